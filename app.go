@@ -1,12 +1,22 @@
 package main
 
 import (
+	"archive/zip"
 	"context"
 	"encoding/binary"
 	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
+	"math"
 	"math/big"
 	"net"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -16,6 +26,7 @@ import (
 	"govault/internal/logger"
 	"govault/internal/miner"
 	"govault/internal/node"
+	"govault/internal/notify"
 	"govault/internal/stratum"
 	"govault/internal/upstream"
 
@@ -34,6 +45,11 @@ type App struct {
 	registry   *miner.Registry
 	stats      *miner.StatsAggregator
 	discovery  *miner.Discovery
+	rounds     *miner.RoundTracker
+	roster     *miner.RosterMonitor
+
+	// roundMu serializes round rotation (block found vs. epoch rollover).
+	roundMu sync.Mutex
 
 	upstream *upstream.Client
 
@@ -49,8 +65,38 @@ type App struct {
 	networkDiff    float64
 	networkHashrate float64
 	blockHeight    int64
+	nodeOutOfSync  bool
 	netMu          sync.RWMutex
 
+	notifier *notify.Notifier
+
+	// MAC addresses learned from devices, keyed by IP
+	// (used by the "name_mac" worker identity mode).
+	deviceMACs   map[string]string
+	deviceMACsMu sync.RWMutex
+	probeDevice  func(ip string) (*miner.DiscoveredMiner, error) // asks a device for its details
+
+	// Latest device telemetry by IP, and raised telemetry alerts
+	telemetry    map[string]miner.DeviceTelemetry
+	telemetryMu  sync.RWMutex
+	deviceAlerts *miner.ThresholdMonitor
+
+	// Last power reading per device IP, for energy metering
+	// (only touched by the telemetry loop)
+	energyLast map[string]energySample
+
+	// Scheduler state: active rules, manual overrides and the workers
+	// withheld from work. schedRunMu serialises evaluation passes.
+	schedActive    map[string]*scheduleState
+	schedOverrides map[string]scheduleOverride
+	heldWorkers    map[string]bool
+	schedMu        sync.Mutex
+	schedRunMu     sync.Mutex
+
+	// Cancels the running network scan, nil when idle
+	scanCancel context.CancelFunc
+	scanMu     sync.Mutex
+
 	// Fleet power cache (30s TTL)
 	fleetPowerCache miner.FleetPowerStats
 	fleetPowerTime  time.Time
@@ -67,18 +113,36 @@ type FleetOverview struct {
 	TotalWatts      float64 `json:"totalWatts"`
 	PowerResponded  int     `json:"powerResponded"`
 	PowerQueried    int     `json:"powerQueried"`
-	DailyCost       float64 `json:"dailyCost"`
+	DailyCost       float64 `json:"dailyCost"` // current load over the next 24h at the tariff
 	ElectricityCost float64 `json:"electricityCost"`
 	Efficiency      float64 `json:"efficiency"` // J/TH
+
+	Currency          string  `json:"currency"`
+	CurrentRate       float64 `json:"currentRate"`       // tariff rate now, per kWh
+	ExpectedBlockCost float64 `json:"expectedBlockCost"` // energy cost until a block at current odds
+
+	Groups []GroupStats `json:"groups"` // per-group breakdown
 }
 
 // NewApp creates a new App application struct.
 func NewApp() *App {
+	discovery := miner.NewDiscovery()
 	return &App{
-		registry:  miner.NewRegistry(),
-		stats:     miner.NewStatsAggregator(),
-		discovery: miner.NewDiscovery(),
-		stopStats: make(chan struct{}),
+		registry:     miner.NewRegistry(),
+		stats:        miner.NewStatsAggregator(),
+		discovery:    discovery,
+		rounds:       miner.NewRoundTracker(),
+		roster:       miner.NewRosterMonitor(),
+		deviceMACs:   make(map[string]string),
+		probeDevice:  discovery.ProbeHost,
+		telemetry:    make(map[string]miner.DeviceTelemetry),
+		deviceAlerts: miner.NewThresholdMonitor(),
+		energyLast:   make(map[string]energySample),
+		stopStats:    make(chan struct{}),
+
+		schedActive:    make(map[string]*scheduleState),
+		schedOverrides: make(map[string]scheduleOverride),
+		heldWorkers:    make(map[string]bool),
 	}
 }
 
@@ -119,7 +183,10 @@ func (a *App) startup(ctx context.Context) {
 		a.buffer = database.NewBuffer(db)
 		a.loadStatsFromDB()
 		if a.log != nil {
-			a.log.Infof("app", "database opened at %s", cfg.DBPath())
+			a.log.Infof("app", "database opened at %s (schema v%d)", cfg.DBPath(), db.SchemaVersion())
+			if backup := db.UpgradeBackup(); backup != "" {
+				a.log.Infof("app", "database upgraded; previous version backed up to %s", backup)
+			}
 		}
 	}
 
@@ -132,8 +199,33 @@ func (a *App) startup(ctx context.Context) {
 		cfg.Node.UseSSL,
 	)
 
-	// Start stats ticker
+	// Initialize notifications
+	a.notifier = notify.NewNotifier(&cfg.Notifications, a.log)
+	a.notifier.OnDelivery = func(d notify.Delivery) {
+		if a.db != nil {
+			a.db.InsertNotification(database.NotificationEntry{
+				Timestamp:   d.Timestamp,
+				ChannelID:   d.ChannelID,
+				ChannelName: d.ChannelName,
+				Event:       d.Event,
+				Title:       d.Title,
+				Status:      d.Status,
+				Attempts:    d.Attempts,
+				Error:       d.Error,
+			})
+		}
+		runtime.EventsEmit(a.ctx, "notify:delivery", d)
+	}
+
+	// Persist discovered devices and reload those seen before
+	a.discovery.OnFound = a.onDeviceFound
+	a.loadDiscoveredDevices()
+
+	// Start stats ticker, telemetry collector, mDNS listener and scheduler
 	go a.statsLoop()
+	go a.telemetryLoop()
+	go a.mdnsLoop()
+	go a.scheduleLoop()
 
 	// Auto-start stratum if configured
 	canAutoStart := cfg.Mining.PayoutAddress != "" || cfg.MiningMode == "proxy"
@@ -178,6 +270,7 @@ func (a *App) shutdown(ctx context.Context) {
 		a.buffer.Stop()
 	}
 	a.saveCumulativeStats()
+	a.saveRound()
 	if a.db != nil {
 		a.db.Close()
 	}
@@ -392,11 +485,17 @@ func (a *App) startProxy() error {
 
 	uc.OnDisconnect = func(err error) {
 		a.log.Errorf("app", "upstream disconnected: %v (reconnecting...)", err)
+		a.notify(notify.Event{
+			Type:    notify.EventUpstreamDown,
+			Title:   "Upstream pool disconnected",
+			Message: fmt.Sprintf("Lost connection to %s: %v. Reconnecting.", proxyCfg.URL, err),
+			Fields:  []notify.Field{{Name: "Pool", Value: proxyCfg.URL}},
+		})
 	}
 
 	uc.OnReconnect = func() {
-		// Upstream assigned a new EN1 — update stratum server and kick
-		// all miners so they reconnect with new EN1-based sessions.
+		// Upstream assigned a new EN1 — update the stratum server, which
+		// moves miners to it with mining.set_extranonce or a reconnect.
 		var vMask uint32
 		if uc.VersionRolling() && uc.VersionMask() != "" {
 			maskBytes, _ := hex.DecodeString(uc.VersionMask())
@@ -453,44 +552,94 @@ func (a *App) startProxy() error {
 // wireStratumCallbacks sets up callbacks shared by both solo and proxy modes.
 func (a *App) wireStratumCallbacks() {
 	a.stratum.OnMinerConnected = func(info stratum.MinerInfo) {
+		host := splitHost(info.IPAddress)
+		workerID, mac := a.resolveWorkerID(info.WorkerName, host)
 		a.registry.Register(miner.MinerInfo{
 			ID:          info.ID,
 			WorkerName:  info.WorkerName,
+			WorkerID:    workerID,
 			UserAgent:   info.UserAgent,
 			IPAddress:   info.IPAddress,
 			ConnectedAt: info.ConnectedAt,
 			CurrentDiff: info.CurrentDiff,
 		})
 		if a.db != nil {
+			a.db.UpsertWorker(workerID, info.WorkerName, host, mac, info.ConnectedAt.Unix())
 			a.db.UpsertMinerSession(database.MinerSessionEntry{
 				SessionID:   info.ID,
 				Worker:      info.WorkerName,
+				WorkerID:    workerID,
 				IPAddress:   info.IPAddress,
+				UserAgent:   info.UserAgent,
 				ConnectedAt: info.ConnectedAt.Unix(),
 			})
 		}
+		if mac == "" && a.config.App.WorkerIdentity == "name_mac" {
+			go a.rekeyWorker(info.ID, info.WorkerName, host)
+		}
 		runtime.EventsEmit(a.ctx, "stratum:miner-connected", info)
 	}
 
 	a.stratum.OnMinerDisconnected = func(id string) {
+		m := a.registry.Get(id)
 		a.registry.Unregister(id)
 		if a.db != nil {
-			a.db.DisconnectMiner(id, time.Now().Unix())
+			now := time.Now().Unix()
+			if m != nil {
+				// Persist final session counters so worker history shows
+				// what each session contributed.
+				a.db.UpsertMinerSession(database.MinerSessionEntry{
+					SessionID:      m.ID,
+					Worker:         m.WorkerName,
+					WorkerID:       m.WorkerID,
+					IPAddress:      m.IPAddress,
+					UserAgent:      m.UserAgent,
+					ConnectedAt:    m.ConnectedAt.Unix(),
+					DisconnectedAt: now,
+					SharesAccepted: int64(m.SharesAccepted),
+					SharesRejected: int64(m.SharesRejected),
+					BestDifficulty: m.BestDifficulty,
+				})
+				a.db.TouchWorker(m.WorkerID, now)
+			} else {
+				a.db.DisconnectMiner(id, now)
+			}
+		}
+		if m != nil {
+			a.notify(notify.Event{
+				Type:    notify.EventMinerDisconnected,
+				Subject: m.WorkerID,
+				Title:   "Miner disconnected: " + m.WorkerName,
+				Message: fmt.Sprintf("%s (%s) disconnected after %s.", m.WorkerName, m.IPAddress, time.Since(m.ConnectedAt).Round(time.Second)),
+				Fields: []notify.Field{
+					{Name: "Worker", Value: m.WorkerName},
+					{Name: "IP", Value: m.IPAddress},
+				},
+			})
 		}
 		runtime.EventsEmit(a.ctx, "stratum:miner-disconnected", map[string]string{"id": id})
 	}
 
-	a.stratum.OnShareAccepted = func(minerID string, sessionDiff, actualDiff float64) {
+	a.stratum.OnShareAccepted = func(minerID string, sessionDiff, actualDiff float64, age stratum.ShareAge) {
+		workerID := a.workerID(minerID)
 		a.registry.RecordShare(minerID, actualDiff, true)
-		a.stats.RecordShare(minerID, sessionDiff, true)
+		a.stats.RecordShare(minerID, workerID, sessionDiff, true)
+		a.roster.RecordShare(workerID, true, false)
 		a.stats.RecordBestDifficulty(actualDiff)
+		a.netMu.RLock()
+		netDiff := a.networkDiff
+		a.netMu.RUnlock()
+		a.rounds.RecordShare(workerID, sessionDiff, actualDiff, netDiff)
 		if a.buffer != nil {
 			a.buffer.AddShare(database.ShareEntry{
 				Timestamp:   time.Now().Unix(),
 				MinerID:     minerID,
+				Worker:      workerID,
 				Difficulty:  actualDiff,
 				SessionDiff: sessionDiff,
 				Accepted:    true,
+				JobAgeMs:    age.Job.Milliseconds(),
+				CleanAgeMs:  age.Clean.Milliseconds(),
 			})
 		}
 		runtime.EventsEmit(a.ctx, "stratum:share-accepted", map[string]interface{}{
@@ -499,22 +648,7 @@ func (a *App) wireStratumCallbacks() {
 		})
 	}
 
-	a.stratum.OnShareRejected = func(minerID string, reason string) {
-		a.registry.RecordShare(minerID, 0, false)
-		a.stats.RecordShare(minerID, 0, false)
-		if a.buffer != nil {
-			a.buffer.AddShare(database.ShareEntry{
-				Timestamp:    time.Now().Unix(),
-				MinerID:      minerID,
-				Accepted:     false,
-				RejectReason: reason,
-			})
-		}
-		runtime.EventsEmit(a.ctx, "stratum:share-rejected", map[string]interface{}{
-			"minerId": minerID,
-			"reason":  reason,
-		})
-	}
+	a.stratum.OnShareRejected = a.recordRejectedShare
 
 	a.stratum.OnBlockFound = func(hash string, height int64, accepted bool) {
 		if accepted {
@@ -526,16 +660,40 @@ func (a *App) wireStratumCallbacks() {
 					Hash:      hash,
 				})
 			}
+			a.closeRound("block", height, hash)
 			runtime.EventsEmit(a.ctx, "stratum:block-found", map[string]interface{}{
 				"hash":   hash,
 				"height": height,
 			})
 			a.log.Infof("app", "BLOCK ACCEPTED! Hash: %s Height: %d", hash, height)
+			a.notify(notify.Event{
+				Type:    notify.EventBlockFound,
+				Title:   fmt.Sprintf("Block found at height %d", height),
+				Message: fmt.Sprintf("GoVault found a %s block.", coin.Get(a.config.Mining.Coin).Name),
+				Fields: []notify.Field{
+					{Name: "Height", Value: fmt.Sprintf("%d", height)},
+					{Name: "Hash", Value: hash},
+				},
+			})
 		} else {
 			a.log.Warnf("app", "Block candidate rejected. Hash: %s Height: %d", hash, height)
 		}
 	}
 
+	a.stratum.OnBanned = func(ban stratum.Ban) {
+		runtime.EventsEmit(a.ctx, "stratum:banned", ban)
+		a.notify(notify.Event{
+			Type:    notify.EventIPBanned,
+			Subject: ban.IP,
+			Title:   fmt.Sprintf("Banned %s", ban.IP),
+			Message: ban.Reason,
+			Fields: []notify.Field{
+				{Name: "IP", Value: ban.IP},
+				{Name: "Until", Value: ban.Until.Format(time.RFC1123)},
+			},
+		})
+	}
+	a.stratum.HoldWork = a.holdWork
 	a.stratum.LookupWorkerDiff = func(workerName string) float64 {
 		if a.db != nil {
 			diff, _ := a.db.GetWorkerDiff(workerName)
@@ -586,6 +744,61 @@ func (a *App) IsStratumRunning() bool {
 	return srv != nil && srv.IsRunning()
 }
 
+// GetStratumBans returns the IPs the stratum server is refusing. Bans are
+// kept in memory and cleared when the server restarts.
+func (a *App) GetStratumBans() []stratum.Ban {
+	a.svcMu.RLock()
+	srv := a.stratum
+	a.svcMu.RUnlock()
+	if srv == nil {
+		return []stratum.Ban{}
+	}
+	return srv.Bans()
+}
+
+// LiftStratumBan unbans an IP and resets its score.
+func (a *App) LiftStratumBan(ip string) error {
+	a.svcMu.RLock()
+	srv := a.stratum
+	a.svcMu.RUnlock()
+	if srv == nil {
+		return fmt.Errorf("stratum server is not running")
+	}
+	if !srv.Unban(ip) {
+		return fmt.Errorf("%s is not banned", ip)
+	}
+	return nil
+}
+
+// SetWorkerPassword hashes a password onto the worker access rule with the
+// given pattern. An empty password lets the rule accept any password.
+func (a *App) SetWorkerPassword(pattern, password string) error {
+	// Sessions read the live rules while authorizing, so change a copy.
+	newCfg, err := a.config.Clone()
+	if err != nil {
+		return err
+	}
+	rules := newCfg.Stratum.Auth.Rules
+	for i := range rules {
+		if rules[i].Pattern != pattern {
+			continue
+		}
+		hash := ""
+		if password != "" {
+			if hash, err = stratum.HashPassword(password); err != nil {
+				return err
+			}
+		}
+		rules[i].PasswordHash = hash
+		if err := a.UpdateConfig(newCfg); err != nil {
+			return err
+		}
+		a.log.Infof("app", "password for workers %q updated", pattern)
+		return nil
+	}
+	return fmt.Errorf("no worker access rule for %q", pattern)
+}
+
 // === Dashboard ===
 
 func (a *App) GetDashboardStats() miner.DashboardStats {
@@ -613,6 +826,9 @@ func (a *App) GetDashboardStats() miner.DashboardStats {
 	)
 
 	ds.MiningMode = a.config.MiningMode
+	round := a.rounds.Snapshot()
+	ds.RoundEffort = round.Effort
+	ds.RoundBestShare = round.BestShare
 	if srv != nil && srv.IsProxyMode() {
 		diag := srv.GetProxyDiagnostics()
 		ds.UpstreamDiff = diag.UpstreamDiff
@@ -624,8 +840,15 @@ func (a *App) GetDashboardStats() miner.DashboardStats {
 	return ds
 }
 
+// GetHashrateHistory returns the pool hashrate series for the given period.
+// Periods up to 7 days come from the in-memory series; longer periods
+// ("30d", "90d", "1y") are served from share rollups.
 func (a *App) GetHashrateHistory(period string) []miner.HashratePoint {
-	return a.stats.GetHashrateHistory(period)
+	window, bucket := historyWindow(period)
+	if window <= 7*24*time.Hour || a.db == nil {
+		return a.stats.GetHashrateHistory(period)
+	}
+	return a.rollupHashrateHistory("", window, bucket)
 }
 
 // GetMinerHashrateHistory returns per-miner hashrate sparkline data (1h window, 2min buckets).
@@ -668,6 +891,23 @@ func (a *App) ClearRejectedShares() (int64, error) {
 	return 0, nil
 }
 
+// GetRejectAnalytics returns share counts, reject counts by code and share
+// timing per time bucket, grouped by "worker" or "userAgent", for the given
+// period. Periods are capped at raw share retention.
+func (a *App) GetRejectAnalytics(by string, period string) ([]database.RejectBucket, error) {
+	if a.db == nil {
+		return nil, fmt.Errorf("database not available")
+	}
+	if by != "worker" && by != "userAgent" {
+		return nil, fmt.Errorf("unknown grouping %q", by)
+	}
+	window, bucket := historyWindow(period)
+	if raw := time.Duration(a.config.App.Retention.RawDays) * 24 * time.Hour; raw > 0 && window > raw {
+		window = raw
+	}
+	return a.db.RejectAnalytics(time.Now().Add(-window).Unix(), bucket, by == "userAgent")
+}
+
 // === Miners ===
 
 func (a *App) GetMiners() []miner.MinerInfo {
@@ -689,13 +929,218 @@ func (a *App) GetMiners() []miner.MinerInfo {
 
 	for i := range miners {
 		miners[i].Hashrate = a.stats.EstimateMinerHashrate(miners[i].ID)
+		_, miners[i].RoundBestDifficulty = a.rounds.BestShare(miners[i].WorkerID)
 		if live, ok := liveSessions[miners[i].ID]; ok {
 			miners[i].CurrentDiff = live.CurrentDiff
+			miners[i].JobLatencyMs = live.JobLatencyMs
+			miners[i].JobLatencyMaxMs = live.JobLatencyMaxMs
+			miners[i].QueueDepth = live.QueueDepth
+			miners[i].Dropped = live.Dropped
+			miners[i].SharesStaleBlock = live.SharesStaleBlock
+			miners[i].StaleTipAgeMs = live.StaleTipAgeMs
+			miners[i].StaleTipAgeMaxMs = live.StaleTipAgeMaxMs
+			miners[i].RejectedNTime = live.RejectedNTime
+			miners[i].RejectedVersionMask = live.RejectedVersionMask
+			miners[i].RejectedExtranonce2 = live.RejectedExtranonce2
+			miners[i].RejectedNonce = live.RejectedNonce
 		}
 	}
 	return miners
 }
 
+// === Workers ===
+
+// WorkerView is a persistent worker together with its live sessions.
+type WorkerView struct {
+	Worker              database.WorkerEntry         `json:"worker"`
+	Online              bool                         `json:"online"`
+	Hashrate            float64                      `json:"hashrate"`
+	RejectRate          float64                      `json:"rejectRate"` // percent
+	RoundBestDifficulty float64                      `json:"roundBestDifficulty"`
+	Sessions            []miner.MinerInfo            `json:"sessions"`                 // live sessions
+	SessionHistory      []database.MinerSessionEntry `json:"sessionHistory,omitempty"` // only from GetWorker
+}
+
+// GetWorkers returns all known workers with their live sessions underneath.
+func (a *App) GetWorkers() []WorkerView {
+	live := a.liveSessionsByWorker()
+
+	var entries []database.WorkerEntry
+	if a.db != nil {
+		var err error
+		entries, err = a.db.ListWorkers()
+		if err != nil && a.log != nil {
+			a.log.Errorf("app", "list workers: %v", err)
+		}
+	}
+
+	views := make([]WorkerView, 0, len(entries))
+	seen := make(map[string]bool, len(entries))
+	for _, e := range entries {
+		seen[e.ID] = true
+		views = append(views, a.workerView(e, live[e.ID]))
+	}
+	// Workers connected while the database was unavailable
+	for id, sessions := range live {
+		if !seen[id] {
+			views = append(views, a.workerView(database.WorkerEntry{
+				ID:        id,
+				Name:      sessions[0].WorkerName,
+				IPAddress: splitHost(sessions[0].IPAddress),
+			}, sessions))
+		}
+	}
+	return views
+}
+
+// GetWorker returns a single worker with its live and recent sessions.
+func (a *App) GetWorker(id string) (WorkerView, error) {
+	live := a.liveSessionsByWorker()[id]
+
+	var entry database.WorkerEntry
+	if a.db != nil {
+		e, err := a.db.GetWorker(id)
+		if err != nil {
+			return WorkerView{}, err
+		}
+		if e != nil {
+			entry = *e
+		}
+	}
+	if entry.ID == "" {
+		if len(live) == 0 {
+			return WorkerView{}, fmt.Errorf("unknown worker: %s", id)
+		}
+		entry = database.WorkerEntry{ID: id, Name: live[0].WorkerName, IPAddress: splitHost(live[0].IPAddress)}
+	}
+
+	view := a.workerView(entry, live)
+	if a.db != nil {
+		history, err := a.db.WorkerSessions(id, 100)
+		if err != nil {
+			return view, err
+		}
+		view.SessionHistory = history
+	}
+	return view, nil
+}
+
+// GetWorkerHashrateHistory returns a worker's hashrate across all of its
+// sessions for the given period ("1h", "6h", "24h", "7d", "30d", "90d",
+// "1y"). Periods beyond raw share retention are served from rollups.
+func (a *App) GetWorkerHashrateHistory(id string, period string) []miner.HashratePoint {
+	if a.db == nil {
+		return nil
+	}
+	window, bucket := historyWindow(period)
+	if window > time.Duration(a.config.App.Retention.RawDays)*24*time.Hour {
+		return a.rollupHashrateHistory(id, window, bucket)
+	}
+	since := time.Now().Add(-window).Unix()
+	entries, err := a.db.WorkerHashrateHistory(id, since, bucket)
+	if err != nil {
+		if a.log != nil {
+			a.log.Errorf("app", "worker hashrate history: %v", err)
+		}
+		return nil
+	}
+	points := make([]miner.HashratePoint, len(entries))
+	for i, e := range entries {
+		points[i] = miner.HashratePoint{
+			Timestamp: e.Timestamp,
+			Hashrate:  e.Hashrate,
+		}
+	}
+	return points
+}
+
+func (a *App) workerView(entry database.WorkerEntry, sessions []miner.MinerInfo) WorkerView {
+	view := WorkerView{
+		Worker:   entry,
+		Online:   len(sessions) > 0,
+		Hashrate: a.stats.EstimateWorkerHashrate(entry.ID),
+		Sessions: sessions,
+	}
+	if view.Sessions == nil {
+		view.Sessions = []miner.MinerInfo{}
+	}
+	if total := entry.SharesAccepted + entry.SharesRejected; total > 0 {
+		view.RejectRate = float64(entry.SharesRejected) / float64(total) * 100
+	}
+	_, view.RoundBestDifficulty = a.rounds.BestShare(entry.ID)
+	return view
+}
+
+// liveSessionsByWorker groups the currently connected sessions by worker ID.
+func (a *App) liveSessionsByWorker() map[string][]miner.MinerInfo {
+	live := make(map[string][]miner.MinerInfo)
+	for _, m := range a.GetMiners() {
+		live[m.WorkerID] = append(live[m.WorkerID], m)
+	}
+	return live
+}
+
+// resolveWorkerID maps a session to its persistent worker identity
+// according to the configured identity mode. It also returns the device
+// MAC address when known. In name_mac mode a device whose MAC is not yet
+// known is keyed by IP; rekeyWorker moves it once the MAC is learned.
+func (a *App) resolveWorkerID(name, ip string) (id, mac string) {
+	a.deviceMACsMu.RLock()
+	mac = a.deviceMACs[ip]
+	a.deviceMACsMu.RUnlock()
+
+	switch a.config.App.WorkerIdentity {
+	case "name_ip":
+		return name + "@" + ip, mac
+	case "name_mac":
+		if mac == "" {
+			return name + "@" + ip, ""
+		}
+		return name + "@" + mac, mac
+	default:
+		return name, mac
+	}
+}
+
+// rekeyWorker asks the device behind a session keyed by IP for its MAC
+// address and, if it answers, moves the session to its MAC-keyed worker.
+// It runs in the background so authorize is never held up by the device.
+func (a *App) rekeyWorker(sessionID, name, ip string) {
+	mac := a.learnDeviceMAC(ip)
+	if mac == "" {
+		return // no API to ask: stays keyed by IP
+	}
+	workerID := name + "@" + mac
+	if !a.registry.SetWorkerID(sessionID, workerID) {
+		return // disconnected meanwhile; the next session gets the MAC
+	}
+	if a.db != nil {
+		a.db.UpsertWorker(workerID, name, ip, mac, time.Now().Unix())
+		a.db.SetSessionWorker(sessionID, workerID)
+	}
+	a.log.Infof("app", "session %s: worker %s@%s is %s", sessionID, name, ip, workerID)
+}
+
+// learnDeviceMAC asks a device for its MAC address and remembers it.
+func (a *App) learnDeviceMAC(ip string) string {
+	m, err := a.probeDevice(ip)
+	if err != nil || m == nil || m.MACAddress == "" {
+		return ""
+	}
+	a.rememberDeviceMACs([]miner.DiscoveredMiner{*m})
+	return m.MACAddress
+}
+
+func (a *App) rememberDeviceMACs(devices []miner.DiscoveredMiner) {
+	a.deviceMACsMu.Lock()
+	for _, d := range devices {
+		if d.MACAddress != "" {
+			a.deviceMACs[d.IP] = d.MACAddress
+		}
+	}
+	a.deviceMACsMu.Unlock()
+}
+
 // GetFleetOverview returns aggregated stats for the Miners page fleet overview.
 func (a *App) GetFleetOverview() FleetOverview {
 	dash := a.GetDashboardStats()
@@ -745,10 +1190,10 @@ func (a *App) GetFleetOverview() FleetOverview {
 	overview.PowerResponded = power.Responded
 	overview.PowerQueried = power.Queried
 
-	// Daily cost: watts * 24h / 1000 * $/kWh
-	if power.TotalWatts > 0 && overview.ElectricityCost > 0 {
-		overview.DailyCost = (power.TotalWatts * 24 / 1000) * overview.ElectricityCost
-	}
+	overview.Currency = a.config.App.Tariff.Currency
+	overview.CurrentRate = a.config.App.RateAt(time.Now())
+	overview.DailyCost = a.dailyEnergyCost(power.TotalWatts)
+	overview.ExpectedBlockCost = a.expectedBlockCost(power.TotalWatts, dash.EstTimeToBlock)
 
 	// Efficiency: J/TH = watts / (hashrate in TH/s)
 	if power.TotalWatts > 0 && dash.TotalHashrate > 0 {
@@ -758,9 +1203,338 @@ func (a *App) GetFleetOverview() FleetOverview {
 		}
 	}
 
+	overview.Groups = a.GetGroupStats()
+
 	return overview
 }
 
+// === Groups ===
+
+// GroupView is a group with its current members.
+type GroupView struct {
+	miner.Group
+	Members []string `json:"members"` // worker identity keys
+}
+
+// GroupStats are live stats for one worker group.
+type GroupStats struct {
+	GroupID        int64   `json:"groupId"`
+	Name           string  `json:"name"`
+	Workers        int     `json:"workers"`
+	Online         int     `json:"online"`
+	Hashrate       float64 `json:"hashrate"`
+	SharesAccepted int64   `json:"sharesAccepted"`
+	SharesRejected int64   `json:"sharesRejected"`
+	TotalWatts     float64 `json:"totalWatts"`
+	PowerResponded int     `json:"powerResponded"`
+	Efficiency     float64 `json:"efficiency"` // J/TH
+	DailyCost      float64 `json:"dailyCost"`
+	EstTimeToBlock float64 `json:"estTimeToBlock"`
+	BlockChance    float64 `json:"blockChance"` // % chance of a block in 24h
+
+	ExpectedBlockCost float64 `json:"expectedBlockCost"`
+}
+
+// groupSubject is a worker prepared for group matching.
+type groupSubject struct {
+	subject miner.GroupSubject
+	view    WorkerView
+}
+
+// GetGroups returns all groups with their current members.
+func (a *App) GetGroups() []GroupView {
+	groups := a.loadGroups()
+	subjects := a.groupSubjects()
+	views := make([]GroupView, len(groups))
+	for i, g := range groups {
+		views[i] = GroupView{Group: g, Members: []string{}}
+		for _, s := range subjects {
+			if g.Matches(s.subject) {
+				views[i].Members = append(views[i].Members, s.view.Worker.ID)
+			}
+		}
+	}
+	return views
+}
+
+// SaveGroup creates a group (ID 0) or updates an existing one.
+func (a *App) SaveGroup(g miner.Group) (miner.Group, error) {
+	if a.db == nil {
+		return g, fmt.Errorf("database not available")
+	}
+	g.Name = strings.TrimSpace(g.Name)
+	if g.Rules == nil {
+		g.Rules = []miner.GroupRule{}
+	}
+	if err := g.Validate(); err != nil {
+		return g, err
+	}
+	rules, err := json.Marshal(g.Rules)
+	if err != nil {
+		return g, err
+	}
+	id, err := a.db.SaveGroup(database.GroupEntry{
+		ID:        g.ID,
+		Name:      g.Name,
+		Rules:     string(rules),
+		CreatedAt: time.Now().Unix(),
+	})
+	if err != nil {
+		return g, fmt.Errorf("save group: %w", err)
+	}
+	g.ID = id
+	return g, nil
+}
+
+// DeleteGroup removes a group. Tags are kept.
+func (a *App) DeleteGroup(id int64) error {
+	if a.db == nil {
+		return fmt.Errorf("database not available")
+	}
+	return a.db.DeleteGroup(id)
+}
+
+// GetTags returns all tags of a kind ("worker", "device", "ip"), or every
+// tag when kind is empty.
+func (a *App) GetTags(kind string) []database.TagEntry {
+	if a.db == nil {
+		return nil
+	}
+	tags, err := a.db.ListTags(kind)
+	if err != nil {
+		a.log.Errorf("app", "list tags: %v", err)
+		return nil
+	}
+	return tags
+}
+
+// SetTags replaces the tags on a worker (identity key), device (MAC
+// address) or IP.
+func (a *App) SetTags(kind, target string, tags []string) error {
+	if a.db == nil {
+		return fmt.Errorf("database not available")
+	}
+	target = strings.TrimSpace(target)
+	switch kind {
+	case miner.TagWorker:
+	case miner.TagDevice:
+		target = strings.ToLower(target)
+	case miner.TagIP:
+		if net.ParseIP(target) == nil {
+			return fmt.Errorf("invalid IP address: %q", target)
+		}
+	default:
+		return fmt.Errorf("unknown tag kind %q", kind)
+	}
+	if target == "" {
+		return fmt.Errorf("tag target is required")
+	}
+	return a.db.SetTags(kind, target, miner.NormalizeTags(tags))
+}
+
+// GetGroupStats returns hashrate, shares, power, efficiency, cost and
+// block odds for every group.
+func (a *App) GetGroupStats() []GroupStats {
+	groups := a.loadGroups()
+	if len(groups) == 0 {
+		return []GroupStats{}
+	}
+	subjects := a.groupSubjects()
+
+	a.netMu.RLock()
+	networkDiff := a.networkDiff
+	a.netMu.RUnlock()
+
+	a.telemetryMu.RLock()
+	defer a.telemetryMu.RUnlock()
+
+	result := make([]GroupStats, 0, len(groups))
+	for _, g := range groups {
+		st := GroupStats{GroupID: g.ID, Name: g.Name}
+		powered := make(map[string]bool)
+		for _, s := range subjects {
+			if !g.Matches(s.subject) {
+				continue
+			}
+			st.Workers++
+			st.SharesAccepted += s.view.Worker.SharesAccepted
+			st.SharesRejected += s.view.Worker.SharesRejected
+			if !s.view.Online {
+				continue
+			}
+			st.Online++
+			st.Hashrate += s.view.Hashrate
+			// Count each device's power once, however many workers it runs
+			if ip := s.subject.IP; !powered[ip] {
+				powered[ip] = true
+				if t, ok := a.telemetry[ip]; ok && t.Power > 0 {
+					st.TotalWatts += t.Power
+					st.PowerResponded++
+				}
+			}
+		}
+		st.DailyCost = a.dailyEnergyCost(st.TotalWatts)
+		if st.TotalWatts > 0 && st.Hashrate > 0 {
+			st.Efficiency = st.TotalWatts / (st.Hashrate / 1e12)
+		}
+		st.EstTimeToBlock = miner.EstimateTimeToBlock(st.Hashrate, networkDiff)
+		if st.EstTimeToBlock > 0 {
+			st.BlockChance = (1 - math.Exp(-86400/st.EstTimeToBlock)) * 100
+		}
+		st.ExpectedBlockCost = a.expectedBlockCost(st.TotalWatts, st.EstTimeToBlock)
+		result = append(result, st)
+	}
+	return result
+}
+
+// GetGroupHashrateHistory returns the summed hashrate history of a group's
+// members for the given period.
+func (a *App) GetGroupHashrateHistory(id int64, period string) []miner.HashratePoint {
+	var members []string
+	for _, g := range a.GetGroups() {
+		if g.ID == id {
+			members = g.Members
+			break
+		}
+	}
+
+	sums := make(map[int64]float64)
+	for _, worker := range members {
+		for _, p := range a.GetWorkerHashrateHistory(worker, period) {
+			sums[p.Timestamp] += p.Hashrate
+		}
+	}
+	points := make([]miner.HashratePoint, 0, len(sums))
+	for ts, h := range sums {
+		points = append(points, miner.HashratePoint{Timestamp: ts, Hashrate: h})
+	}
+	sort.Slice(points, func(i, j int) bool { return points[i].Timestamp < points[j].Timestamp })
+	return points
+}
+
+// loadGroups reads all groups from the database.
+func (a *App) loadGroups() []miner.Group {
+	if a.db == nil {
+		return nil
+	}
+	entries, err := a.db.ListGroups()
+	if err != nil {
+		a.log.Errorf("app", "list groups: %v", err)
+		return nil
+	}
+	groups := make([]miner.Group, 0, len(entries))
+	for _, e := range entries {
+		g := miner.Group{ID: e.ID, Name: e.Name}
+		if err := json.Unmarshal([]byte(e.Rules), &g.Rules); err != nil {
+			a.log.Warnf("app", "group %q has invalid rules: %v", e.Name, err)
+		}
+		groups = append(groups, g)
+	}
+	return groups
+}
+
+// groupSubjects collects every known worker with the attributes group
+// rules match on: name, user agent, IP, device model and tags.
+func (a *App) groupSubjects() []groupSubject {
+	tags := make(map[string][]string) // "kind/target" -> tags
+	for _, t := range a.GetTags("") {
+		key := t.Kind + "/" + t.Target
+		tags[key] = append(tags[key], t.Tag)
+	}
+	models := make(map[string]string)
+	for _, d := range a.discovery.Known() {
+		models[d.IP] = d.Model
+	}
+
+	views := a.GetWorkers()
+	subjects := make([]groupSubject, 0, len(views))
+	for _, v := range views {
+		s := miner.GroupSubject{
+			WorkerID:   v.Worker.ID,
+			WorkerName: v.Worker.Name,
+			IP:         v.Worker.IPAddress,
+		}
+		if len(v.Sessions) > 0 {
+			s.UserAgent = v.Sessions[0].UserAgent
+			s.IP = splitHost(v.Sessions[0].IPAddress)
+		}
+		s.Model = models[s.IP]
+
+		mac := v.Worker.MACAddress
+		if mac == "" {
+			a.deviceMACsMu.RLock()
+			mac = a.deviceMACs[s.IP]
+			a.deviceMACsMu.RUnlock()
+		}
+		s.Tags = append(s.Tags, tags[miner.TagWorker+"/"+s.WorkerID]...)
+		if mac != "" {
+			s.Tags = append(s.Tags, tags[miner.TagDevice+"/"+strings.ToLower(mac)]...)
+		}
+		if s.IP != "" {
+			s.Tags = append(s.Tags, tags[miner.TagIP+"/"+s.IP]...)
+		}
+		subjects = append(subjects, groupSubject{subject: s, view: v})
+	}
+	return subjects
+}
+
+// === Rounds ===
+
+// GetCurrentRound returns live stats for the current round.
+func (a *App) GetCurrentRound() miner.RoundStats {
+	round := a.rounds.Snapshot()
+	a.netMu.RLock()
+	if a.networkDiff > 0 {
+		round.ExpectedWork = a.networkDiff
+	}
+	a.netMu.RUnlock()
+	return round
+}
+
+// GetRoundHistory returns the most recent rounds, newest first. The open
+// round reflects live totals rather than the last persisted snapshot.
+func (a *App) GetRoundHistory(limit int) []database.RoundEntry {
+	if a.db == nil {
+		return nil
+	}
+	if limit <= 0 {
+		limit = 50
+	}
+	rounds, err := a.db.RecentRounds(limit)
+	if err != nil {
+		if a.log != nil {
+			a.log.Errorf("app", "round history: %v", err)
+		}
+		return nil
+	}
+	current := a.rounds.Snapshot()
+	for i := range rounds {
+		if rounds[i].ID == current.ID && rounds[i].EndedAt == 0 {
+			rounds[i] = roundEntryFromStats(current)
+		}
+	}
+	return rounds
+}
+
+// GetRoundWorkers returns per-worker contributions for a round.
+func (a *App) GetRoundWorkers(roundID int64) []database.RoundWorkerEntry {
+	current := a.rounds.Snapshot()
+	if roundID == current.ID {
+		return roundWorkerEntries(current)
+	}
+	if a.db == nil {
+		return nil
+	}
+	workers, err := a.db.RoundWorkers(roundID)
+	if err != nil {
+		if a.log != nil {
+			a.log.Errorf("app", "round workers: %v", err)
+		}
+		return nil
+	}
+	return workers
+}
+
 // === Node ===
 
 // DetectNode probes the local machine for a running node matching the given coin.
@@ -1006,12 +1780,175 @@ func (a *App) GetCoinList() []map[string]interface{} {
 
 // === Discovery ===
 
+// ScanForMiners scans the configured discovery targets, emitting
+// "discovery:progress" events while it runs. Only one scan runs at a time.
 func (a *App) ScanForMiners() []miner.DiscoveredMiner {
-	a.log.Info("discovery", "starting network scan for miners")
-	results := a.discovery.ScanSubnet()
-	a.log.Infof("discovery", "found %d miners on network", len(results))
-	return results
-}
+	a.scanMu.Lock()
+	if a.scanCancel != nil {
+		a.scanMu.Unlock()
+		a.log.Warn("discovery", "scan already in progress")
+		return nil
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	a.scanCancel = cancel
+	a.scanMu.Unlock()
+	defer func() {
+		cancel()
+		a.scanMu.Lock()
+		a.scanCancel = nil
+		a.scanMu.Unlock()
+	}()
+
+	dc := a.config.App.Discovery
+	opts := miner.ScanOptions{
+		Targets:     dc.Targets,
+		Concurrency: dc.Concurrency,
+		Timeout:     time.Duration(dc.TimeoutMs) * time.Millisecond,
+		Budget:      time.Duration(dc.BudgetSec) * time.Second,
+		MaxHosts:    dc.MaxHosts,
+	}
+
+	// Throttle progress events to a few per second
+	var emitMu sync.Mutex
+	var lastEmit time.Time
+	progress := func(p miner.ScanProgress) {
+		emitMu.Lock()
+		defer emitMu.Unlock()
+		if !p.Done && time.Since(lastEmit) < 250*time.Millisecond {
+			return
+		}
+		lastEmit = time.Now()
+		runtime.EventsEmit(a.ctx, "discovery:progress", p)
+	}
+
+	a.log.Info("discovery", "starting network scan for miners")
+	results, err := a.discovery.Scan(ctx, opts, progress)
+	if err != nil {
+		a.log.Errorf("discovery", "scan failed: %v", err)
+		runtime.EventsEmit(a.ctx, "discovery:progress", miner.ScanProgress{Done: true, Error: err.Error()})
+		return nil
+	}
+	a.log.Infof("discovery", "found %d miners on network", len(results))
+	return results
+}
+
+// CancelScan stops a running network scan; devices found so far are kept.
+func (a *App) CancelScan() {
+	a.scanMu.Lock()
+	defer a.scanMu.Unlock()
+	if a.scanCancel != nil {
+		a.scanCancel()
+	}
+}
+
+// GetDiscoveredDevices returns every device found by scans or mDNS, with
+// first and last seen times.
+func (a *App) GetDiscoveredDevices() []database.DiscoveredDeviceEntry {
+	if a.db == nil {
+		return nil
+	}
+	devices, err := a.db.ListDiscoveredDevices()
+	if err != nil {
+		a.log.Errorf("app", "discovered devices: %v", err)
+		return nil
+	}
+	return devices
+}
+
+// ForgetDiscoveredDevice removes a device from the discovered list. It
+// comes back if it is found again.
+func (a *App) ForgetDiscoveredDevice(ip string) error {
+	if a.db == nil {
+		return fmt.Errorf("database not available")
+	}
+	a.discovery.Forget(ip)
+	return a.db.DeleteDiscoveredDevice(ip)
+}
+
+// onDeviceFound persists a device found by a scan or mDNS.
+func (a *App) onDeviceFound(m miner.DiscoveredMiner, source string) {
+	a.rememberDeviceMACs([]miner.DiscoveredMiner{m})
+	if source == "mdns" && a.log != nil {
+		a.log.Infof("discovery", "found %s (%s) via mDNS", m.IP, m.Model)
+	}
+	if a.db == nil {
+		return
+	}
+	err := a.db.UpsertDiscoveredDevice(database.DiscoveredDeviceEntry{
+		IP:          m.IP,
+		MACAddress:  m.MACAddress,
+		Hostname:    m.Hostname,
+		Model:       m.Model,
+		Firmware:    m.Firmware,
+		Driver:      m.Driver,
+		CurrentPool: m.CurrentPool,
+		Source:      source,
+		LastSeen:    time.Now().Unix(),
+	})
+	if err != nil && a.log != nil {
+		a.log.Errorf("discovery", "save device %s: %v", m.IP, err)
+	}
+}
+
+// loadDiscoveredDevices seeds discovery with devices stored in the database.
+func (a *App) loadDiscoveredDevices() {
+	if a.db == nil {
+		return
+	}
+	entries, err := a.db.ListDiscoveredDevices()
+	if err != nil {
+		if a.log != nil {
+			a.log.Errorf("discovery", "load devices: %v", err)
+		}
+		return
+	}
+	devices := make([]miner.DiscoveredMiner, len(entries))
+	for i, e := range entries {
+		devices[i] = miner.DiscoveredMiner{
+			IP:          e.IP,
+			Hostname:    e.Hostname,
+			Model:       e.Model,
+			CurrentPool: e.CurrentPool,
+			Firmware:    e.Firmware,
+			MACAddress:  e.MACAddress,
+			Driver:      e.Driver,
+		}
+	}
+	a.discovery.Seed(devices)
+	a.rememberDeviceMACs(devices)
+}
+
+// mdnsLoop runs the passive mDNS listener while it is enabled in config.
+func (a *App) mdnsLoop() {
+	var cancel context.CancelFunc
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		enabled := a.config.App.Discovery.MDNS
+		if enabled && cancel == nil {
+			var ctx context.Context
+			ctx, cancel = context.WithCancel(context.Background())
+			go func() {
+				if err := a.discovery.ListenMDNS(ctx); err != nil && a.log != nil {
+					a.log.Warnf("discovery", "mDNS listener stopped: %v", err)
+				}
+			}()
+		} else if !enabled && cancel != nil {
+			cancel()
+			cancel = nil
+		}
+
+		select {
+		case <-a.stopStats:
+			if cancel != nil {
+				cancel()
+			}
+			return
+		case <-ticker.C:
+		}
+	}
+}
 
 func (a *App) ConfigureMiner(ip string) error {
 	localIP := miner.GetLocalIP()
@@ -1022,8 +1959,8 @@ func (a *App) ConfigureMiner(ip string) error {
 	return a.discovery.ConfigureMiner(ip, stratumURL, stratumPort, stratumUser)
 }
 
-// ReconnectMiners nudges disconnected AxeOS miners by PATCHing their
-// stratum settings via HTTP, causing them to reconnect immediately.
+// ReconnectMiners nudges disconnected miners by re-sending their pool
+// settings through their device driver, causing them to reconnect.
 func (a *App) ReconnectMiners() map[string]interface{} {
 	if !a.IsStratumRunning() {
 		return map[string]interface{}{
@@ -1251,209 +2188,1499 @@ func (a *App) updateNetworkDiffFromNBits(nbitsHex string) {
 
 // === Database ===
 
-// GetDatabaseInfo returns the database file path and total disk usage in bytes.
+// GetDatabaseInfo returns the database file path, total disk usage in bytes,
+// schema version and the available snapshots.
 func (a *App) GetDatabaseInfo() map[string]interface{} {
 	if a.db == nil {
 		return map[string]interface{}{"path": "", "size": 0}
 	}
+	snapshots, err := database.ListSnapshots(a.config.BackupDir())
+	if err != nil && a.log != nil {
+		a.log.Errorf("app", "list snapshots: %v", err)
+	}
+	if snapshots == nil {
+		snapshots = []database.SnapshotInfo{}
+	}
 	return map[string]interface{}{
-		"path": a.db.Path(),
-		"size": a.db.Size(),
+		"path":          a.db.Path(),
+		"size":          a.db.Size(),
+		"schemaVersion": a.db.SchemaVersion(),
+		"snapshots":     snapshots,
 	}
 }
 
-// === Logs ===
+// === Backups ===
 
-func (a *App) GetRecentLogs(count int) []logger.LogEntry {
-	if a.log == nil {
-		return nil
+// BackupNow takes a database snapshot immediately and rotates old ones.
+func (a *App) BackupNow() (database.SnapshotInfo, error) {
+	if a.db == nil {
+		return database.SnapshotInfo{}, fmt.Errorf("database not available")
 	}
-	return a.log.GetEntries(count)
+	if a.buffer != nil {
+		a.buffer.Flush()
+	}
+	snap, err := a.db.Snapshot(a.config.BackupDir(), "")
+	if err != nil {
+		return database.SnapshotInfo{}, err
+	}
+	if n, err := database.RotateSnapshots(a.config.BackupDir(), a.config.App.Backup.Keep); err != nil {
+		a.log.Errorf("app", "rotate snapshots: %v", err)
+	} else if n > 0 {
+		a.log.Infof("app", "removed %d old snapshots", n)
+	}
+	a.log.Infof("app", "database snapshot saved to %s", snap.Path)
+	return snap, nil
 }
 
-func (a *App) SetLogLevel(level string) {
-	if a.log != nil {
-		a.log.SetLevel(level)
+// RestoreBackup replaces the database contents with the named snapshot.
+// The current database is snapshotted first so the restore can be undone.
+func (a *App) RestoreBackup(name string) error {
+	if name == "" || name != filepath.Base(name) {
+		return fmt.Errorf("invalid snapshot name: %q", name)
 	}
-	a.config.App.LogLevel = level
-	a.config.Save()
+	path := filepath.Join(a.config.BackupDir(), name)
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("snapshot not found: %s", name)
+	}
+	return a.restoreDatabase(path)
 }
 
-// === Internal ===
-
-func (a *App) statsLoop() {
-	ticker := time.NewTicker(2 * time.Second)
-	defer ticker.Stop()
+// ExportBundle saves config.json and a database snapshot into a zip file
+// chosen by the user, for moving GoVault to another machine. Returns the
+// bundle path, or "" if the dialog was cancelled.
+func (a *App) ExportBundle() (string, error) {
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export GoVault Bundle",
+		DefaultFilename: "govault-bundle-" + time.Now().Format("20060102") + ".zip",
+		Filters:         []runtime.FileFilter{{DisplayName: "GoVault bundle (*.zip)", Pattern: "*.zip"}},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
 
-	hashrateTicker := time.NewTicker(60 * time.Second)
-	defer hashrateTicker.Stop()
+	if err := a.config.Save(); err != nil {
+		return "", fmt.Errorf("save config: %w", err)
+	}
+	files := map[string]string{"config.json": a.config.GetPath()}
 
-	nodeRefreshTicker := time.NewTicker(30 * time.Second)
-	defer nodeRefreshTicker.Stop()
+	if a.db != nil {
+		tmpDir, err := os.MkdirTemp("", "govault-export-")
+		if err != nil {
+			return "", err
+		}
+		defer os.RemoveAll(tmpDir)
+		if a.buffer != nil {
+			a.buffer.Flush()
+		}
+		snap, err := a.db.Snapshot(tmpDir, "export")
+		if err != nil {
+			return "", err
+		}
+		files["govault.db"] = snap.Path
+	}
 
-	cumulativeTicker := time.NewTicker(5 * time.Minute)
-	defer cumulativeTicker.Stop()
+	if err := writeBundle(path, files); err != nil {
+		return "", fmt.Errorf("write bundle: %w", err)
+	}
+	a.log.Infof("app", "exported bundle to %s", path)
+	return path, nil
+}
 
-	pruneTicker := time.NewTicker(1 * time.Hour)
-	defer pruneTicker.Stop()
+// ImportBundle loads a bundle created by ExportBundle: the database is
+// restored from its snapshot and the config replaced. Returns the bundle
+// path, or "" if the dialog was cancelled.
+func (a *App) ImportBundle() (string, error) {
+	path, err := runtime.OpenFileDialog(a.ctx, runtime.OpenDialogOptions{
+		Title:   "Import GoVault Bundle",
+		Filters: []runtime.FileFilter{{DisplayName: "GoVault bundle (*.zip)", Pattern: "*.zip"}},
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
 
-	proxyStatsTicker := time.NewTicker(30 * time.Second)
-	defer proxyStatsTicker.Stop()
+	tmpDir, err := os.MkdirTemp("", "govault-import-")
+	if err != nil {
+		return "", err
+	}
+	defer os.RemoveAll(tmpDir)
 
-	for {
-		select {
-		case <-a.stopStats:
-			return
-		case <-ticker.C:
-			stats := a.GetDashboardStats()
-			runtime.EventsEmit(a.ctx, "stats:updated", stats)
-		case <-hashrateTicker.C:
-			hashrate := a.stats.EstimateHashrate()
-			a.stats.RecordHashrate(hashrate)
-			if a.db != nil {
-				a.db.InsertHashrate(time.Now().Unix(), hashrate)
-			}
-			// Update per-miner hashrates in registry
-			for _, m := range a.registry.GetAll() {
-				hr := a.stats.EstimateMinerHashrate(m.ID)
-				a.registry.UpdateHashrate(m.ID, hr)
-			}
-		case <-cumulativeTicker.C:
-			a.saveCumulativeStats()
-		case <-pruneTicker.C:
-			a.pruneOldData()
-		case <-nodeRefreshTicker.C:
-			a.refreshNodeInfo()
-		case <-proxyStatsTicker.C:
-			a.svcMu.RLock()
-			srv := a.stratum
-			a.svcMu.RUnlock()
-			if srv != nil && srv.IsProxyMode() {
-				d := srv.GetProxyDiagnostics()
-				fwdRate := float64(0)
-				if d.SharesValid > 0 {
-					fwdRate = float64(d.SharesFwd) / float64(d.SharesValid) * 100
-				}
-				rejectRate := float64(0)
-				if d.SharesFwd > 0 {
-					rejectRate = float64(d.SharesRejected) / float64(d.SharesFwd) * 100
-				}
-				dropped := d.SharesIn - d.SharesValid - d.SharesDupe - d.SharesStale
-				a.log.Infof("proxy", "[STATS] in=%d valid=%d stale=%d dupe=%d other_reject=%d | fwd=%d(%.1f%%) accepted=%d rejected=%d(%.1f%%) below=%d upDiff=%.2f",
-					d.SharesIn, d.SharesValid, d.SharesStale, d.SharesDupe, dropped,
-					d.SharesFwd, fwdRate, d.SharesAccepted, d.SharesRejected, rejectRate,
-					d.SharesBelow, d.UpstreamDiff)
-				for name, diff := range d.MinerDiffs {
-					dupes := d.MinerDupes[name]
-					accepted := d.MinerAccepted[name]
-					total := accepted + dupes
-					dupeRate := float64(0)
-					if total > 0 {
-						dupeRate = float64(dupes) / float64(total) * 100
-					}
-					a.log.Infof("proxy", "[STATS]   miner=%s accepted=%d dupes=%d(%.1f%%) total=%d diff=%.2f",
-						name, accepted, dupes, dupeRate, total, diff)
-				}
-			}
-		}
+	files, err := readBundle(path, tmpDir, "config.json", "govault.db")
+	if err != nil {
+		return "", fmt.Errorf("read bundle: %w", err)
+	}
+	cfgPath, hasCfg := files["config.json"]
+	if !hasCfg {
+		return "", fmt.Errorf("bundle has no config.json")
 	}
-}
 
-func (a *App) refreshNodeInfo() {
-	// In proxy mode, network info comes from upstream job nBits
-	if a.config.MiningMode == "proxy" {
-		return
+	data, err := os.ReadFile(cfgPath)
+	if err != nil {
+		return "", err
+	}
+	newCfg := config.Defaults()
+	if err := json.Unmarshal(data, newCfg); err != nil {
+		return "", fmt.Errorf("parse bundle config: %w", err)
+	}
+	if err := newCfg.Validate(); err != nil {
+		return "", fmt.Errorf("bundle config: %w", err)
 	}
-	if info, err := a.nodeClient.GetMiningInfo(); err == nil {
-		// For multi-algo coins (DGB), use the per-algorithm difficulty and
-		// hashrate from the "difficulties"/"networkhashesps" maps. These are
-		// always correct regardless of which algorithm's turn it is.
-		miningAlgo := coin.Get(a.config.Mining.Coin).MiningAlgo
 
-		a.netMu.Lock()
-		a.blockHeight = info.Blocks
-		if miningAlgo != "" && len(info.Difficulties) > 0 {
-			if algoDiff, ok := info.Difficulties[miningAlgo]; ok {
-				a.networkDiff = algoDiff
-			}
-			if algoHash, ok := info.NetworkHashesPSs[miningAlgo]; ok {
-				a.networkHashrate = algoHash
-			}
-		} else {
-			a.networkDiff = info.Difficulty
-			a.networkHashrate = info.NetworkHashPS
+	// Restore the database first: it refuses snapshots from newer builds,
+	// and the config should not change if it does.
+	if dbPath, ok := files["govault.db"]; ok && a.db != nil {
+		if err := a.restoreDatabase(dbPath); err != nil {
+			return "", err
 		}
-		a.netMu.Unlock()
 	}
+	if err := a.UpdateConfig(newCfg); err != nil {
+		return "", err
+	}
+	a.log.Infof("app", "imported bundle from %s", path)
+	return path, nil
 }
 
-func (a *App) loadStatsFromDB() {
-	if a.db == nil {
+// scheduledBackup takes a snapshot when the newest one is older than the
+// configured backup interval.
+func (a *App) scheduledBackup() {
+	interval := time.Duration(a.config.App.Backup.IntervalHours) * time.Hour
+	if a.db == nil || interval == 0 {
 		return
 	}
-
-	cumulative, err := a.db.LoadCumulativeStats()
+	snapshots, err := database.ListSnapshots(a.config.BackupDir())
 	if err != nil {
-		if a.log != nil {
-			a.log.Errorf("app", "failed to load cumulative stats: %v", err)
-		}
+		a.log.Errorf("app", "list snapshots: %v", err)
 		return
 	}
-
-	// Load 7 days of hashrate history
-	since := time.Now().Add(-7 * 24 * time.Hour).Unix()
-	history, err := a.db.LoadHashrateHistory(since)
-	if err != nil {
-		if a.log != nil {
-			a.log.Errorf("app", "failed to load hashrate history: %v", err)
-		}
-	}
-
-	var points []miner.HashratePoint
-	for _, h := range history {
-		points = append(points, miner.HashratePoint{
-			Timestamp: h.Timestamp,
-			Hashrate:  h.Hashrate,
-		})
+	if len(snapshots) > 0 && time.Since(time.Unix(snapshots[0].CreatedAt, 0)) < interval {
+		return
 	}
-
-	a.stats.LoadFromDB(
-		cumulative.TotalAccepted,
-		cumulative.TotalRejected,
-		cumulative.BlocksFound,
-		cumulative.BestDifficulty,
-		points,
-	)
-
-	if a.log != nil {
-		a.log.Infof("app", "restored stats: %d accepted, %d rejected, %d blocks, %d hashrate points",
-			cumulative.TotalAccepted, cumulative.TotalRejected, cumulative.BlocksFound, len(points))
+	if _, err := a.BackupNow(); err != nil {
+		a.log.Errorf("app", "scheduled backup failed: %v", err)
 	}
 }
 
-func (a *App) saveCumulativeStats() {
+// restoreDatabase snapshots the current database, restores the one at
+// path, and reloads in-memory stats from it.
+func (a *App) restoreDatabase(path string) error {
 	if a.db == nil {
-		return
+		return fmt.Errorf("database not available")
 	}
-
-	accepted, rejected, blocks, bestDiff := a.stats.GetCumulativeStats()
-	err := a.db.SaveCumulativeStats(database.CumulativeStats{
-		TotalAccepted:  accepted,
-		TotalRejected:  rejected,
-		BestDifficulty: bestDiff,
-		BlocksFound:    blocks,
-	})
-	if err != nil && a.log != nil {
-		a.log.Errorf("app", "failed to save cumulative stats: %v", err)
+	if a.buffer != nil {
+		a.buffer.Flush()
+	}
+	if _, err := a.db.Snapshot(a.config.BackupDir(), "pre-restore"); err != nil {
+		return fmt.Errorf("snapshot before restore: %w", err)
 	}
+	if err := a.db.RestoreFrom(path); err != nil {
+		return err
+	}
+	a.loadStatsFromDB()
+	a.log.Infof("app", "database restored from %s", path)
+	runtime.EventsEmit(a.ctx, "database:restored", nil)
+	return nil
 }
 
-func (a *App) pruneOldData() {
+// writeBundle zips the given files (archive name -> source path) into path.
+func writeBundle(path string, files map[string]string) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	zw := zip.NewWriter(out)
+	for name, src := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			out.Close()
+			return err
+		}
+		in, err := os.Open(src)
+		if err != nil {
+			out.Close()
+			return err
+		}
+		_, err = io.Copy(w, in)
+		in.Close()
+		if err != nil {
+			out.Close()
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// readBundle extracts the named files from a bundle into dir and returns
+// their paths. Other archive entries are ignored.
+func readBundle(path, dir string, names ...string) (map[string]string, error) {
+	zr, err := zip.OpenReader(path)
+	if err != nil {
+		return nil, err
+	}
+	defer zr.Close()
+
+	files := make(map[string]string)
+	for _, f := range zr.File {
+		wanted := false
+		for _, n := range names {
+			if f.Name == n {
+				wanted = true
+			}
+		}
+		if !wanted {
+			continue
+		}
+		rc, err := f.Open()
+		if err != nil {
+			return nil, err
+		}
+		dst := filepath.Join(dir, f.Name)
+		out, err := os.Create(dst)
+		if err != nil {
+			rc.Close()
+			return nil, err
+		}
+		_, err = io.Copy(out, rc)
+		rc.Close()
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			return nil, err
+		}
+		files[f.Name] = dst
+	}
+	return files, nil
+}
+
+// === Roster ===
+
+// GetRoster returns the expected workers with their current condition.
+func (a *App) GetRoster() []miner.RosterStatus {
+	return a.roster.Status()
+}
+
+// SetRosterWorker adds a worker to the roster or updates its label and
+// nominal hashrate (H/s).
+func (a *App) SetRosterWorker(workerID, label string, nominalHashrate float64) error {
+	if workerID == "" {
+		return fmt.Errorf("worker id required")
+	}
+	if nominalHashrate < 0 {
+		return fmt.Errorf("nominal hashrate must not be negative")
+	}
+	if a.db == nil {
+		return fmt.Errorf("database not available")
+	}
+	if err := a.db.UpsertRosterEntry(database.RosterEntry{
+		WorkerID:        workerID,
+		Label:           label,
+		NominalHashrate: nominalHashrate,
+		AddedAt:         time.Now().Unix(),
+	}); err != nil {
+		return err
+	}
+	a.loadRoster()
+	return nil
+}
+
+// RemoveRosterWorker takes a worker off the roster.
+func (a *App) RemoveRosterWorker(workerID string) error {
+	if a.db == nil {
+		return fmt.Errorf("database not available")
+	}
+	if err := a.db.DeleteRosterEntry(workerID); err != nil {
+		return err
+	}
+	a.loadRoster()
+	return nil
+}
+
+// GetRosterEvents returns recent roster condition changes, optionally for
+// a single worker.
+func (a *App) GetRosterEvents(workerID string, limit int) []database.RosterEventEntry {
+	if a.db == nil {
+		return nil
+	}
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	events, err := a.db.RecentRosterEvents(workerID, limit)
+	if err != nil {
+		if a.log != nil {
+			a.log.Errorf("app", "roster events: %v", err)
+		}
+		return nil
+	}
+	return events
+}
+
+// loadRoster reads the roster from the database into the monitor.
+func (a *App) loadRoster() {
+	entries, err := a.db.ListRoster()
+	if err != nil {
+		if a.log != nil {
+			a.log.Errorf("app", "failed to load roster: %v", err)
+		}
+		return
+	}
+	roster := make([]miner.RosterEntry, len(entries))
+	for i, e := range entries {
+		roster[i] = miner.RosterEntry{
+			WorkerID:        e.WorkerID,
+			Label:           e.Label,
+			NominalHashrate: e.NominalHashrate,
+		}
+	}
+	a.roster.SetEntries(roster)
+}
+
+// evaluateRoster checks roster workers against the configured thresholds
+// and records and announces any condition changes.
+func (a *App) evaluateRoster() {
+	live := a.liveSessionsByWorker()
+	obs := make(map[string]miner.WorkerObservation)
+	for _, s := range a.roster.Status() {
+		o := miner.WorkerObservation{
+			Online:   len(live[s.WorkerID]) > 0,
+			Hashrate: a.stats.EstimateWorkerHashrate(s.WorkerID),
+		}
+		if o.Online {
+			o.LastSeen = time.Now()
+		}
+		obs[s.WorkerID] = o
+	}
+	if a.db != nil {
+		workers, err := a.db.ListWorkers()
+		if err == nil {
+			for _, w := range workers {
+				if o, ok := obs[w.ID]; ok && !o.Online {
+					o.LastSeen = time.Unix(w.LastSeen, 0)
+					obs[w.ID] = o
+				}
+			}
+		}
+	}
+
+	cfg := a.config.App.Roster
+	events := a.roster.Evaluate(time.Now(), miner.RosterThresholds{
+		Grace:           time.Duration(cfg.GraceMinutes) * time.Minute,
+		UnderperformPct: cfg.UnderperformPct,
+		UnderperformFor: time.Duration(cfg.UnderperformMinutes) * time.Minute,
+		RejectSpikePct:  cfg.RejectSpikePct,
+		RejectWindow:    time.Duration(cfg.RejectWindowMinutes) * time.Minute,
+		MinShares:       cfg.MinShares,
+	}, obs)
+
+	labels := make(map[string]string)
+	for _, s := range a.roster.Status() {
+		labels[s.WorkerID] = s.Label
+	}
+	for _, ev := range events {
+		if a.db != nil {
+			a.db.InsertRosterEvent(database.RosterEventEntry{
+				Timestamp: ev.Timestamp,
+				WorkerID:  ev.WorkerID,
+				Kind:      ev.Kind,
+				Detail:    ev.Detail,
+			})
+		}
+		runtime.EventsEmit(a.ctx, "roster:event", ev)
+
+		name := labels[ev.WorkerID]
+		if name == "" {
+			name = ev.WorkerID
+		}
+		a.log.Infof("roster", "%s: %s (%s)", name, ev.Kind, ev.Detail)
+		a.notify(rosterNotification(name, ev))
+	}
+}
+
+// rosterNotification turns a roster event into a notification.
+func rosterNotification(name string, ev miner.RosterEvent) notify.Event {
+	n := notify.Event{
+		Message: ev.Detail,
+		Time:    time.Unix(ev.Timestamp, 0),
+		Fields:  []notify.Field{{Name: "Worker", Value: ev.WorkerID}},
+		Subject: ev.WorkerID,
+	}
+	switch ev.Kind {
+	case miner.RosterEventMissing:
+		n.Type, n.Title = notify.EventWorkerMissing, "Worker missing: "+name
+	case miner.RosterEventReturned:
+		n.Type, n.Title = notify.EventWorkerMissing, "Worker back: "+name
+		n.Recovery = true
+	case miner.RosterEventUnderperforming:
+		n.Type, n.Title = notify.EventWorkerUnderperforming, "Worker underperforming: "+name
+	case miner.RosterEventRecovered:
+		n.Type, n.Title = notify.EventWorkerUnderperforming, "Worker hashrate recovered: "+name
+		n.Recovery = true
+	case miner.RosterEventRejectSpike:
+		n.Type, n.Title = notify.EventWorkerRejectSpike, "Reject spike: "+name
+	default:
+		n.Type, n.Title = notify.EventWorkerRejectSpike, "Rejects back to normal: "+name
+		n.Recovery = true
+	}
+	return n
+}
+
+// === Device Management ===
+
+// DeviceChangeResult is the outcome of a device action on one device.
+type DeviceChangeResult struct {
+	IP       string `json:"ip"`
+	ChangeID int64  `json:"changeId"`
+	OK       bool   `json:"ok"`
+	Error    string `json:"error,omitempty"`
+}
+
+// GetDeviceLimits returns the safe frequency, voltage and fan limits per
+// ASIC model.
+func (a *App) GetDeviceLimits() []miner.DeviceLimits {
+	limits := miner.AllDeviceLimits()
+	sort.Slice(limits, func(i, j int) bool { return limits[i].Model < limits[j].Model })
+	return limits
+}
+
+// GetDeviceSettings returns the current changeable settings of a device.
+func (a *App) GetDeviceSettings(ip string) map[string]interface{} {
+	settings, model, err := a.discovery.ReadDeviceSettings(ip)
+	if err != nil {
+		return map[string]interface{}{"error": err.Error()}
+	}
+	result := map[string]interface{}{
+		"ip":       ip,
+		"model":    model,
+		"settings": settings,
+	}
+	if limits, ok := miner.LimitsForModel(model); ok {
+		result["limits"] = limits
+	}
+	return result
+}
+
+// ApplyDeviceSettings pushes the same settings to every selected device and
+// records the previous values so the change can be rolled back. Frequency
+// and voltage only take effect after a restart, which is done when restart
+// is set.
+func (a *App) ApplyDeviceSettings(ips []string, settings miner.DeviceSettings, restart bool) []DeviceChangeResult {
+	batchID := fmt.Sprintf("%d", time.Now().UnixNano())
+	return a.eachDevice(ips, func(ip string) DeviceChangeResult {
+		return a.applyDeviceChange(ip, batchID, "apply", settings, restart)
+	})
+}
+
+// RestartDevices restarts every selected device.
+func (a *App) RestartDevices(ips []string) []DeviceChangeResult {
+	batchID := fmt.Sprintf("%d", time.Now().UnixNano())
+	return a.eachDevice(ips, func(ip string) DeviceChangeResult {
+		res := DeviceChangeResult{IP: ip}
+		err := a.discovery.RestartDevice(ip)
+		res.ChangeID = a.recordDeviceChange(ip, batchID, "restart", nil, nil, err)
+		if err != nil {
+			res.Error = err.Error()
+			return res
+		}
+		res.OK = true
+		return res
+	})
+}
+
+// RollbackDeviceChanges restores the settings recorded before each of the
+// given changes. Password changes cannot be rolled back because AxeOS never
+// reports the old value.
+func (a *App) RollbackDeviceChanges(changeIDs []int64, restart bool) []DeviceChangeResult {
+	if a.db == nil {
+		return nil
+	}
+	batchID := fmt.Sprintf("%d", time.Now().UnixNano())
+	results := make([]DeviceChangeResult, 0, len(changeIDs))
+	for _, id := range changeIDs {
+		change, err := a.db.GetDeviceChange(id)
+		if err == nil && change == nil {
+			err = fmt.Errorf("change %d not found", id)
+		} else if err == nil && change.Action == "restart" {
+			err = fmt.Errorf("change %d is a restart", id)
+		} else if err == nil && change.RolledBackBy != 0 {
+			err = fmt.Errorf("change %d was already rolled back", id)
+		}
+		var before miner.DeviceSettings
+		if err == nil {
+			err = json.Unmarshal([]byte(change.Before), &before)
+		}
+		if err != nil {
+			res := DeviceChangeResult{Error: err.Error()}
+			if change != nil {
+				res.IP = change.IP
+			}
+			results = append(results, res)
+			continue
+		}
+
+		res := a.applyDeviceChange(change.IP, batchID, "rollback", before, restart)
+		if res.OK {
+			if err := a.db.MarkDeviceChangeRolledBack(id, res.ChangeID); err != nil {
+				a.log.Errorf("devices", "mark change %d rolled back: %v", id, err)
+			}
+		}
+		results = append(results, res)
+	}
+	return results
+}
+
+// GetDeviceChanges returns recent device changes, newest first. An empty ip
+// returns changes for all devices.
+func (a *App) GetDeviceChanges(ip string, limit int) []database.DeviceChangeEntry {
+	if a.db == nil {
+		return nil
+	}
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	changes, err := a.db.RecentDeviceChanges(ip, limit)
+	if err != nil {
+		a.log.Errorf("app", "device changes: %v", err)
+		return nil
+	}
+	return changes
+}
+
+// applyDeviceChange reads a device's current settings, validates the new
+// ones against its ASIC model, applies them and records the change.
+func (a *App) applyDeviceChange(ip, batchID, action string, settings miner.DeviceSettings, restart bool) DeviceChangeResult {
+	res := DeviceChangeResult{IP: ip}
+
+	current, model, err := a.discovery.ReadDeviceSettings(ip)
+	if err != nil {
+		res.Error = fmt.Sprintf("read settings: %v", err)
+		return res
+	}
+	if err := settings.Validate(model); err != nil {
+		res.Error = err.Error()
+		return res
+	}
+
+	before := current.Only(settings)
+	err = a.discovery.ApplyDeviceSettings(ip, settings)
+	if err == nil && restart {
+		if rerr := a.discovery.RestartDevice(ip); rerr != nil {
+			err = fmt.Errorf("settings applied but restart failed: %w", rerr)
+		}
+	}
+
+	// Never store passwords in the change log.
+	after := settings
+	after.StratumPassword = nil
+	after.FallbackStratumPassword = nil
+	res.ChangeID = a.recordDeviceChange(ip, batchID, action, before, after, err)
+	if err != nil {
+		res.Error = err.Error()
+		return res
+	}
+	res.OK = true
+	a.log.Infof("devices", "%s on %s succeeded", action, ip)
+	return res
+}
+
+// recordDeviceChange stores a device change and returns its ID.
+func (a *App) recordDeviceChange(ip, batchID, action string, before, after interface{}, applyErr error) int64 {
+	if a.db == nil {
+		return 0
+	}
+	entry := database.DeviceChangeEntry{
+		Timestamp: time.Now().Unix(),
+		BatchID:   batchID,
+		IP:        ip,
+		Action:    action,
+		Before:    "{}",
+		After:     "{}",
+		Status:    "ok",
+	}
+	if before != nil {
+		if b, err := json.Marshal(before); err == nil {
+			entry.Before = string(b)
+		}
+	}
+	if after != nil {
+		if b, err := json.Marshal(after); err == nil {
+			entry.After = string(b)
+		}
+	}
+	if applyErr != nil {
+		entry.Status = "failed"
+		entry.Error = applyErr.Error()
+	}
+	id, err := a.db.InsertDeviceChange(entry)
+	if err != nil {
+		a.log.Errorf("devices", "record change for %s: %v", ip, err)
+	}
+	return id
+}
+
+// eachDevice runs fn concurrently for every IP and returns results in
+// input order.
+func (a *App) eachDevice(ips []string, fn func(ip string) DeviceChangeResult) []DeviceChangeResult {
+	results := make([]DeviceChangeResult, len(ips))
+	sem := make(chan struct{}, 8)
+	var wg sync.WaitGroup
+	for i, ip := range ips {
+		wg.Add(1)
+		go func(i int, ip string) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = fn(ip)
+		}(i, ip)
+	}
+	wg.Wait()
+	return results
+}
+
+// === Device Telemetry ===
+
+// GetDeviceTelemetry returns the latest telemetry reading of every device.
+func (a *App) GetDeviceTelemetry() []miner.DeviceTelemetry {
+	a.telemetryMu.RLock()
+	result := make([]miner.DeviceTelemetry, 0, len(a.telemetry))
+	for _, t := range a.telemetry {
+		result = append(result, t)
+	}
+	a.telemetryMu.RUnlock()
+	sort.Slice(result, func(i, j int) bool { return result[i].IP < result[j].IP })
+	return result
+}
+
+// GetDeviceTelemetryHistory returns a device's stored telemetry for the
+// given period ("1h", "6h", "24h", "7d", "30d").
+func (a *App) GetDeviceTelemetryHistory(ip string, period string) []database.TelemetryEntry {
+	if a.db == nil {
+		return nil
+	}
+	window, _ := historyWindow(period)
+	entries, err := a.db.TelemetryHistory(ip, time.Now().Add(-window).Unix())
+	if err != nil {
+		if a.log != nil {
+			a.log.Errorf("app", "telemetry history: %v", err)
+		}
+		return nil
+	}
+	return entries
+}
+
+// GetDeviceAlerts returns recent device alerts, newest first.
+func (a *App) GetDeviceAlerts(limit int) []database.DeviceAlertEntry {
+	if a.db == nil {
+		return nil
+	}
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	alerts, err := a.db.RecentDeviceAlerts(limit)
+	if err != nil {
+		if a.log != nil {
+			a.log.Errorf("app", "device alerts: %v", err)
+		}
+		return nil
+	}
+	return alerts
+}
+
+// telemetryLoop polls device telemetry at the configured interval.
+func (a *App) telemetryLoop() {
+	for {
+		interval := time.Duration(a.config.App.Telemetry.IntervalSec) * time.Second
+		enabled := interval > 0
+		if !enabled {
+			interval = time.Minute // re-check the setting
+		}
+		select {
+		case <-a.stopStats:
+			return
+		case <-time.After(interval):
+			if enabled {
+				a.collectTelemetry()
+			}
+		}
+	}
+}
+
+// collectTelemetry polls every known device, stores the readings and
+// checks them against the alert thresholds.
+func (a *App) collectTelemetry() {
+	// Pool-side hashrate per host, and every IP we know a device at.
+	// minesHere marks devices that should be sending us shares: they have
+	// a live session or are configured with our stratum URL.
+	poolHashrate := make(map[string]float64)
+	workersByHost := make(map[string][]string)
+	ips := make(map[string]bool)
+	minesHere := make(map[string]bool)
+	for _, m := range a.registry.GetAll() {
+		host := splitHost(m.IPAddress)
+		poolHashrate[host] += a.stats.EstimateMinerHashrate(m.ID)
+		if !slices.Contains(workersByHost[host], m.WorkerID) {
+			workersByHost[host] = append(workersByHost[host], m.WorkerID)
+		}
+		ips[host] = true
+		minesHere[host] = true
+	}
+	localIP := miner.GetLocalIP()
+	for _, d := range a.discovery.Known() {
+		ips[d.IP] = true
+		if a.isOurPool(d.CurrentPool, localIP) {
+			minesHere[d.IP] = true
+		}
+	}
+
+	list := make([]string, 0, len(ips))
+	for ip := range ips {
+		list = append(list, ip)
+	}
+	readings := a.discovery.CollectTelemetry(list)
+
+	entries := make([]database.TelemetryEntry, len(readings))
+	a.telemetryMu.Lock()
+	for i := range readings {
+		t := &readings[i]
+		t.PoolHashrate = poolHashrate[t.IP]
+		a.telemetry[t.IP] = *t
+		entries[i] = database.TelemetryEntry{
+			Timestamp:    t.Timestamp,
+			IP:           t.IP,
+			Hostname:     t.Hostname,
+			Temp:         t.Temp,
+			VRTemp:       t.VRTemp,
+			Power:        t.Power,
+			Voltage:      t.Voltage,
+			Current:      t.Current,
+			Frequency:    t.Frequency,
+			FanSpeed:     t.FanSpeed,
+			FanRPM:       t.FanRPM,
+			Hashrate:     t.Hashrate,
+			PoolHashrate: t.PoolHashrate,
+			BestDiff:     t.BestDiff,
+			FreeHeap:     t.FreeHeap,
+		}
+	}
+	a.telemetryMu.Unlock()
+
+	if a.db != nil {
+		if err := a.db.InsertTelemetry(entries); err != nil {
+			a.log.Errorf("app", "failed to store telemetry: %v", err)
+		}
+	}
+	a.meterEnergy(readings, workersByHost)
+	for _, t := range readings {
+		a.checkDeviceAlerts(t, minesHere[t.IP])
+	}
+	runtime.EventsEmit(a.ctx, "telemetry:update", readings)
+}
+
+// checkDeviceAlerts compares a reading with the configured thresholds.
+// Temperatures alert after two readings in a row; the hashrate comparison
+// needs a longer streak because the pool-side estimate is noisy.
+func (a *App) checkDeviceAlerts(t miner.DeviceTelemetry, minesHere bool) {
+	cfg := a.config.App.Telemetry
+	name := t.Hostname
+	if name == "" {
+		name = t.IP
+	}
+
+	check := func(metric string, enabled, breached bool, value, threshold float64, sustain int, detail string) {
+		raised, cleared := a.deviceAlerts.Check(t.IP+"/"+metric, enabled && breached, sustain)
+		if !raised && !cleared {
+			return
+		}
+		state, title := "raised", fmt.Sprintf("%s: %s", name, detail)
+		if cleared {
+			state, title = "cleared", fmt.Sprintf("%s: %s back to normal", name, metric)
+		}
+		if a.db != nil {
+			a.db.InsertDeviceAlert(database.DeviceAlertEntry{
+				Timestamp: t.Timestamp,
+				IP:        t.IP,
+				Metric:    metric,
+				Value:     value,
+				Threshold: threshold,
+				State:     state,
+				Detail:    detail,
+			})
+		}
+		a.log.Warnf("telemetry", "%s", title)
+		runtime.EventsEmit(a.ctx, "telemetry:alert", map[string]interface{}{
+			"ip": t.IP, "metric": metric, "state": state, "value": value, "threshold": threshold,
+		})
+		a.notify(notify.Event{
+			Type:     notify.EventDeviceAlert,
+			Subject:  t.IP + "/" + metric,
+			Recovery: cleared,
+			Title:    title,
+			Message:  detail,
+			Fields: []notify.Field{
+				{Name: "Device", Value: name},
+				{Name: "IP", Value: t.IP},
+			},
+		})
+	}
+
+	check("temp", cfg.MaxTemp > 0, t.Temp > cfg.MaxTemp, t.Temp, cfg.MaxTemp, 2,
+		fmt.Sprintf("ASIC temperature %.1f°C above %.0f°C", t.Temp, cfg.MaxTemp))
+	check("vr_temp", cfg.MaxVRTemp > 0, t.VRTemp > cfg.MaxVRTemp, t.VRTemp, cfg.MaxVRTemp, 2,
+		fmt.Sprintf("VR temperature %.1f°C above %.0f°C", t.VRTemp, cfg.MaxVRTemp))
+	check("free_heap", cfg.MinFreeHeap > 0, t.FreeHeap < cfg.MinFreeHeap, float64(t.FreeHeap), float64(cfg.MinFreeHeap), 3,
+		fmt.Sprintf("free heap %d bytes below %d", t.FreeHeap, cfg.MinFreeHeap))
+
+	// Device-reported vs. pool-side hashrate: a large gap means the firmware
+	// over-reports or work is being lost between device and pool. A device
+	// hashing for us while the pool sees nothing is the worst case.
+	var mismatch float64
+	if t.Hashrate > 0 {
+		mismatch = math.Abs(t.Hashrate-t.PoolHashrate) / t.Hashrate * 100
+	}
+	detail := fmt.Sprintf("device reports %.2f GH/s but pool sees %.2f GH/s (%.0f%% apart)",
+		t.Hashrate/1e9, t.PoolHashrate/1e9, mismatch)
+	if t.PoolHashrate == 0 {
+		detail = fmt.Sprintf("device reports %.2f GH/s but pool sees no shares", t.Hashrate/1e9)
+	}
+	check("hashrate_mismatch", cfg.MaxHashrateMismatchPct > 0 && t.Hashrate > 0 && (t.PoolHashrate > 0 || minesHere),
+		mismatch > cfg.MaxHashrateMismatchPct, mismatch, cfg.MaxHashrateMismatchPct, 10, detail)
+}
+
+// isOurPool reports whether a device's pool URL points at this stratum
+// server.
+func (a *App) isOurPool(poolURL, localIP string) bool {
+	if _, rest, ok := strings.Cut(poolURL, "://"); ok {
+		poolURL = rest
+	}
+	host, port, err := net.SplitHostPort(strings.TrimSuffix(poolURL, "/"))
+	return err == nil && localIP != "" && host == localIP && port == strconv.Itoa(a.config.Stratum.Port)
+}
+
+// === Notifications ===
+
+// GetNotificationEvents returns the event types channel rules can match.
+func (a *App) GetNotificationEvents() []string {
+	return notify.EventTypes()
+}
+
+// SendTestNotification sends a test message to a channel and waits for it
+// to be delivered.
+func (a *App) SendTestNotification(channelID string) error {
+	if a.notifier == nil {
+		return fmt.Errorf("notifications not initialized")
+	}
+	return a.notifier.SendTest(channelID)
+}
+
+// GetNotificationLog returns the most recent notification deliveries.
+func (a *App) GetNotificationLog(limit int) []database.NotificationEntry {
+	if a.db == nil {
+		return nil
+	}
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	entries, err := a.db.RecentNotifications(limit)
+	if err != nil {
+		if a.log != nil {
+			a.log.Errorf("app", "notification log: %v", err)
+		}
+		return nil
+	}
+	return entries
+}
+
+func (a *App) notify(ev notify.Event) {
+	if a.notifier != nil {
+		a.notifier.Notify(ev)
+	}
+}
+
+// === Export ===
+
+// ExportData writes a dataset ("shares", "blocks", "sessions", "hashrate" or
+// "cumulative") as "csv" or "ndjson" to a file chosen by the user. from and
+// to are unix seconds (0 = unbounded); worker filters by worker name or ID.
+// Returns the file path, or "" if the dialog was cancelled.
+func (a *App) ExportData(dataset, format string, from, to int64, worker string) (string, error) {
+	if a.db == nil {
+		return "", fmt.Errorf("database not available")
+	}
+	ext := "csv"
+	if format == "ndjson" {
+		ext = "ndjson"
+	}
+	path, err := runtime.SaveFileDialog(a.ctx, runtime.SaveDialogOptions{
+		Title:           "Export " + dataset,
+		DefaultFilename: fmt.Sprintf("govault-%s-%s.%s", dataset, time.Now().Format("20060102"), ext),
+	})
+	if err != nil || path == "" {
+		return "", err
+	}
+
+	if a.buffer != nil {
+		a.buffer.Flush()
+	}
+	out, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	n, err := a.db.Export(out, dataset, format, database.ExportFilter{From: from, To: to, Worker: worker})
+	if cerr := out.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", fmt.Errorf("export %s: %w", dataset, err)
+	}
+	a.log.Infof("app", "exported %d %s rows to %s", n, dataset, path)
+	return path, nil
+}
+
+// === Logs ===
+
+func (a *App) GetRecentLogs(count int) []logger.LogEntry {
+	if a.log == nil {
+		return nil
+	}
+	return a.log.GetEntries(count)
+}
+
+func (a *App) SetLogLevel(level string) {
+	if a.log != nil {
+		a.log.SetLevel(level)
+	}
+	a.config.App.LogLevel = level
+	a.config.Save()
+}
+
+// === Internal ===
+
+func (a *App) statsLoop() {
+	ticker := time.NewTicker(2 * time.Second)
+	defer ticker.Stop()
+
+	hashrateTicker := time.NewTicker(60 * time.Second)
+	defer hashrateTicker.Stop()
+
+	nodeRefreshTicker := time.NewTicker(30 * time.Second)
+	defer nodeRefreshTicker.Stop()
+
+	cumulativeTicker := time.NewTicker(5 * time.Minute)
+	defer cumulativeTicker.Stop()
+
+	pruneTicker := time.NewTicker(1 * time.Hour)
+	defer pruneTicker.Stop()
+
+	proxyStatsTicker := time.NewTicker(30 * time.Second)
+	defer proxyStatsTicker.Stop()
+
+	for {
+		select {
+		case <-a.stopStats:
+			return
+		case <-ticker.C:
+			stats := a.GetDashboardStats()
+			runtime.EventsEmit(a.ctx, "stats:updated", stats)
+		case <-hashrateTicker.C:
+			if a.rounds.EpochDue(time.Duration(a.config.App.RoundEpochHours) * time.Hour) {
+				a.closeRound("epoch", 0, "")
+			}
+			hashrate := a.stats.EstimateHashrate()
+			a.stats.RecordHashrate(hashrate)
+			if a.db != nil {
+				a.db.InsertHashrate(time.Now().Unix(), hashrate)
+			}
+			// Update per-miner hashrates in registry
+			for _, m := range a.registry.GetAll() {
+				hr := a.stats.EstimateMinerHashrate(m.ID)
+				a.registry.UpdateHashrate(m.ID, hr)
+			}
+			a.evaluateRoster()
+		case <-cumulativeTicker.C:
+			a.saveCumulativeStats()
+			a.saveRound()
+			a.rollupShares()
+		case <-pruneTicker.C:
+			a.pruneOldData()
+			a.scheduledBackup()
+		case <-nodeRefreshTicker.C:
+			a.refreshNodeInfo()
+		case <-proxyStatsTicker.C:
+			a.svcMu.RLock()
+			srv := a.stratum
+			a.svcMu.RUnlock()
+			if srv != nil && srv.IsProxyMode() {
+				d := srv.GetProxyDiagnostics()
+				fwdRate := float64(0)
+				if d.SharesValid > 0 {
+					fwdRate = float64(d.SharesFwd) / float64(d.SharesValid) * 100
+				}
+				rejectRate := float64(0)
+				if d.SharesFwd > 0 {
+					rejectRate = float64(d.SharesRejected) / float64(d.SharesFwd) * 100
+				}
+				dropped := d.SharesIn - d.SharesValid - d.SharesDupe - d.SharesStale - d.SharesStaleBlock
+				a.log.Infof("proxy", "[STATS] in=%d valid=%d stale=%d stale_block=%d dupe=%d other_reject=%d | fwd=%d(%.1f%%) accepted=%d rejected=%d(%.1f%%) below=%d upDiff=%.2f",
+					d.SharesIn, d.SharesValid, d.SharesStale, d.SharesStaleBlock, d.SharesDupe, dropped,
+					d.SharesFwd, fwdRate, d.SharesAccepted, d.SharesRejected, rejectRate,
+					d.SharesBelow, d.UpstreamDiff)
+				for name, diff := range d.MinerDiffs {
+					dupes := d.MinerDupes[name]
+					accepted := d.MinerAccepted[name]
+					total := accepted + dupes
+					dupeRate := float64(0)
+					if total > 0 {
+						dupeRate = float64(dupes) / float64(total) * 100
+					}
+					a.log.Infof("proxy", "[STATS]   miner=%s accepted=%d dupes=%d(%.1f%%) total=%d diff=%.2f",
+						name, accepted, dupes, dupeRate, total, diff)
+				}
+			}
+		}
+	}
+}
+
+func (a *App) refreshNodeInfo() {
+	// In proxy mode, network info comes from upstream job nBits
+	if a.config.MiningMode == "proxy" {
+		return
+	}
+	if info, err := a.nodeClient.GetMiningInfo(); err == nil {
+		// For multi-algo coins (DGB), use the per-algorithm difficulty and
+		// hashrate from the "difficulties"/"networkhashesps" maps. These are
+		// always correct regardless of which algorithm's turn it is.
+		miningAlgo := coin.Get(a.config.Mining.Coin).MiningAlgo
+
+		a.netMu.Lock()
+		a.blockHeight = info.Blocks
+		if miningAlgo != "" && len(info.Difficulties) > 0 {
+			if algoDiff, ok := info.Difficulties[miningAlgo]; ok {
+				a.networkDiff = algoDiff
+			}
+			if algoHash, ok := info.NetworkHashesPSs[miningAlgo]; ok {
+				a.networkHashrate = algoHash
+			}
+		} else {
+			a.networkDiff = info.Difficulty
+			a.networkHashrate = info.NetworkHashPS
+		}
+		a.netMu.Unlock()
+	}
+	a.checkNodeSync()
+}
+
+// checkNodeSync notifies when the node stops being usable for mining:
+// unreachable, in initial block download, or behind its own headers.
+func (a *App) checkNodeSync() {
+	var problem string
+	info, err := a.nodeClient.GetBlockchainInfo()
+	switch {
+	case err != nil:
+		problem = fmt.Sprintf("node unreachable: %v", err)
+	case info.InitialBlockDownload:
+		problem = fmt.Sprintf("node is in initial block download (%.1f%%)", info.VerificationProgress*100)
+	case info.Headers-info.Blocks > 2:
+		problem = fmt.Sprintf("node is %d blocks behind its headers", info.Headers-info.Blocks)
+	}
+
+	a.netMu.Lock()
+	was := a.nodeOutOfSync
+	a.nodeOutOfSync = problem != ""
+	a.netMu.Unlock()
+
+	if problem != "" && !was {
+		a.log.Warnf("app", "node out of sync: %s", problem)
+		a.notify(notify.Event{
+			Type:    notify.EventNodeOutOfSync,
+			Title:   "Node out of sync",
+			Message: problem,
+			Fields:  []notify.Field{{Name: "Node", Value: fmt.Sprintf("%s:%d", a.config.Node.Host, a.config.Node.Port)}},
+		})
+	} else if problem == "" && was {
+		a.log.Infof("app", "node back in sync")
+	}
+}
+
+func (a *App) loadStatsFromDB() {
+	if a.db == nil {
+		return
+	}
+
+	cumulative, err := a.db.LoadCumulativeStats()
+	if err != nil {
+		if a.log != nil {
+			a.log.Errorf("app", "failed to load cumulative stats: %v", err)
+		}
+		return
+	}
+
+	// Load 7 days of hashrate history
+	since := time.Now().Add(-7 * 24 * time.Hour).Unix()
+	history, err := a.db.LoadHashrateHistory(since)
+	if err != nil {
+		if a.log != nil {
+			a.log.Errorf("app", "failed to load hashrate history: %v", err)
+		}
+	}
+
+	var points []miner.HashratePoint
+	for _, h := range history {
+		points = append(points, miner.HashratePoint{
+			Timestamp: h.Timestamp,
+			Hashrate:  h.Hashrate,
+		})
+	}
+
+	a.stats.LoadFromDB(
+		cumulative.TotalAccepted,
+		cumulative.TotalRejected,
+		cumulative.BlocksFound,
+		cumulative.BestDifficulty,
+		points,
+	)
+
+	if a.log != nil {
+		a.log.Infof("app", "restored stats: %d accepted, %d rejected, %d blocks, %d hashrate points",
+			cumulative.TotalAccepted, cumulative.TotalRejected, cumulative.BlocksFound, len(points))
+	}
+
+	a.loadRound()
+	a.loadRoster()
+}
+
+// loadRound resumes the open round from the database, or starts a new one.
+func (a *App) loadRound() {
+	open, err := a.db.OpenRound()
+	if err != nil {
+		if a.log != nil {
+			a.log.Errorf("app", "failed to load current round: %v", err)
+		}
+		return
+	}
+
+	if open == nil {
+		now := time.Now()
+		id, err := a.db.InsertRound(database.RoundEntry{StartedAt: now.Unix()})
+		if err != nil {
+			if a.log != nil {
+				a.log.Errorf("app", "failed to start round: %v", err)
+			}
+			return
+		}
+		a.rounds.Rotate(id, now)
+		return
+	}
+
+	entries, err := a.db.RoundWorkers(open.ID)
+	if err != nil && a.log != nil {
+		a.log.Errorf("app", "failed to load round workers: %v", err)
+	}
+	workers := make([]miner.WorkerRoundStats, len(entries))
+	for i, w := range entries {
+		workers[i] = miner.WorkerRoundStats{
+			Worker:    w.Worker,
+			Work:      w.Work,
+			Shares:    w.Shares,
+			BestShare: w.BestShare,
+		}
+	}
+	a.rounds.Restore(open.ID, time.Unix(open.StartedAt, 0), open.Work, open.Effort, open.ExpectedWork,
+		open.Shares, open.BestShare, open.BestWorker, workers)
+
+	if a.log != nil {
+		a.log.Infof("app", "resumed round #%d: effort %.2f%%, best share %.0f", open.ID, open.Effort, open.BestShare)
+	}
+}
+
+// saveRound persists the running totals of the current round. It holds
+// roundMu so a round closed concurrently is not written back as open.
+func (a *App) saveRound() {
+	if a.db == nil {
+		return
+	}
+	a.roundMu.Lock()
+	defer a.roundMu.Unlock()
+
+	round := a.rounds.Snapshot()
+	if round.ID == 0 {
+		return
+	}
+	if err := a.db.UpdateRound(roundEntryFromStats(round)); err != nil && a.log != nil {
+		a.log.Errorf("app", "failed to save round: %v", err)
+	}
+	if err := a.db.SaveRoundWorkers(roundWorkerEntries(round)); err != nil && a.log != nil {
+		a.log.Errorf("app", "failed to save round workers: %v", err)
+	}
+}
+
+// closeRound ends the current round (reason "block" or "epoch") and starts
+// a new one.
+func (a *App) closeRound(reason string, height int64, hash string) {
+	a.roundMu.Lock()
+	defer a.roundMu.Unlock()
+
+	now := time.Now()
+	var nextID int64
+	if a.db != nil {
+		id, err := a.db.InsertRound(database.RoundEntry{StartedAt: now.Unix()})
+		if err != nil && a.log != nil {
+			a.log.Errorf("app", "failed to start round: %v", err)
+		}
+		nextID = id
+	}
+
+	ended := a.rounds.Rotate(nextID, now)
+
+	if a.db != nil && ended.ID > 0 {
+		entry := roundEntryFromStats(ended)
+		entry.EndedAt = now.Unix()
+		entry.EndReason = reason
+		entry.BlockHeight = height
+		entry.BlockHash = hash
+		if err := a.db.UpdateRound(entry); err != nil && a.log != nil {
+			a.log.Errorf("app", "failed to close round: %v", err)
+		}
+		if err := a.db.SaveRoundWorkers(roundWorkerEntries(ended)); err != nil && a.log != nil {
+			a.log.Errorf("app", "failed to save round workers: %v", err)
+		}
+	}
+
+	if a.log != nil {
+		a.log.Infof("app", "round #%d ended (%s): effort %.2f%%, %d shares, best share %.0f by %s",
+			ended.ID, reason, ended.Effort, ended.Shares, ended.BestShare, ended.BestWorker)
+	}
+	runtime.EventsEmit(a.ctx, "round:ended", map[string]interface{}{
+		"id":     ended.ID,
+		"reason": reason,
+		"effort": ended.Effort,
+	})
+}
+
+func roundEntryFromStats(r miner.RoundStats) database.RoundEntry {
+	return database.RoundEntry{
+		ID:           r.ID,
+		StartedAt:    r.StartedAt,
+		Work:         r.Work,
+		ExpectedWork: r.ExpectedWork,
+		Effort:       r.Effort,
+		Shares:       r.Shares,
+		BestShare:    r.BestShare,
+		BestWorker:   r.BestWorker,
+	}
+}
+
+func roundWorkerEntries(r miner.RoundStats) []database.RoundWorkerEntry {
+	entries := make([]database.RoundWorkerEntry, len(r.Workers))
+	for i, w := range r.Workers {
+		entries[i] = database.RoundWorkerEntry{
+			RoundID:   r.ID,
+			Worker:    w.Worker,
+			Work:      w.Work,
+			Shares:    w.Shares,
+			BestShare: w.BestShare,
+		}
+	}
+	return entries
+}
+
+// workerID returns the persistent worker ID of a connected session, falling
+// back to the session ID if the miner is no longer registered.
+func (a *App) workerID(minerID string) string {
+	if m := a.registry.Get(minerID); m != nil && m.WorkerID != "" {
+		return m.WorkerID
+	}
+	return minerID
+}
+
+// historyWindow maps a history period to its time window and bucket size.
+func historyWindow(period string) (time.Duration, int64) {
+	switch period {
+	case "1h":
+		return time.Hour, 120
+	case "6h":
+		return 6 * time.Hour, 600
+	case "7d":
+		return 7 * 24 * time.Hour, 3600
+	case "30d":
+		return 30 * 24 * time.Hour, 4 * 3600
+	case "90d":
+		return 90 * 24 * time.Hour, 12 * 3600
+	case "1y":
+		return 365 * 24 * time.Hour, 24 * 3600
+	default:
+		return 24 * time.Hour, 900
+	}
+}
+
+// recordRejectedShare records a rejected share with its reject code.
+func (a *App) recordRejectedShare(minerID string, r stratum.ShareReject) {
+	workerID := a.workerID(minerID)
+	// A submit before authorize has no worker yet: its session ID must not
+	// turn up in the registry, stats or roster.
+	if r.Code != stratum.RejectUnauthorized {
+		a.registry.RecordShare(minerID, 0, false)
+		a.stats.RecordShare(minerID, workerID, 0, false)
+		a.roster.RecordShare(workerID, false, r.IsStale())
+	}
+	if a.buffer != nil {
+		a.buffer.AddShare(database.ShareEntry{
+			Timestamp:    time.Now().Unix(),
+			MinerID:      minerID,
+			Worker:       workerID,
+			Accepted:     false,
+			RejectCode:   r.Code,
+			RejectReason: r.Detail,
+			JobAgeMs:     r.Age.Job.Milliseconds(),
+			CleanAgeMs:   r.Age.Clean.Milliseconds(),
+			TipAgeMs:     r.TipAge.Milliseconds(),
+		})
+	}
+	runtime.EventsEmit(a.ctx, "stratum:share-rejected", map[string]interface{}{
+		"minerId":  minerID,
+		"code":     r.Code,
+		"reason":   r.Detail,
+		"tipAgeMs": r.TipAge.Milliseconds(),
+	})
+}
+
+// splitHost strips the port from a "host:port" address.
+func splitHost(addr string) string {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return addr
+	}
+	return host
+}
+
+func (a *App) saveCumulativeStats() {
+	if a.db == nil {
+		return
+	}
+
+	accepted, rejected, blocks, bestDiff := a.stats.GetCumulativeStats()
+	err := a.db.SaveCumulativeStats(database.CumulativeStats{
+		TotalAccepted:  accepted,
+		TotalRejected:  rejected,
+		BestDifficulty: bestDiff,
+		BlocksFound:    blocks,
+	})
+	if err != nil && a.log != nil {
+		a.log.Errorf("app", "failed to save cumulative stats: %v", err)
+	}
+}
+
+// rollupShares condenses raw shares into the rollup tiers. Each tier only
+// rolls up what the tier below has completed. The most recent minutes are
+// left alone so buffered shares can still land in their bucket.
+func (a *App) rollupShares() {
+	if a.db == nil {
+		return
+	}
+
+	until := time.Now().Add(-2 * time.Minute).Unix()
+	for i, tier := range database.RollupTiers {
+		if i > 0 {
+			wm, err := a.db.RollupWatermark(database.RollupTiers[i-1].Name)
+			if err != nil {
+				if a.log != nil {
+					a.log.Errorf("app", "rollup watermark %s: %v", tier.Name, err)
+				}
+				return
+			}
+			until = wm
+		}
+		if _, err := a.db.RollupShares(i, until); err != nil {
+			if a.log != nil {
+				a.log.Errorf("app", "failed to roll up shares: %v", err)
+			}
+			return
+		}
+	}
+}
+
+// rollupHashrateHistory serves a hashrate series from the finest rollup
+// tier that still covers the window. An empty worker means all workers.
+func (a *App) rollupHashrateHistory(worker string, window time.Duration, bucket int64) []miner.HashratePoint {
+	tier := a.rollupTierFor(window)
+	if bucket < tier.BucketSec || bucket%tier.BucketSec != 0 {
+		bucket = tier.BucketSec
+	}
+	since := time.Now().Add(-window).Unix()
+	entries, err := a.db.RollupHashrateHistory(tier.Name, worker, since, bucket)
+	if err != nil {
+		if a.log != nil {
+			a.log.Errorf("app", "rollup hashrate history: %v", err)
+		}
+		return nil
+	}
+	points := make([]miner.HashratePoint, len(entries))
+	for i, e := range entries {
+		points[i] = miner.HashratePoint{
+			Timestamp: e.Timestamp,
+			Hashrate:  e.Hashrate,
+		}
+	}
+	return points
+}
+
+// rollupTierFor returns the finest rollup tier whose retention covers the
+// window, falling back to the coarsest tier.
+func (a *App) rollupTierFor(window time.Duration) database.RollupTier {
+	retention := a.rollupRetentionDays()
+	for i, tier := range database.RollupTiers {
+		days := retention[i]
+		if days == 0 || time.Duration(days)*24*time.Hour >= window {
+			return tier
+		}
+	}
+	return database.RollupTiers[len(database.RollupTiers)-1]
+}
+
+// rollupRetentionDays returns the configured retention per rollup tier,
+// in the order of database.RollupTiers (0 = forever).
+func (a *App) rollupRetentionDays() []int {
+	r := a.config.App.Retention
+	return []int{r.FiveMinDays, r.HourlyDays, r.DailyDays}
+}
+
+func (a *App) pruneOldData() {
 	if a.db == nil {
 		return
 	}
 
-	maxAge := 30 * 24 * time.Hour // 30 days
+	// Condense first so pruning never drops shares that were not rolled up.
+	a.rollupShares()
+
+	for i, days := range a.rollupRetentionDays() {
+		if days == 0 {
+			continue
+		}
+		cutoff := time.Now().Add(-time.Duration(days) * 24 * time.Hour).Unix()
+		if n, err := a.db.PruneRollups(i, cutoff); err != nil {
+			if a.log != nil {
+				a.log.Errorf("app", "failed to prune %s rollups: %v", database.RollupTiers[i].Name, err)
+			}
+		} else if n > 0 && a.log != nil {
+			a.log.Infof("app", "pruned %d old %s rollups", n, database.RollupTiers[i].Name)
+		}
+	}
+
+	telemetryAge := time.Duration(a.config.App.Telemetry.RetentionDays) * 24 * time.Hour
+	if n, err := a.db.PruneTelemetry(telemetryAge); err != nil {
+		if a.log != nil {
+			a.log.Errorf("app", "failed to prune telemetry: %v", err)
+		}
+	} else if n > 0 && a.log != nil {
+		a.log.Infof("app", "pruned %d old telemetry readings", n)
+	}
+
+	if _, err := a.db.PruneNotifications(90 * 24 * time.Hour); err != nil && a.log != nil {
+		a.log.Errorf("app", "failed to prune notification log: %v", err)
+	}
+
+	maxAge := time.Duration(a.config.App.Retention.RawDays) * 24 * time.Hour
 	if n, err := a.db.PruneShares(maxAge); err != nil {
 		if a.log != nil {
 			a.log.Errorf("app", "failed to prune shares: %v", err)
@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"govault/internal/config"
+	"govault/internal/logger"
+	"govault/internal/miner"
+)
+
+func newIdentityApp(t *testing.T, mode string, probe func(ip string) (*miner.DiscoveredMiner, error)) *App {
+	t.Helper()
+	cfg := config.Defaults()
+	cfg.App.WorkerIdentity = mode
+	log, err := logger.New(t.TempDir(), "error")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(log.Close)
+	return &App{
+		config:      cfg,
+		log:         log,
+		registry:    miner.NewRegistry(),
+		deviceMACs:  make(map[string]string),
+		probeDevice: probe,
+	}
+}
+
+// A device whose MAC is not known yet is keyed by IP without waiting for
+// it, and moved to its MAC-keyed worker once the device answers.
+func TestResolveWorkerIDNameMAC(t *testing.T) {
+	var probes atomic.Int32
+	a := newIdentityApp(t, "name_mac", func(ip string) (*miner.DiscoveredMiner, error) {
+		probes.Add(1)
+		return &miner.DiscoveredMiner{IP: ip, MACAddress: "aa:bb:cc:dd:ee:ff"}, nil
+	})
+
+	id, mac := a.resolveWorkerID("rig1", "192.168.1.20")
+	if id != "rig1@192.168.1.20" || mac != "" {
+		t.Fatalf("first session: got (%q, %q), want IP-keyed ID", id, mac)
+	}
+	if n := probes.Load(); n != 0 {
+		t.Fatalf("resolve probed the device %d times", n)
+	}
+	a.registry.Register(miner.MinerInfo{ID: "s1", WorkerName: "rig1", WorkerID: id})
+	a.rekeyWorker("s1", "rig1", "192.168.1.20")
+	if m := a.registry.Get("s1"); m.WorkerID != "rig1@aa:bb:cc:dd:ee:ff" {
+		t.Errorf("session keyed %q after the MAC was learned", m.WorkerID)
+	}
+
+	id, mac = a.resolveWorkerID("rig1", "192.168.1.20")
+	if id != "rig1@aa:bb:cc:dd:ee:ff" || mac != "aa:bb:cc:dd:ee:ff" {
+		t.Errorf("next session: got (%q, %q)", id, mac)
+	}
+	if n := probes.Load(); n != 1 {
+		t.Errorf("device probed %d times, want 1", n)
+	}
+}
+
+func TestResolveWorkerIDNameMACUnknownDevice(t *testing.T) {
+	a := newIdentityApp(t, "name_mac", func(ip string) (*miner.DiscoveredMiner, error) {
+		return nil, errors.New("no API")
+	})
+	a.registry.Register(miner.MinerInfo{ID: "s1", WorkerName: "rig1", WorkerID: "rig1@192.168.1.20"})
+	a.rekeyWorker("s1", "rig1", "192.168.1.20")
+	if m := a.registry.Get("s1"); m.WorkerID != "rig1@192.168.1.20" {
+		t.Errorf("session re-keyed to %q without a MAC", m.WorkerID)
+	}
+	id, mac := a.resolveWorkerID("rig1", "192.168.1.20")
+	if id != "rig1@192.168.1.20" || mac != "" {
+		t.Fatalf("got (%q, %q), want IP-keyed ID", id, mac)
+	}
+}
+
+func TestResolveWorkerIDModes(t *testing.T) {
+	probe := func(ip string) (*miner.DiscoveredMiner, error) {
+		t.Fatalf("unexpected probe of %s", ip)
+		return nil, nil
+	}
+	cases := []struct{ mode, want string }{
+		{"name", "rig1"},
+		{"name_ip", "rig1@192.168.1.20"},
+	}
+	for _, c := range cases {
+		a := newIdentityApp(t, c.mode, probe)
+		if id, _ := a.resolveWorkerID("rig1", "192.168.1.20"); id != c.want {
+			t.Errorf("%s: got %q, want %q", c.mode, id, c.want)
+		}
+	}
+}
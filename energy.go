@@ -0,0 +1,334 @@
+package main
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"govault/internal/database"
+	"govault/internal/miner"
+)
+
+// EnergyReportRow is energy, cost and work for one report period.
+type EnergyReportRow struct {
+	Label        string  `json:"label"` // "2026-10-18", "2026-10" or "lifetime"
+	Start        int64   `json:"start"`
+	KWh          float64 `json:"kwh"`
+	Cost         float64 `json:"cost"`
+	THDays       float64 `json:"thDays"`       // work done, in TH/s sustained for a day
+	AvgHashrate  float64 `json:"avgHashrate"`  // H/s over the period
+	CostPerTHDay float64 `json:"costPerThDay"` // 0 when no work was done
+}
+
+// EnergyReport is a cost report for the whole fleet or one group.
+type EnergyReport struct {
+	Period   string            `json:"period"` // "daily", "monthly" or "lifetime"
+	GroupID  int64             `json:"groupId"`
+	Currency string            `json:"currency"`
+	Rows     []EnergyReportRow `json:"rows"`
+	Total    EnergyReportRow   `json:"total"`
+}
+
+// EnergyShare is the energy and cost used by one worker or group.
+type EnergyShare struct {
+	ID   string  `json:"id"`
+	Name string  `json:"name"`
+	KWh  float64 `json:"kwh"`
+	Cost float64 `json:"cost"`
+}
+
+// EnergyBreakdown splits energy use by worker and group.
+type EnergyBreakdown struct {
+	Period     string        `json:"period"`
+	Currency   string        `json:"currency"`
+	Workers    []EnergyShare `json:"workers"`
+	Groups     []EnergyShare `json:"groups"`
+	Unassigned EnergyShare   `json:"unassigned"` // devices with no connected worker
+	Total      EnergyShare   `json:"total"`
+}
+
+// energySample is the last power reading of a device.
+type energySample struct {
+	timestamp int64
+	watts     float64
+}
+
+// GetEnergyReport returns daily (last 30 days), monthly (last 12 months)
+// or lifetime energy, cost and work, for the whole fleet (groupID 0) or
+// one group.
+func (a *App) GetEnergyReport(period string, groupID int64) EnergyReport {
+	report := EnergyReport{
+		Period:   period,
+		GroupID:  groupID,
+		Currency: a.config.App.Tariff.Currency,
+		Rows:     []EnergyReportRow{},
+	}
+	if a.db == nil {
+		return report
+	}
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	var since time.Time
+	var label func(t time.Time) string
+	switch period {
+	case "monthly":
+		since = time.Date(now.Year(), now.Month()-11, 1, 0, 0, 0, 0, time.Local)
+		label = func(t time.Time) string { return t.Format("2006-01") }
+	case "lifetime":
+		first, err := a.db.FirstEnergyBucket()
+		if err != nil || first == 0 {
+			return report
+		}
+		since = time.Unix(first, 0)
+		label = func(time.Time) string { return "lifetime" }
+	default:
+		report.Period = "daily"
+		since = today.AddDate(0, 0, -29)
+		label = func(t time.Time) string { return t.Format("2006-01-02") }
+	}
+
+	members, all := a.groupMemberSet(groupID)
+
+	rows := make(map[string]*EnergyReportRow)
+	var order []string
+	row := func(ts int64) *EnergyReportRow {
+		t := time.Unix(ts, 0).Local()
+		key := label(t)
+		r, ok := rows[key]
+		if !ok {
+			r = &EnergyReportRow{Label: key, Start: ts}
+			rows[key] = r
+			order = append(order, key)
+		}
+		if ts < r.Start {
+			r.Start = ts
+		}
+		return r
+	}
+
+	usage, err := a.db.EnergyUsage(since.Unix(), now.Unix()+1)
+	if err != nil {
+		a.log.Errorf("app", "energy usage: %v", err)
+		return report
+	}
+	for _, e := range usage {
+		if !all && !members[e.Worker] {
+			continue
+		}
+		r := row(e.Bucket)
+		r.KWh += e.WattHour / 1000
+		r.Cost += e.Cost
+	}
+
+	tier := a.rollupTierFor(now.Sub(since))
+	work, err := a.db.Rollups(tier.Name, "", since.Unix())
+	if err != nil {
+		a.log.Errorf("app", "energy report work: %v", err)
+	}
+	hashes := make(map[string]float64)
+	for _, w := range work {
+		if !all && !members[w.Worker] {
+			continue
+		}
+		r := row(w.Bucket)
+		hashes[r.Label] += w.Work * math.Pow(2, 32)
+	}
+
+	sort.Slice(order, func(i, j int) bool { return rows[order[i]].Start < rows[order[j]].Start })
+	total := EnergyReportRow{Label: "total", Start: since.Unix()}
+	var totalHashes float64
+	for _, key := range order {
+		r := rows[key]
+		end := now
+		switch report.Period {
+		case "daily":
+			end = minTime(now, time.Unix(r.Start, 0).Local().AddDate(0, 0, 1))
+		case "monthly":
+			start := time.Unix(r.Start, 0).Local()
+			end = minTime(now, time.Date(start.Year(), start.Month()+1, 1, 0, 0, 0, 0, time.Local))
+		}
+		finishEnergyRow(r, hashes[key], end.Sub(time.Unix(r.Start, 0)).Seconds())
+		report.Rows = append(report.Rows, *r)
+
+		total.KWh += r.KWh
+		total.Cost += r.Cost
+		totalHashes += hashes[key]
+	}
+	finishEnergyRow(&total, totalHashes, now.Sub(since).Seconds())
+	report.Total = total
+	return report
+}
+
+// GetEnergyBreakdown returns energy and cost per worker and per group for
+// a period ("24h", "7d", "30d" or "lifetime").
+func (a *App) GetEnergyBreakdown(period string) EnergyBreakdown {
+	result := EnergyBreakdown{
+		Period:     period,
+		Currency:   a.config.App.Tariff.Currency,
+		Workers:    []EnergyShare{},
+		Groups:     []EnergyShare{},
+		Unassigned: EnergyShare{ID: "", Name: "unassigned"},
+		Total:      EnergyShare{ID: "", Name: "total"},
+	}
+	if a.db == nil {
+		return result
+	}
+
+	var since int64
+	switch period {
+	case "lifetime":
+	case "7d":
+		since = time.Now().Add(-7 * 24 * time.Hour).Unix()
+	case "30d":
+		since = time.Now().Add(-30 * 24 * time.Hour).Unix()
+	default:
+		result.Period = "24h"
+		since = time.Now().Add(-24 * time.Hour).Unix()
+	}
+	usage, err := a.db.EnergyUsage(since, time.Now().Unix()+1)
+	if err != nil {
+		a.log.Errorf("app", "energy usage: %v", err)
+		return result
+	}
+
+	byWorker := make(map[string]*EnergyShare)
+	for _, e := range usage {
+		kwh := e.WattHour / 1000
+		result.Total.KWh += kwh
+		result.Total.Cost += e.Cost
+		if e.Worker == "" {
+			result.Unassigned.KWh += kwh
+			result.Unassigned.Cost += e.Cost
+			continue
+		}
+		s, ok := byWorker[e.Worker]
+		if !ok {
+			s = &EnergyShare{ID: e.Worker, Name: e.Worker}
+			byWorker[e.Worker] = s
+		}
+		s.KWh += kwh
+		s.Cost += e.Cost
+	}
+	for _, s := range byWorker {
+		result.Workers = append(result.Workers, *s)
+	}
+	sort.Slice(result.Workers, func(i, j int) bool { return result.Workers[i].KWh > result.Workers[j].KWh })
+
+	for _, g := range a.GetGroups() {
+		share := EnergyShare{ID: strconv.FormatInt(g.ID, 10), Name: g.Name}
+		for _, m := range g.Members {
+			if s, ok := byWorker[m]; ok {
+				share.KWh += s.KWh
+				share.Cost += s.Cost
+			}
+		}
+		result.Groups = append(result.Groups, share)
+	}
+	return result
+}
+
+// meterEnergy integrates device power readings into hourly kWh and cost.
+// Each device's energy is split evenly between the workers connected from
+// it. Only called from the telemetry loop.
+func (a *App) meterEnergy(readings []miner.DeviceTelemetry, workersByHost map[string][]string) {
+	if a.db == nil {
+		return
+	}
+	// Gaps longer than this (device offline, app asleep) are not metered
+	maxGap := int64(3 * a.config.App.Telemetry.IntervalSec)
+
+	var entries []database.EnergyEntry
+	for _, t := range readings {
+		last, ok := a.energyLast[t.IP]
+		a.energyLast[t.IP] = energySample{timestamp: t.Timestamp, watts: t.Power}
+		dt := t.Timestamp - last.timestamp
+		if !ok || dt <= 0 || dt > maxGap || t.Power <= 0 {
+			continue
+		}
+
+		wh := (last.watts + t.Power) / 2 * float64(dt) / 3600
+		mid := time.Unix(t.Timestamp-dt/2, 0)
+		cost := wh / 1000 * a.config.App.RateAt(mid)
+		bucket := t.Timestamp / 3600 * 3600
+
+		workers := workersByHost[t.IP]
+		if len(workers) == 0 {
+			workers = []string{""}
+		}
+		n := float64(len(workers))
+		for _, w := range workers {
+			entries = append(entries, database.EnergyEntry{
+				Bucket:   bucket,
+				IP:       t.IP,
+				Worker:   w,
+				WattHour: wh / n,
+				Cost:     cost / n,
+			})
+		}
+	}
+	if err := a.db.AddEnergy(entries); err != nil {
+		a.log.Errorf("app", "failed to store energy usage: %v", err)
+	}
+}
+
+// dailyEnergyCost prices a constant load over the next 24 hours at the
+// configured tariff.
+func (a *App) dailyEnergyCost(watts float64) float64 {
+	if watts <= 0 {
+		return 0
+	}
+	const step = 15 * time.Minute
+	now := time.Now()
+	var cost float64
+	for t := time.Duration(0); t < 24*time.Hour; t += step {
+		cost += watts / 1000 * step.Hours() * a.config.App.RateAt(now.Add(t))
+	}
+	return cost
+}
+
+// expectedBlockCost prices a constant load until the expected time to
+// find a block, at the week-averaged tariff.
+func (a *App) expectedBlockCost(watts, estTimeToBlock float64) float64 {
+	if watts <= 0 || estTimeToBlock <= 0 || math.IsInf(estTimeToBlock, 0) {
+		return 0
+	}
+	return watts / 1000 * (estTimeToBlock / 3600) * a.config.App.AverageRate(time.Now())
+}
+
+// groupMemberSet returns the worker IDs in a group; all is true for
+// groupID 0 (everything, including devices with no worker).
+func (a *App) groupMemberSet(groupID int64) (members map[string]bool, all bool) {
+	if groupID == 0 {
+		return nil, true
+	}
+	members = make(map[string]bool)
+	for _, g := range a.GetGroups() {
+		if g.ID == groupID {
+			for _, m := range g.Members {
+				members[m] = true
+			}
+		}
+	}
+	return members, false
+}
+
+// finishEnergyRow fills in work-derived fields from the hashes done over
+// a period of the given length.
+func finishEnergyRow(r *EnergyReportRow, hashes, seconds float64) {
+	r.THDays = hashes / 1e12 / 86400
+	if seconds > 0 {
+		r.AvgHashrate = hashes / seconds
+	}
+	if r.THDays > 0 {
+		r.CostPerTHDay = r.Cost / r.THDays
+	}
+}
+
+func minTime(a, b time.Time) time.Time {
+	if a.Before(b) {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"math"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"govault/internal/config"
+	"govault/internal/database"
+	"govault/internal/logger"
+	"govault/internal/miner"
+)
+
+func newEnergyApp(t *testing.T) *App {
+	t.Helper()
+	cfg := config.Defaults()
+	cfg.App.ElectricityCost = 0.20
+	cfg.App.Tariff.Periods = []config.TariffPeriod{
+		{Name: "night", Days: "all", Start: "22:00", End: "06:00", Rate: 0.10},
+	}
+	log, err := logger.New(t.TempDir(), "error")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(log.Close)
+	db, err := database.Open(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return &App{config: cfg, log: log, db: db, energyLast: make(map[string]energySample)}
+}
+
+func TestMeterEnergy(t *testing.T) {
+	a := newEnergyApp(t)
+	// Readings two minutes apart straddle 22:00, so the interval is priced
+	// at the night rate by its midpoint.
+	start := time.Date(2026, time.October, 14, 21, 59, 0, 0, time.Local).Unix()
+	reading := func(offset int64, ip string, watts float64) miner.DeviceTelemetry {
+		return miner.DeviceTelemetry{Timestamp: start + offset, IP: ip, Power: watts}
+	}
+	hosts := map[string][]string{"10.0.0.2": {"rig1", "rig2"}}
+
+	a.meterEnergy([]miner.DeviceTelemetry{reading(0, "10.0.0.2", 10), reading(0, "10.0.0.3", 20)}, hosts)
+	a.meterEnergy([]miner.DeviceTelemetry{reading(120, "10.0.0.2", 20), reading(120, "10.0.0.3", 0)}, hosts)
+	a.meterEnergy([]miner.DeviceTelemetry{reading(1000, "10.0.0.2", 20)}, hosts) // gap: not metered
+
+	usage, err := a.db.EnergyUsage(0, start+3600)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// (10 W + 20 W) / 2 over 120 s = 0.5 Wh, split between two workers;
+	// the device reporting 0 W is not metered.
+	if len(usage) != 2 {
+		t.Fatalf("usage %+v, want one row per worker", usage)
+	}
+	for _, e := range usage {
+		if e.IP != "10.0.0.2" || math.Abs(e.WattHour-0.25) > 1e-9 || math.Abs(e.Cost-0.25/1000*0.10) > 1e-12 {
+			t.Errorf("row %+v, want 0.25 Wh at the night rate", e)
+		}
+		if e.Bucket != (start+120)/3600*3600 {
+			t.Errorf("bucket %d, want the hour of the reading", e.Bucket)
+		}
+	}
+
+	// A device with no worker is metered as unassigned.
+	a.meterEnergy([]miner.DeviceTelemetry{reading(1060, "10.0.0.2", 20)}, nil)
+	usage, _ = a.db.EnergyUsage(0, start+7200)
+	var unassigned float64
+	for _, e := range usage {
+		if e.Worker == "" {
+			unassigned += e.WattHour
+		}
+	}
+	if math.Abs(unassigned-20.0/60) > 1e-9 {
+		t.Errorf("unassigned %v Wh, want 20 W for a minute", unassigned)
+	}
+}
+
+func TestDailyEnergyCost(t *testing.T) {
+	a := newEnergyApp(t)
+	// 8 hours at 0.10 and 16 at 0.20, for 1 kW.
+	if got := a.dailyEnergyCost(1000); math.Abs(got-4.0) > 1e-9 {
+		t.Errorf("daily cost %v, want 4.00", got)
+	}
+	if a.dailyEnergyCost(0) != 0 {
+		t.Error("no load should cost nothing")
+	}
+	if a.expectedBlockCost(1000, math.Inf(1)) != 0 {
+		t.Error("a block that is never expected should have no cost")
+	}
+}
@@ -1,9 +1,17 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
-import {config} from '../models';
 import {miner} from '../models';
 import {main} from '../models';
+import {database} from '../models';
+import {config} from '../models';
 import {logger} from '../models';
+import {stratum} from '../models';
+
+export function ApplyDeviceSettings(arg1:Array<string>,arg2:miner.DeviceSettings,arg3:boolean):Promise<Array<main.DeviceChangeResult>>;
+
+export function BackupNow():Promise<database.SnapshotInfo>;
+
+export function CancelScan():Promise<void>;
 
 export function ClearRejectedShares():Promise<number>;
 
@@ -11,18 +19,52 @@ export function ConfigureMiner(arg1:string):Promise<void>;
 
 export function ConnectNode():Promise<Record<string, any>>;
 
+export function DeleteGroup(arg1:number):Promise<void>;
+
 export function DetectNode(arg1:string):Promise<Record<string, any>>;
 
+export function ExportBundle():Promise<string>;
+
+export function ExportData(arg1:string,arg2:string,arg3:number,arg4:number,arg5:string):Promise<string>;
+
+export function ForgetDiscoveredDevice(arg1:string):Promise<void>;
+
 export function GetCoinList():Promise<Array<Record<string, any>>>;
 
 export function GetConfig():Promise<config.Config>;
 
+export function GetCurrentRound():Promise<miner.RoundStats>;
+
 export function GetDashboardStats():Promise<miner.DashboardStats>;
 
 export function GetDatabaseInfo():Promise<Record<string, any>>;
 
+export function GetDeviceAlerts(arg1:number):Promise<Array<database.DeviceAlertEntry>>;
+
+export function GetDeviceChanges(arg1:string,arg2:number):Promise<Array<database.DeviceChangeEntry>>;
+
+export function GetDeviceLimits():Promise<Array<miner.DeviceLimits>>;
+
+export function GetDeviceSettings(arg1:string):Promise<Record<string, any>>;
+
+export function GetDeviceTelemetry():Promise<Array<miner.DeviceTelemetry>>;
+
+export function GetDeviceTelemetryHistory(arg1:string,arg2:string):Promise<Array<database.TelemetryEntry>>;
+
+export function GetDiscoveredDevices():Promise<Array<database.DiscoveredDeviceEntry>>;
+
+export function GetEnergyBreakdown(arg1:string):Promise<main.EnergyBreakdown>;
+
+export function GetEnergyReport(arg1:string,arg2:number):Promise<main.EnergyReport>;
+
 export function GetFleetOverview():Promise<main.FleetOverview>;
 
+export function GetGroupHashrateHistory(arg1:number,arg2:string):Promise<Array<miner.HashratePoint>>;
+
+export function GetGroupStats():Promise<Array<main.GroupStats>>;
+
+export function GetGroups():Promise<Array<main.GroupView>>;
+
 export function GetHashrateHistory(arg1:string):Promise<Array<miner.HashratePoint>>;
 
 export function GetMinerHashrateHistory(arg1:string):Promise<Array<miner.HashratePoint>>;
@@ -33,22 +75,74 @@ export function GetMiningMode():Promise<string>;
 
 export function GetNodeStatus():Promise<Record<string, any>>;
 
+export function GetNotificationEvents():Promise<Array<string>>;
+
+export function GetNotificationLog(arg1:number):Promise<Array<database.NotificationEntry>>;
+
 export function GetProxyDiagnostics():Promise<Record<string, any>>;
 
 export function GetRecentLogs(arg1:number):Promise<Array<logger.LogEntry>>;
 
+export function GetRejectAnalytics(arg1:string,arg2:string):Promise<Array<database.RejectBucket>>;
+
+export function GetRoster():Promise<Array<miner.RosterStatus>>;
+
+export function GetRosterEvents(arg1:string,arg2:number):Promise<Array<database.RosterEventEntry>>;
+
+export function GetRoundHistory(arg1:number):Promise<Array<database.RoundEntry>>;
+
+export function GetRoundWorkers(arg1:number):Promise<Array<database.RoundWorkerEntry>>;
+
+export function GetScheduleActions(arg1:string,arg2:number):Promise<Array<database.ScheduleActionEntry>>;
+
+export function GetScheduleStatus():Promise<Array<main.ScheduleStatus>>;
+
+export function GetStratumBans():Promise<Array<stratum.Ban>>;
+
 export function GetStratumURL():Promise<string>;
 
+export function GetTags(arg1:string):Promise<Array<database.TagEntry>>;
+
 export function GetUpstreamStatus():Promise<Record<string, any>>;
 
+export function GetWorker(arg1:string):Promise<main.WorkerView>;
+
+export function GetWorkerHashrateHistory(arg1:string,arg2:string):Promise<Array<miner.HashratePoint>>;
+
+export function GetWorkers():Promise<Array<main.WorkerView>>;
+
+export function ImportBundle():Promise<string>;
+
 export function IsStratumRunning():Promise<boolean>;
 
+export function LiftStratumBan(arg1:string):Promise<void>;
+
 export function ReconnectMiners():Promise<Record<string, any>>;
 
+export function RemoveRosterWorker(arg1:string):Promise<void>;
+
+export function RestartDevices(arg1:Array<string>):Promise<Array<main.DeviceChangeResult>>;
+
+export function RestoreBackup(arg1:string):Promise<void>;
+
+export function RollbackDeviceChanges(arg1:Array<number>,arg2:boolean):Promise<Array<main.DeviceChangeResult>>;
+
+export function SaveGroup(arg1:miner.Group):Promise<miner.Group>;
+
 export function ScanForMiners():Promise<Array<miner.DiscoveredMiner>>;
 
+export function SendTestNotification(arg1:string):Promise<void>;
+
 export function SetLogLevel(arg1:string):Promise<void>;
 
+export function SetRosterWorker(arg1:string,arg2:string,arg3:number):Promise<void>;
+
+export function SetScheduleOverride(arg1:string,arg2:string,arg3:number):Promise<void>;
+
+export function SetTags(arg1:string,arg2:string,arg3:Array<string>):Promise<void>;
+
+export function SetWorkerPassword(arg1:string,arg2:string):Promise<void>;
+
 export function StartStratum():Promise<void>;
 
 export function StopStratum():Promise<void>;
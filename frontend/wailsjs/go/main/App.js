@@ -2,6 +2,18 @@
 // Cynhyrchwyd y ffeil hon yn awtomatig. PEIDIWCH Â MODIWL
 // This file is automatically generated. DO NOT EDIT
 
+export function ApplyDeviceSettings(arg1, arg2, arg3) {
+  return window['go']['main']['App']['ApplyDeviceSettings'](arg1, arg2, arg3);
+}
+
+export function BackupNow() {
+  return window['go']['main']['App']['BackupNow']();
+}
+
+export function CancelScan() {
+  return window['go']['main']['App']['CancelScan']();
+}
+
 export function ClearRejectedShares() {
   return window['go']['main']['App']['ClearRejectedShares']();
 }
@@ -14,10 +26,26 @@ export function ConnectNode() {
   return window['go']['main']['App']['ConnectNode']();
 }
 
+export function DeleteGroup(arg1) {
+  return window['go']['main']['App']['DeleteGroup'](arg1);
+}
+
 export function DetectNode(arg1) {
   return window['go']['main']['App']['DetectNode'](arg1);
 }
 
+export function ExportBundle() {
+  return window['go']['main']['App']['ExportBundle']();
+}
+
+export function ExportData(arg1, arg2, arg3, arg4, arg5) {
+  return window['go']['main']['App']['ExportData'](arg1, arg2, arg3, arg4, arg5);
+}
+
+export function ForgetDiscoveredDevice(arg1) {
+  return window['go']['main']['App']['ForgetDiscoveredDevice'](arg1);
+}
+
 export function GetCoinList() {
   return window['go']['main']['App']['GetCoinList']();
 }
@@ -26,6 +54,10 @@ export function GetConfig() {
   return window['go']['main']['App']['GetConfig']();
 }
 
+export function GetCurrentRound() {
+  return window['go']['main']['App']['GetCurrentRound']();
+}
+
 export function GetDashboardStats() {
   return window['go']['main']['App']['GetDashboardStats']();
 }
@@ -34,10 +66,58 @@ export function GetDatabaseInfo() {
   return window['go']['main']['App']['GetDatabaseInfo']();
 }
 
+export function GetDeviceAlerts(arg1) {
+  return window['go']['main']['App']['GetDeviceAlerts'](arg1);
+}
+
+export function GetDeviceChanges(arg1, arg2) {
+  return window['go']['main']['App']['GetDeviceChanges'](arg1, arg2);
+}
+
+export function GetDeviceLimits() {
+  return window['go']['main']['App']['GetDeviceLimits']();
+}
+
+export function GetDeviceSettings(arg1) {
+  return window['go']['main']['App']['GetDeviceSettings'](arg1);
+}
+
+export function GetDeviceTelemetry() {
+  return window['go']['main']['App']['GetDeviceTelemetry']();
+}
+
+export function GetDeviceTelemetryHistory(arg1, arg2) {
+  return window['go']['main']['App']['GetDeviceTelemetryHistory'](arg1, arg2);
+}
+
+export function GetDiscoveredDevices() {
+  return window['go']['main']['App']['GetDiscoveredDevices']();
+}
+
+export function GetEnergyBreakdown(arg1) {
+  return window['go']['main']['App']['GetEnergyBreakdown'](arg1);
+}
+
+export function GetEnergyReport(arg1, arg2) {
+  return window['go']['main']['App']['GetEnergyReport'](arg1, arg2);
+}
+
 export function GetFleetOverview() {
   return window['go']['main']['App']['GetFleetOverview']();
 }
 
+export function GetGroupHashrateHistory(arg1, arg2) {
+  return window['go']['main']['App']['GetGroupHashrateHistory'](arg1, arg2);
+}
+
+export function GetGroupStats() {
+  return window['go']['main']['App']['GetGroupStats']();
+}
+
+export function GetGroups() {
+  return window['go']['main']['App']['GetGroups']();
+}
+
 export function GetHashrateHistory(arg1) {
   return window['go']['main']['App']['GetHashrateHistory'](arg1);
 }
@@ -58,6 +138,14 @@ export function GetNodeStatus() {
   return window['go']['main']['App']['GetNodeStatus']();
 }
 
+export function GetNotificationEvents() {
+  return window['go']['main']['App']['GetNotificationEvents']();
+}
+
+export function GetNotificationLog(arg1) {
+  return window['go']['main']['App']['GetNotificationLog'](arg1);
+}
+
 export function GetProxyDiagnostics() {
   return window['go']['main']['App']['GetProxyDiagnostics']();
 }
@@ -66,30 +154,126 @@ export function GetRecentLogs(arg1) {
   return window['go']['main']['App']['GetRecentLogs'](arg1);
 }
 
+export function GetRejectAnalytics(arg1, arg2) {
+  return window['go']['main']['App']['GetRejectAnalytics'](arg1, arg2);
+}
+
+export function GetRoster() {
+  return window['go']['main']['App']['GetRoster']();
+}
+
+export function GetRosterEvents(arg1, arg2) {
+  return window['go']['main']['App']['GetRosterEvents'](arg1, arg2);
+}
+
+export function GetRoundHistory(arg1) {
+  return window['go']['main']['App']['GetRoundHistory'](arg1);
+}
+
+export function GetRoundWorkers(arg1) {
+  return window['go']['main']['App']['GetRoundWorkers'](arg1);
+}
+
+export function GetScheduleActions(arg1, arg2) {
+  return window['go']['main']['App']['GetScheduleActions'](arg1, arg2);
+}
+
+export function GetScheduleStatus() {
+  return window['go']['main']['App']['GetScheduleStatus']();
+}
+
+export function GetStratumBans() {
+  return window['go']['main']['App']['GetStratumBans']();
+}
+
 export function GetStratumURL() {
   return window['go']['main']['App']['GetStratumURL']();
 }
 
+export function GetTags(arg1) {
+  return window['go']['main']['App']['GetTags'](arg1);
+}
+
 export function GetUpstreamStatus() {
   return window['go']['main']['App']['GetUpstreamStatus']();
 }
 
+export function GetWorker(arg1) {
+  return window['go']['main']['App']['GetWorker'](arg1);
+}
+
+export function GetWorkerHashrateHistory(arg1, arg2) {
+  return window['go']['main']['App']['GetWorkerHashrateHistory'](arg1, arg2);
+}
+
+export function GetWorkers() {
+  return window['go']['main']['App']['GetWorkers']();
+}
+
+export function ImportBundle() {
+  return window['go']['main']['App']['ImportBundle']();
+}
+
 export function IsStratumRunning() {
   return window['go']['main']['App']['IsStratumRunning']();
 }
 
+export function LiftStratumBan(arg1) {
+  return window['go']['main']['App']['LiftStratumBan'](arg1);
+}
+
 export function ReconnectMiners() {
   return window['go']['main']['App']['ReconnectMiners']();
 }
 
+export function RemoveRosterWorker(arg1) {
+  return window['go']['main']['App']['RemoveRosterWorker'](arg1);
+}
+
+export function RestartDevices(arg1) {
+  return window['go']['main']['App']['RestartDevices'](arg1);
+}
+
+export function RestoreBackup(arg1) {
+  return window['go']['main']['App']['RestoreBackup'](arg1);
+}
+
+export function RollbackDeviceChanges(arg1, arg2) {
+  return window['go']['main']['App']['RollbackDeviceChanges'](arg1, arg2);
+}
+
+export function SaveGroup(arg1) {
+  return window['go']['main']['App']['SaveGroup'](arg1);
+}
+
 export function ScanForMiners() {
   return window['go']['main']['App']['ScanForMiners']();
 }
 
+export function SendTestNotification(arg1) {
+  return window['go']['main']['App']['SendTestNotification'](arg1);
+}
+
 export function SetLogLevel(arg1) {
   return window['go']['main']['App']['SetLogLevel'](arg1);
 }
 
+export function SetRosterWorker(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SetRosterWorker'](arg1, arg2, arg3);
+}
+
+export function SetScheduleOverride(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SetScheduleOverride'](arg1, arg2, arg3);
+}
+
+export function SetTags(arg1, arg2, arg3) {
+  return window['go']['main']['App']['SetTags'](arg1, arg2, arg3);
+}
+
+export function SetWorkerPassword(arg1, arg2) {
+  return window['go']['main']['App']['SetWorkerPassword'](arg1, arg2);
+}
+
 export function StartStratum() {
   return window['go']['main']['App']['StartStratum']();
 }
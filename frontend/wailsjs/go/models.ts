@@ -1,9 +1,232 @@
 export namespace config {
 	
+	export class ScheduleRule {
+	    id: string;
+	    name: string;
+	    enabled: boolean;
+	    groupId: number;
+	    days: string;
+	    start: string;
+	    end: string;
+	    tariffPeriod: string;
+	    minRate: number;
+	    action: string;
+	    frequency: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScheduleRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.enabled = source["enabled"];
+	        this.groupId = source["groupId"];
+	        this.days = source["days"];
+	        this.start = source["start"];
+	        this.end = source["end"];
+	        this.tariffPeriod = source["tariffPeriod"];
+	        this.minRate = source["minRate"];
+	        this.action = source["action"];
+	        this.frequency = source["frequency"];
+	    }
+	}
+	export class SchedulerConfig {
+	    enabled: boolean;
+	    rules: ScheduleRule[];
+	
+	    static createFrom(source: any = {}) {
+	        return new SchedulerConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.rules = this.convertValues(source["rules"], ScheduleRule);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class TariffPeriod {
+	    name: string;
+	    days: string;
+	    start: string;
+	    end: string;
+	    rate: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TariffPeriod(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.days = source["days"];
+	        this.start = source["start"];
+	        this.end = source["end"];
+	        this.rate = source["rate"];
+	    }
+	}
+	export class TariffConfig {
+	    currency: string;
+	    periods: TariffPeriod[];
+	
+	    static createFrom(source: any = {}) {
+	        return new TariffConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.currency = source["currency"];
+	        this.periods = this.convertValues(source["periods"], TariffPeriod);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class DiscoveryConfig {
+	    targets: string[];
+	    concurrency: number;
+	    timeoutMs: number;
+	    budgetSec: number;
+	    maxHosts: number;
+	    mdns: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new DiscoveryConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.targets = source["targets"];
+	        this.concurrency = source["concurrency"];
+	        this.timeoutMs = source["timeoutMs"];
+	        this.budgetSec = source["budgetSec"];
+	        this.maxHosts = source["maxHosts"];
+	        this.mdns = source["mdns"];
+	    }
+	}
+	export class TelemetryConfig {
+	    intervalSec: number;
+	    retentionDays: number;
+	    maxTemp: number;
+	    maxVrTemp: number;
+	    minFreeHeap: number;
+	    maxHashrateMismatchPct: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TelemetryConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.intervalSec = source["intervalSec"];
+	        this.retentionDays = source["retentionDays"];
+	        this.maxTemp = source["maxTemp"];
+	        this.maxVrTemp = source["maxVrTemp"];
+	        this.minFreeHeap = source["minFreeHeap"];
+	        this.maxHashrateMismatchPct = source["maxHashrateMismatchPct"];
+	    }
+	}
+	export class RosterConfig {
+	    graceMinutes: number;
+	    underperformPct: number;
+	    underperformMinutes: number;
+	    rejectSpikePct: number;
+	    rejectWindowMinutes: number;
+	    minShares: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RosterConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.graceMinutes = source["graceMinutes"];
+	        this.underperformPct = source["underperformPct"];
+	        this.underperformMinutes = source["underperformMinutes"];
+	        this.rejectSpikePct = source["rejectSpikePct"];
+	        this.rejectWindowMinutes = source["rejectWindowMinutes"];
+	        this.minShares = source["minShares"];
+	    }
+	}
+	export class BackupConfig {
+	    intervalHours: number;
+	    keep: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BackupConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.intervalHours = source["intervalHours"];
+	        this.keep = source["keep"];
+	    }
+	}
+	export class RetentionConfig {
+	    rawDays: number;
+	    fiveMinDays: number;
+	    hourlyDays: number;
+	    dailyDays: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RetentionConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.rawDays = source["rawDays"];
+	        this.fiveMinDays = source["fiveMinDays"];
+	        this.hourlyDays = source["hourlyDays"];
+	        this.dailyDays = source["dailyDays"];
+	    }
+	}
 	export class AppConfig {
 	    theme: string;
 	    logLevel: string;
 	    electricityCost: number;
+	    roundEpochHours: number;
+	    workerIdentity: string;
+	    retention: RetentionConfig;
+	    backup: BackupConfig;
+	    roster: RosterConfig;
+	    telemetry: TelemetryConfig;
+	    discovery: DiscoveryConfig;
+	    tariff: TariffConfig;
+	    scheduler: SchedulerConfig;
 	
 	    static createFrom(source: any = {}) {
 	        return new AppConfig(source);
@@ -14,7 +237,218 @@ export namespace config {
 	        this.theme = source["theme"];
 	        this.logLevel = source["logLevel"];
 	        this.electricityCost = source["electricityCost"];
+	        this.roundEpochHours = source["roundEpochHours"];
+	        this.workerIdentity = source["workerIdentity"];
+	        this.retention = this.convertValues(source["retention"], RetentionConfig);
+	        this.backup = this.convertValues(source["backup"], BackupConfig);
+	        this.roster = this.convertValues(source["roster"], RosterConfig);
+	        this.telemetry = this.convertValues(source["telemetry"], TelemetryConfig);
+	        this.discovery = this.convertValues(source["discovery"], DiscoveryConfig);
+	        this.tariff = this.convertValues(source["tariff"], TariffConfig);
+	        this.scheduler = this.convertValues(source["scheduler"], SchedulerConfig);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class AuthRule {
+	    pattern: string;
+	    passwordHash: string;
+	    cidrs: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new AuthRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.pattern = source["pattern"];
+	        this.passwordHash = source["passwordHash"];
+	        this.cidrs = source["cidrs"];
+	    }
+	}
+	export class AuthConfig {
+	    mode: string;
+	    rules: AuthRule[];
+	
+	    static createFrom(source: any = {}) {
+	        return new AuthConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.mode = source["mode"];
+	        this.rules = this.convertValues(source["rules"], AuthRule);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	export class BanConfig {
+	    enabled: boolean;
+	    threshold: number;
+	    durationMin: number;
+	    decayPerMin: number;
+	    malformedPoints: number;
+	    invalidSharePoints: number;
+	    allowList: string[];
+	    authFailPoints: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new BanConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.threshold = source["threshold"];
+	        this.durationMin = source["durationMin"];
+	        this.decayPerMin = source["decayPerMin"];
+	        this.malformedPoints = source["malformedPoints"];
+	        this.invalidSharePoints = source["invalidSharePoints"];
+	        this.allowList = source["allowList"];
+	        this.authFailPoints = source["authFailPoints"];
+	    }
+	}
+	export class NotifyRule {
+	    event: string;
+	    enabled: boolean;
+	    minIntervalSec: number;
+	    ignoreQuietHours: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new NotifyRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.event = source["event"];
+	        this.enabled = source["enabled"];
+	        this.minIntervalSec = source["minIntervalSec"];
+	        this.ignoreQuietHours = source["ignoreQuietHours"];
+	    }
+	}
+	export class NotifyChannel {
+	    id: string;
+	    name: string;
+	    type: string;
+	    enabled: boolean;
+	    url: string;
+	    botToken: string;
+	    chatId: string;
+	    smtpHost: string;
+	    smtpPort: number;
+	    username: string;
+	    password: string;
+	    from: string;
+	    to: string[];
+	    rules: NotifyRule[];
+	    quietStart: string;
+	    quietEnd: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new NotifyChannel(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.type = source["type"];
+	        this.enabled = source["enabled"];
+	        this.url = source["url"];
+	        this.botToken = source["botToken"];
+	        this.chatId = source["chatId"];
+	        this.smtpHost = source["smtpHost"];
+	        this.smtpPort = source["smtpPort"];
+	        this.username = source["username"];
+	        this.password = source["password"];
+	        this.from = source["from"];
+	        this.to = source["to"];
+	        this.rules = this.convertValues(source["rules"], NotifyRule);
+	        this.quietStart = source["quietStart"];
+	        this.quietEnd = source["quietEnd"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class NotificationsConfig {
+	    channels: NotifyChannel[];
+	
+	    static createFrom(source: any = {}) {
+	        return new NotificationsConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.channels = this.convertValues(source["channels"], NotifyChannel);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class ProxyConfig {
 	    url: string;
@@ -70,10 +504,49 @@ export namespace config {
 	        this.coinbaseTag = source["coinbaseTag"];
 	    }
 	}
+	export class ProxyProtocolConfig {
+	    enabled: boolean;
+	    trusted: string[];
+	
+	    static createFrom(source: any = {}) {
+	        return new ProxyProtocolConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.enabled = source["enabled"];
+	        this.trusted = source["trusted"];
+	    }
+	}
+	export class JobRetentionConfig {
+	    maxAgeSec: number;
+	    staleGraceSec: number;
+	    maxJobs: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new JobRetentionConfig(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.maxAgeSec = source["maxAgeSec"];
+	        this.staleGraceSec = source["staleGraceSec"];
+	        this.maxJobs = source["maxJobs"];
+	    }
+	}
 	export class StratumConfig {
 	    port: number;
+	    bindAddrs: string[];
 	    maxConn: number;
 	    autoStart: boolean;
+	    maxConnPerIp: number;
+	    maxPending: number;
+	    handshakeTimeoutSec: number;
+	    resumeTtlSec: number;
+	    ban: BanConfig;
+	    auth: AuthConfig;
+	    jobs: JobRetentionConfig;
+	    proxyProtocol: ProxyProtocolConfig;
 	
 	    static createFrom(source: any = {}) {
 	        return new StratumConfig(source);
@@ -82,9 +555,36 @@ export namespace config {
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.port = source["port"];
+	        this.bindAddrs = source["bindAddrs"];
 	        this.maxConn = source["maxConn"];
 	        this.autoStart = source["autoStart"];
+	        this.maxConnPerIp = source["maxConnPerIp"];
+	        this.maxPending = source["maxPending"];
+	        this.handshakeTimeoutSec = source["handshakeTimeoutSec"];
+	        this.resumeTtlSec = source["resumeTtlSec"];
+	        this.ban = this.convertValues(source["ban"], BanConfig);
+	        this.auth = this.convertValues(source["auth"], AuthConfig);
+	        this.jobs = this.convertValues(source["jobs"], JobRetentionConfig);
+	        this.proxyProtocol = this.convertValues(source["proxyProtocol"], ProxyProtocolConfig);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 	export class NodeConfig {
 	    host: string;
@@ -113,21 +613,679 @@ export namespace config {
 	    vardiff: VardiffConfig;
 	    app: AppConfig;
 	    proxy: ProxyConfig;
+	    notifications: NotificationsConfig;
 	    miningMode: string;
 	
 	    static createFrom(source: any = {}) {
-	        return new Config(source);
+	        return new Config(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.node = this.convertValues(source["node"], NodeConfig);
+	        this.stratum = this.convertValues(source["stratum"], StratumConfig);
+	        this.mining = this.convertValues(source["mining"], MiningConfig);
+	        this.vardiff = this.convertValues(source["vardiff"], VardiffConfig);
+	        this.app = this.convertValues(source["app"], AppConfig);
+	        this.proxy = this.convertValues(source["proxy"], ProxyConfig);
+	        this.notifications = this.convertValues(source["notifications"], NotificationsConfig);
+	        this.miningMode = source["miningMode"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	
+	
+	
+	
+	
+	
+	
+	
+	
+	
+	
+	
+	
+	
+	
+
+}
+
+export namespace database {
+	
+	export class DeviceAlertEntry {
+	    id: number;
+	    timestamp: number;
+	    ip: string;
+	    metric: string;
+	    value: number;
+	    threshold: number;
+	    state: string;
+	    detail: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DeviceAlertEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.timestamp = source["timestamp"];
+	        this.ip = source["ip"];
+	        this.metric = source["metric"];
+	        this.value = source["value"];
+	        this.threshold = source["threshold"];
+	        this.state = source["state"];
+	        this.detail = source["detail"];
+	    }
+	}
+	export class DeviceChangeEntry {
+	    id: number;
+	    timestamp: number;
+	    batchId: string;
+	    ip: string;
+	    action: string;
+	    before: string;
+	    after: string;
+	    status: string;
+	    error: string;
+	    rolledBackBy: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DeviceChangeEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.timestamp = source["timestamp"];
+	        this.batchId = source["batchId"];
+	        this.ip = source["ip"];
+	        this.action = source["action"];
+	        this.before = source["before"];
+	        this.after = source["after"];
+	        this.status = source["status"];
+	        this.error = source["error"];
+	        this.rolledBackBy = source["rolledBackBy"];
+	    }
+	}
+	export class DiscoveredDeviceEntry {
+	    ip: string;
+	    macAddress: string;
+	    hostname: string;
+	    model: string;
+	    firmware: string;
+	    driver: string;
+	    currentPool: string;
+	    source: string;
+	    firstSeen: number;
+	    lastSeen: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DiscoveredDeviceEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ip = source["ip"];
+	        this.macAddress = source["macAddress"];
+	        this.hostname = source["hostname"];
+	        this.model = source["model"];
+	        this.firmware = source["firmware"];
+	        this.driver = source["driver"];
+	        this.currentPool = source["currentPool"];
+	        this.source = source["source"];
+	        this.firstSeen = source["firstSeen"];
+	        this.lastSeen = source["lastSeen"];
+	    }
+	}
+	export class MinerSessionEntry {
+	    sessionId: string;
+	    worker: string;
+	    workerId: string;
+	    ipAddress: string;
+	    userAgent: string;
+	    connectedAt: number;
+	    disconnectedAt: number;
+	    sharesAccepted: number;
+	    sharesRejected: number;
+	    bestDifficulty: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new MinerSessionEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.sessionId = source["sessionId"];
+	        this.worker = source["worker"];
+	        this.workerId = source["workerId"];
+	        this.ipAddress = source["ipAddress"];
+	        this.userAgent = source["userAgent"];
+	        this.connectedAt = source["connectedAt"];
+	        this.disconnectedAt = source["disconnectedAt"];
+	        this.sharesAccepted = source["sharesAccepted"];
+	        this.sharesRejected = source["sharesRejected"];
+	        this.bestDifficulty = source["bestDifficulty"];
+	    }
+	}
+	export class NotificationEntry {
+	    id: number;
+	    timestamp: number;
+	    channelId: string;
+	    channelName: string;
+	    event: string;
+	    title: string;
+	    status: string;
+	    attempts: number;
+	    error: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new NotificationEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.timestamp = source["timestamp"];
+	        this.channelId = source["channelId"];
+	        this.channelName = source["channelName"];
+	        this.event = source["event"];
+	        this.title = source["title"];
+	        this.status = source["status"];
+	        this.attempts = source["attempts"];
+	        this.error = source["error"];
+	    }
+	}
+	export class RejectBucket {
+	    bucket: number;
+	    key: string;
+	    shares: number;
+	    rejects: Record<string, number>;
+	    jobAgeMs: number;
+	    cleanAgeMs: number;
+	    rejectJobAgeMs: Record<string, number>;
+	
+	    static createFrom(source: any = {}) {
+	        return new RejectBucket(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.bucket = source["bucket"];
+	        this.key = source["key"];
+	        this.shares = source["shares"];
+	        this.rejects = source["rejects"];
+	        this.jobAgeMs = source["jobAgeMs"];
+	        this.cleanAgeMs = source["cleanAgeMs"];
+	        this.rejectJobAgeMs = source["rejectJobAgeMs"];
+	    }
+	}
+	export class RosterEventEntry {
+	    id: number;
+	    timestamp: number;
+	    workerId: string;
+	    kind: string;
+	    detail: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RosterEventEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.timestamp = source["timestamp"];
+	        this.workerId = source["workerId"];
+	        this.kind = source["kind"];
+	        this.detail = source["detail"];
+	    }
+	}
+	export class RoundEntry {
+	    id: number;
+	    startedAt: number;
+	    endedAt: number;
+	    endReason: string;
+	    work: number;
+	    expectedWork: number;
+	    effort: number;
+	    shares: number;
+	    bestShare: number;
+	    bestWorker: string;
+	    blockHeight: number;
+	    blockHash: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new RoundEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.startedAt = source["startedAt"];
+	        this.endedAt = source["endedAt"];
+	        this.endReason = source["endReason"];
+	        this.work = source["work"];
+	        this.expectedWork = source["expectedWork"];
+	        this.effort = source["effort"];
+	        this.shares = source["shares"];
+	        this.bestShare = source["bestShare"];
+	        this.bestWorker = source["bestWorker"];
+	        this.blockHeight = source["blockHeight"];
+	        this.blockHash = source["blockHash"];
+	    }
+	}
+	export class RoundWorkerEntry {
+	    roundId: number;
+	    worker: string;
+	    work: number;
+	    shares: number;
+	    bestShare: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new RoundWorkerEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.roundId = source["roundId"];
+	        this.worker = source["worker"];
+	        this.work = source["work"];
+	        this.shares = source["shares"];
+	        this.bestShare = source["bestShare"];
+	    }
+	}
+	export class ScheduleActionEntry {
+	    id: number;
+	    timestamp: number;
+	    ruleId: string;
+	    ruleName: string;
+	    action: string;
+	    phase: string;
+	    target: string;
+	    status: string;
+	    detail: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new ScheduleActionEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.timestamp = source["timestamp"];
+	        this.ruleId = source["ruleId"];
+	        this.ruleName = source["ruleName"];
+	        this.action = source["action"];
+	        this.phase = source["phase"];
+	        this.target = source["target"];
+	        this.status = source["status"];
+	        this.detail = source["detail"];
+	    }
+	}
+	export class SnapshotInfo {
+	    name: string;
+	    path: string;
+	    size: number;
+	    createdAt: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new SnapshotInfo(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.name = source["name"];
+	        this.path = source["path"];
+	        this.size = source["size"];
+	        this.createdAt = source["createdAt"];
+	    }
+	}
+	export class TagEntry {
+	    kind: string;
+	    target: string;
+	    tag: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new TagEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.kind = source["kind"];
+	        this.target = source["target"];
+	        this.tag = source["tag"];
+	    }
+	}
+	export class TelemetryEntry {
+	    timestamp: number;
+	    ip: string;
+	    hostname: string;
+	    temp: number;
+	    vrTemp: number;
+	    power: number;
+	    voltage: number;
+	    current: number;
+	    frequency: number;
+	    fanSpeed: number;
+	    fanRpm: number;
+	    hashrate: number;
+	    poolHashrate: number;
+	    bestDiff: number;
+	    freeHeap: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new TelemetryEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.timestamp = source["timestamp"];
+	        this.ip = source["ip"];
+	        this.hostname = source["hostname"];
+	        this.temp = source["temp"];
+	        this.vrTemp = source["vrTemp"];
+	        this.power = source["power"];
+	        this.voltage = source["voltage"];
+	        this.current = source["current"];
+	        this.frequency = source["frequency"];
+	        this.fanSpeed = source["fanSpeed"];
+	        this.fanRpm = source["fanRpm"];
+	        this.hashrate = source["hashrate"];
+	        this.poolHashrate = source["poolHashrate"];
+	        this.bestDiff = source["bestDiff"];
+	        this.freeHeap = source["freeHeap"];
+	    }
+	}
+	export class WorkerEntry {
+	    id: string;
+	    name: string;
+	    ipAddress: string;
+	    macAddress: string;
+	    firstSeen: number;
+	    lastSeen: number;
+	    sessions: number;
+	    sharesAccepted: number;
+	    sharesRejected: number;
+	    bestDifficulty: number;
+	    totalWork: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new WorkerEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.ipAddress = source["ipAddress"];
+	        this.macAddress = source["macAddress"];
+	        this.firstSeen = source["firstSeen"];
+	        this.lastSeen = source["lastSeen"];
+	        this.sessions = source["sessions"];
+	        this.sharesAccepted = source["sharesAccepted"];
+	        this.sharesRejected = source["sharesRejected"];
+	        this.bestDifficulty = source["bestDifficulty"];
+	        this.totalWork = source["totalWork"];
+	    }
+	}
+
+}
+
+export namespace logger {
+	
+	export class LogEntry {
+	    timestamp: string;
+	    level: string;
+	    component: string;
+	    message: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new LogEntry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.timestamp = source["timestamp"];
+	        this.level = source["level"];
+	        this.component = source["component"];
+	        this.message = source["message"];
+	    }
+	}
+
+}
+
+export namespace main {
+	
+	export class DeviceChangeResult {
+	    ip: string;
+	    changeId: number;
+	    ok: boolean;
+	    error?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DeviceChangeResult(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ip = source["ip"];
+	        this.changeId = source["changeId"];
+	        this.ok = source["ok"];
+	        this.error = source["error"];
+	    }
+	}
+	export class EnergyShare {
+	    id: string;
+	    name: string;
+	    kwh: number;
+	    cost: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new EnergyShare(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.kwh = source["kwh"];
+	        this.cost = source["cost"];
+	    }
+	}
+	export class EnergyBreakdown {
+	    period: string;
+	    currency: string;
+	    workers: EnergyShare[];
+	    groups: EnergyShare[];
+	    unassigned: EnergyShare;
+	    total: EnergyShare;
+	
+	    static createFrom(source: any = {}) {
+	        return new EnergyBreakdown(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.period = source["period"];
+	        this.currency = source["currency"];
+	        this.workers = this.convertValues(source["workers"], EnergyShare);
+	        this.groups = this.convertValues(source["groups"], EnergyShare);
+	        this.unassigned = this.convertValues(source["unassigned"], EnergyShare);
+	        this.total = this.convertValues(source["total"], EnergyShare);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class EnergyReportRow {
+	    label: string;
+	    start: number;
+	    kwh: number;
+	    cost: number;
+	    thDays: number;
+	    avgHashrate: number;
+	    costPerThDay: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new EnergyReportRow(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.label = source["label"];
+	        this.start = source["start"];
+	        this.kwh = source["kwh"];
+	        this.cost = source["cost"];
+	        this.thDays = source["thDays"];
+	        this.avgHashrate = source["avgHashrate"];
+	        this.costPerThDay = source["costPerThDay"];
+	    }
+	}
+	export class EnergyReport {
+	    period: string;
+	    groupId: number;
+	    currency: string;
+	    rows: EnergyReportRow[];
+	    total: EnergyReportRow;
+	
+	    static createFrom(source: any = {}) {
+	        return new EnergyReport(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.period = source["period"];
+	        this.groupId = source["groupId"];
+	        this.currency = source["currency"];
+	        this.rows = this.convertValues(source["rows"], EnergyReportRow);
+	        this.total = this.convertValues(source["total"], EnergyReportRow);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	
+	
+	export class GroupStats {
+	    groupId: number;
+	    name: string;
+	    workers: number;
+	    online: number;
+	    hashrate: number;
+	    sharesAccepted: number;
+	    sharesRejected: number;
+	    totalWatts: number;
+	    powerResponded: number;
+	    efficiency: number;
+	    dailyCost: number;
+	    estTimeToBlock: number;
+	    blockChance: number;
+	    expectedBlockCost: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new GroupStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.groupId = source["groupId"];
+	        this.name = source["name"];
+	        this.workers = source["workers"];
+	        this.online = source["online"];
+	        this.hashrate = source["hashrate"];
+	        this.sharesAccepted = source["sharesAccepted"];
+	        this.sharesRejected = source["sharesRejected"];
+	        this.totalWatts = source["totalWatts"];
+	        this.powerResponded = source["powerResponded"];
+	        this.efficiency = source["efficiency"];
+	        this.dailyCost = source["dailyCost"];
+	        this.estTimeToBlock = source["estTimeToBlock"];
+	        this.blockChance = source["blockChance"];
+	        this.expectedBlockCost = source["expectedBlockCost"];
+	    }
+	}
+	export class FleetOverview {
+	    totalHashrate: number;
+	    blockChance: number;
+	    totalWatts: number;
+	    powerResponded: number;
+	    powerQueried: number;
+	    dailyCost: number;
+	    electricityCost: number;
+	    efficiency: number;
+	    currency: string;
+	    currentRate: number;
+	    expectedBlockCost: number;
+	    groups: GroupStats[];
+	
+	    static createFrom(source: any = {}) {
+	        return new FleetOverview(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.node = this.convertValues(source["node"], NodeConfig);
-	        this.stratum = this.convertValues(source["stratum"], StratumConfig);
-	        this.mining = this.convertValues(source["mining"], MiningConfig);
-	        this.vardiff = this.convertValues(source["vardiff"], VardiffConfig);
-	        this.app = this.convertValues(source["app"], AppConfig);
-	        this.proxy = this.convertValues(source["proxy"], ProxyConfig);
-	        this.miningMode = source["miningMode"];
+	        this.totalHashrate = source["totalHashrate"];
+	        this.blockChance = source["blockChance"];
+	        this.totalWatts = source["totalWatts"];
+	        this.powerResponded = source["powerResponded"];
+	        this.powerQueried = source["powerQueried"];
+	        this.dailyCost = source["dailyCost"];
+	        this.electricityCost = source["electricityCost"];
+	        this.efficiency = source["efficiency"];
+	        this.currency = source["currency"];
+	        this.currentRate = source["currentRate"];
+	        this.expectedBlockCost = source["expectedBlockCost"];
+	        this.groups = this.convertValues(source["groups"], GroupStats);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -149,62 +1307,125 @@ export namespace config {
 		}
 	}
 	
+	export class GroupView {
+	    id: number;
+	    name: string;
+	    rules: miner.GroupRule[];
+	    members: string[];
 	
+	    static createFrom(source: any = {}) {
+	        return new GroupView(source);
+	    }
 	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.rules = this.convertValues(source["rules"], miner.GroupRule);
+	        this.members = source["members"];
+	    }
 	
-
-}
-
-export namespace logger {
-	
-	export class LogEntry {
-	    timestamp: string;
-	    level: string;
-	    component: string;
-	    message: string;
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class ScheduleStatus {
+	    id: string;
+	    name: string;
+	    enabled: boolean;
+	    groupId: number;
+	    days: string;
+	    start: string;
+	    end: string;
+	    tariffPeriod: string;
+	    minRate: number;
+	    action: string;
+	    frequency: number;
+	    active: boolean;
+	    since?: number;
+	    override: string;
+	    overrideUntil?: number;
+	    targets: number;
 	
 	    static createFrom(source: any = {}) {
-	        return new LogEntry(source);
+	        return new ScheduleStatus(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.timestamp = source["timestamp"];
-	        this.level = source["level"];
-	        this.component = source["component"];
-	        this.message = source["message"];
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.enabled = source["enabled"];
+	        this.groupId = source["groupId"];
+	        this.days = source["days"];
+	        this.start = source["start"];
+	        this.end = source["end"];
+	        this.tariffPeriod = source["tariffPeriod"];
+	        this.minRate = source["minRate"];
+	        this.action = source["action"];
+	        this.frequency = source["frequency"];
+	        this.active = source["active"];
+	        this.since = source["since"];
+	        this.override = source["override"];
+	        this.overrideUntil = source["overrideUntil"];
+	        this.targets = source["targets"];
 	    }
 	}
-
-}
-
-export namespace main {
-	
-	export class FleetOverview {
-	    totalHashrate: number;
-	    blockChance: number;
-	    totalWatts: number;
-	    powerResponded: number;
-	    powerQueried: number;
-	    dailyCost: number;
-	    electricityCost: number;
-	    efficiency: number;
+	export class WorkerView {
+	    worker: database.WorkerEntry;
+	    online: boolean;
+	    hashrate: number;
+	    rejectRate: number;
+	    roundBestDifficulty: number;
+	    sessions: miner.MinerInfo[];
+	    sessionHistory?: database.MinerSessionEntry[];
 	
 	    static createFrom(source: any = {}) {
-	        return new FleetOverview(source);
+	        return new WorkerView(source);
 	    }
 	
 	    constructor(source: any = {}) {
 	        if ('string' === typeof source) source = JSON.parse(source);
-	        this.totalHashrate = source["totalHashrate"];
-	        this.blockChance = source["blockChance"];
-	        this.totalWatts = source["totalWatts"];
-	        this.powerResponded = source["powerResponded"];
-	        this.powerQueried = source["powerQueried"];
-	        this.dailyCost = source["dailyCost"];
-	        this.electricityCost = source["electricityCost"];
-	        this.efficiency = source["efficiency"];
+	        this.worker = this.convertValues(source["worker"], database.WorkerEntry);
+	        this.online = source["online"];
+	        this.hashrate = source["hashrate"];
+	        this.rejectRate = source["rejectRate"];
+	        this.roundBestDifficulty = source["roundBestDifficulty"];
+	        this.sessions = this.convertValues(source["sessions"], miner.MinerInfo);
+	        this.sessionHistory = this.convertValues(source["sessionHistory"], database.MinerSessionEntry);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
 
 }
@@ -230,6 +1451,8 @@ export namespace miner {
 	    proxySharesFwd: number;
 	    proxySharesAccepted: number;
 	    proxySharesRejected: number;
+	    roundEffort: number;
+	    roundBestShare: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new DashboardStats(source);
@@ -255,6 +1478,102 @@ export namespace miner {
 	        this.proxySharesFwd = source["proxySharesFwd"];
 	        this.proxySharesAccepted = source["proxySharesAccepted"];
 	        this.proxySharesRejected = source["proxySharesRejected"];
+	        this.roundEffort = source["roundEffort"];
+	        this.roundBestShare = source["roundBestShare"];
+	    }
+	}
+	export class DeviceLimits {
+	    model: string;
+	    minFrequency: number;
+	    maxFrequency: number;
+	    minCoreVoltage: number;
+	    maxCoreVoltage: number;
+	    minFanSpeed: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DeviceLimits(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.model = source["model"];
+	        this.minFrequency = source["minFrequency"];
+	        this.maxFrequency = source["maxFrequency"];
+	        this.minCoreVoltage = source["minCoreVoltage"];
+	        this.maxCoreVoltage = source["maxCoreVoltage"];
+	        this.minFanSpeed = source["minFanSpeed"];
+	    }
+	}
+	export class DeviceSettings {
+	    frequency?: number;
+	    coreVoltage?: number;
+	    autoFan?: boolean;
+	    fanSpeed?: number;
+	    hostname?: string;
+	    stratumPassword?: string;
+	    fallbackStratumURL?: string;
+	    fallbackStratumPort?: number;
+	    fallbackStratumUser?: string;
+	    fallbackStratumPassword?: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new DeviceSettings(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.frequency = source["frequency"];
+	        this.coreVoltage = source["coreVoltage"];
+	        this.autoFan = source["autoFan"];
+	        this.fanSpeed = source["fanSpeed"];
+	        this.hostname = source["hostname"];
+	        this.stratumPassword = source["stratumPassword"];
+	        this.fallbackStratumURL = source["fallbackStratumURL"];
+	        this.fallbackStratumPort = source["fallbackStratumPort"];
+	        this.fallbackStratumUser = source["fallbackStratumUser"];
+	        this.fallbackStratumPassword = source["fallbackStratumPassword"];
+	    }
+	}
+	export class DeviceTelemetry {
+	    timestamp: number;
+	    ip: string;
+	    hostname: string;
+	    model: string;
+	    temp: number;
+	    vrTemp: number;
+	    power: number;
+	    voltage: number;
+	    current: number;
+	    frequency: number;
+	    fanSpeed: number;
+	    fanRpm: number;
+	    hashrate: number;
+	    poolHashrate: number;
+	    bestDiff: number;
+	    freeHeap: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new DeviceTelemetry(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.timestamp = source["timestamp"];
+	        this.ip = source["ip"];
+	        this.hostname = source["hostname"];
+	        this.model = source["model"];
+	        this.temp = source["temp"];
+	        this.vrTemp = source["vrTemp"];
+	        this.power = source["power"];
+	        this.voltage = source["voltage"];
+	        this.current = source["current"];
+	        this.frequency = source["frequency"];
+	        this.fanSpeed = source["fanSpeed"];
+	        this.fanRpm = source["fanRpm"];
+	        this.hashrate = source["hashrate"];
+	        this.poolHashrate = source["poolHashrate"];
+	        this.bestDiff = source["bestDiff"];
+	        this.freeHeap = source["freeHeap"];
 	    }
 	}
 	export class DiscoveredMiner {
@@ -265,6 +1584,8 @@ export namespace miner {
 	    temperature: number;
 	    currentPool: string;
 	    firmware: string;
+	    macAddress: string;
+	    driver: string;
 	
 	    static createFrom(source: any = {}) {
 	        return new DiscoveredMiner(source);
@@ -279,8 +1600,59 @@ export namespace miner {
 	        this.temperature = source["temperature"];
 	        this.currentPool = source["currentPool"];
 	        this.firmware = source["firmware"];
+	        this.macAddress = source["macAddress"];
+	        this.driver = source["driver"];
+	    }
+	}
+	export class GroupRule {
+	    type: string;
+	    pattern: string;
+	
+	    static createFrom(source: any = {}) {
+	        return new GroupRule(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.type = source["type"];
+	        this.pattern = source["pattern"];
+	    }
+	}
+	export class Group {
+	    id: number;
+	    name: string;
+	    rules: GroupRule[];
+	
+	    static createFrom(source: any = {}) {
+	        return new Group(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.name = source["name"];
+	        this.rules = this.convertValues(source["rules"], GroupRule);
 	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
 	}
+	
 	export class HashratePoint {
 	    t: number;
 	    h: number;
@@ -298,6 +1670,7 @@ export namespace miner {
 	export class MinerInfo {
 	    id: string;
 	    workerName: string;
+	    workerId: string;
 	    userAgent: string;
 	    ipAddress: string;
 	    // Go type: time
@@ -309,6 +1682,18 @@ export namespace miner {
 	    // Go type: time
 	    lastShareTime: any;
 	    bestDifficulty: number;
+	    roundBestDifficulty: number;
+	    jobLatencyMs: number;
+	    jobLatencyMaxMs: number;
+	    queueDepth: number;
+	    dropped: number;
+	    sharesStaleBlock: number;
+	    staleTipAgeMs: number;
+	    staleTipAgeMaxMs: number;
+	    rejectedNTime: number;
+	    rejectedVersionMask: number;
+	    rejectedExtranonce2: number;
+	    rejectedNonce: number;
 	
 	    static createFrom(source: any = {}) {
 	        return new MinerInfo(source);
@@ -318,6 +1703,7 @@ export namespace miner {
 	        if ('string' === typeof source) source = JSON.parse(source);
 	        this.id = source["id"];
 	        this.workerName = source["workerName"];
+	        this.workerId = source["workerId"];
 	        this.userAgent = source["userAgent"];
 	        this.ipAddress = source["ipAddress"];
 	        this.connectedAt = this.convertValues(source["connectedAt"], null);
@@ -327,6 +1713,163 @@ export namespace miner {
 	        this.sharesRejected = source["sharesRejected"];
 	        this.lastShareTime = this.convertValues(source["lastShareTime"], null);
 	        this.bestDifficulty = source["bestDifficulty"];
+	        this.roundBestDifficulty = source["roundBestDifficulty"];
+	        this.jobLatencyMs = source["jobLatencyMs"];
+	        this.jobLatencyMaxMs = source["jobLatencyMaxMs"];
+	        this.queueDepth = source["queueDepth"];
+	        this.dropped = source["dropped"];
+	        this.sharesStaleBlock = source["sharesStaleBlock"];
+	        this.staleTipAgeMs = source["staleTipAgeMs"];
+	        this.staleTipAgeMaxMs = source["staleTipAgeMaxMs"];
+	        this.rejectedNTime = source["rejectedNTime"];
+	        this.rejectedVersionMask = source["rejectedVersionMask"];
+	        this.rejectedExtranonce2 = source["rejectedExtranonce2"];
+	        this.rejectedNonce = source["rejectedNonce"];
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+	export class RosterStatus {
+	    workerId: string;
+	    label: string;
+	    nominalHashrate: number;
+	    online: boolean;
+	    lastSeen: number;
+	    hashrate: number;
+	    hashratePct: number;
+	    rejectRate: number;
+	    staleRate: number;
+	    missing: boolean;
+	    underperforming: boolean;
+	    rejectSpike: boolean;
+	
+	    static createFrom(source: any = {}) {
+	        return new RosterStatus(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.workerId = source["workerId"];
+	        this.label = source["label"];
+	        this.nominalHashrate = source["nominalHashrate"];
+	        this.online = source["online"];
+	        this.lastSeen = source["lastSeen"];
+	        this.hashrate = source["hashrate"];
+	        this.hashratePct = source["hashratePct"];
+	        this.rejectRate = source["rejectRate"];
+	        this.staleRate = source["staleRate"];
+	        this.missing = source["missing"];
+	        this.underperforming = source["underperforming"];
+	        this.rejectSpike = source["rejectSpike"];
+	    }
+	}
+	export class WorkerRoundStats {
+	    worker: string;
+	    work: number;
+	    shares: number;
+	    bestShare: number;
+	
+	    static createFrom(source: any = {}) {
+	        return new WorkerRoundStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.worker = source["worker"];
+	        this.work = source["work"];
+	        this.shares = source["shares"];
+	        this.bestShare = source["bestShare"];
+	    }
+	}
+	export class RoundStats {
+	    id: number;
+	    startedAt: number;
+	    durationSec: number;
+	    work: number;
+	    expectedWork: number;
+	    effort: number;
+	    shares: number;
+	    bestShare: number;
+	    bestWorker: string;
+	    workers: WorkerRoundStats[];
+	
+	    static createFrom(source: any = {}) {
+	        return new RoundStats(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.id = source["id"];
+	        this.startedAt = source["startedAt"];
+	        this.durationSec = source["durationSec"];
+	        this.work = source["work"];
+	        this.expectedWork = source["expectedWork"];
+	        this.effort = source["effort"];
+	        this.shares = source["shares"];
+	        this.bestShare = source["bestShare"];
+	        this.bestWorker = source["bestWorker"];
+	        this.workers = this.convertValues(source["workers"], WorkerRoundStats);
+	    }
+	
+		convertValues(a: any, classs: any, asMap: boolean = false): any {
+		    if (!a) {
+		        return a;
+		    }
+		    if (a.slice && a.map) {
+		        return (a as any[]).map(elem => this.convertValues(elem, classs));
+		    } else if ("object" === typeof a) {
+		        if (asMap) {
+		            for (const key of Object.keys(a)) {
+		                a[key] = new classs(a[key]);
+		            }
+		            return a;
+		        }
+		        return new classs(a);
+		    }
+		    return a;
+		}
+	}
+
+}
+
+export namespace stratum {
+	
+	export class Ban {
+	    ip: string;
+	    reason: string;
+	    score: number;
+	    // Go type: time
+	    since: any;
+	    // Go type: time
+	    until: any;
+	
+	    static createFrom(source: any = {}) {
+	        return new Ban(source);
+	    }
+	
+	    constructor(source: any = {}) {
+	        if ('string' === typeof source) source = JSON.parse(source);
+	        this.ip = source["ip"];
+	        this.reason = source["reason"];
+	        this.score = source["score"];
+	        this.since = this.convertValues(source["since"], null);
+	        this.until = this.convertValues(source["until"], null);
 	    }
 	
 		convertValues(a: any, classs: any, asMap: boolean = false): any {
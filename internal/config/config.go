@@ -3,9 +3,13 @@ package config
 import (
 	"encoding/json"
 	"fmt"
+	"net"
 	"os"
+	"path"
 	"path/filepath"
+	"strings"
 	"sync"
+	"time"
 
 	"govault/internal/coin"
 )
@@ -18,6 +22,8 @@ type Config struct {
 	App     AppConfig     `json:"app"`
 	Proxy   ProxyConfig   `json:"proxy"`
 
+	Notifications NotificationsConfig `json:"notifications"`
+
 	// MiningMode selects "solo" (local node) or "proxy" (upstream pool).
 	MiningMode string `json:"miningMode"`
 
@@ -31,6 +37,47 @@ type ProxyConfig struct {
 	Password   string `json:"password"`
 }
 
+// NotificationsConfig holds the channels events are delivered to.
+type NotificationsConfig struct {
+	Channels []NotifyChannel `json:"channels"`
+}
+
+// NotifyChannel is one notification destination with its delivery rules.
+type NotifyChannel struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Type    string `json:"type"` // "webhook", "discord", "telegram" or "email"
+	Enabled bool   `json:"enabled"`
+
+	// URL is the webhook URL, or the Telegram API base URL
+	// (default https://api.telegram.org).
+	URL      string `json:"url"`
+	BotToken string `json:"botToken"`
+	ChatID   string `json:"chatId"`
+
+	SMTPHost string   `json:"smtpHost"`
+	SMTPPort int      `json:"smtpPort"`
+	Username string   `json:"username"`
+	Password string   `json:"password"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+
+	Rules []NotifyRule `json:"rules"`
+
+	// Quiet hours in local time ("HH:MM"); events are dropped inside the
+	// window unless their rule ignores quiet hours. Empty = none.
+	QuietStart string `json:"quietStart"`
+	QuietEnd   string `json:"quietEnd"`
+}
+
+// NotifyRule enables one event type on a channel.
+type NotifyRule struct {
+	Event            string `json:"event"`
+	Enabled          bool   `json:"enabled"`
+	MinIntervalSec   int    `json:"minIntervalSec"` // rate limit between notifications of this event
+	IgnoreQuietHours bool   `json:"ignoreQuietHours"`
+}
+
 type NodeConfig struct {
 	Host     string `json:"host"`
 	Port     int    `json:"port"`
@@ -40,9 +87,68 @@ type NodeConfig struct {
 }
 
 type StratumConfig struct {
-	Port      int  `json:"port"`
-	MaxConn   int  `json:"maxConn"`
-	AutoStart bool `json:"autoStart"`
+	Port      int      `json:"port"`
+	BindAddrs []string `json:"bindAddrs"` // IPv4 or IPv6 addresses to listen on, e.g. "0.0.0.0", "::"
+	MaxConn   int      `json:"maxConn"`   // 0 = unlimited
+	AutoStart bool     `json:"autoStart"`
+
+	MaxConnPerIP        int `json:"maxConnPerIp"`        // 0 = unlimited
+	MaxPending          int `json:"maxPending"`          // connections not yet authorized
+	HandshakeTimeoutSec int `json:"handshakeTimeoutSec"` // time allowed to subscribe and authorize
+	ResumeTTLSec        int `json:"resumeTtlSec"`        // keep disconnected sessions resumable this long (0 = off)
+
+	Ban  BanConfig          `json:"ban"`
+	Auth AuthConfig         `json:"auth"`
+	Jobs JobRetentionConfig `json:"jobs"`
+
+	ProxyProtocol ProxyProtocolConfig `json:"proxyProtocol"`
+}
+
+// ProxyProtocolConfig accepts HAProxy PROXY protocol v1/v2 headers from
+// relays in Trusted, so sessions see the miner's address rather than the
+// relay's. Connections from anywhere else are never parsed for a header.
+type ProxyProtocolConfig struct {
+	Enabled bool     `json:"enabled"`
+	Trusted []string `json:"trusted"` // relay IPs or CIDRs
+}
+
+// JobRetentionConfig controls how long issued jobs accept shares. Jobs on
+// the current tip are kept for MaxAgeSec; jobs on a replaced tip only for
+// StaleGraceSec after the tip changed, so their shares are still told
+// apart as stale-block.
+type JobRetentionConfig struct {
+	MaxAgeSec     int `json:"maxAgeSec"`
+	StaleGraceSec int `json:"staleGraceSec"`
+	MaxJobs       int `json:"maxJobs"` // cap regardless of age
+}
+
+// AuthConfig controls which workers may authorize. In "open" mode any
+// non-empty worker name is accepted; in "restricted" mode a worker must
+// match one of the rules.
+type AuthConfig struct {
+	Mode  string     `json:"mode"` // "open" or "restricted"
+	Rules []AuthRule `json:"rules"`
+}
+
+// AuthRule admits workers whose name matches Pattern, optionally only
+// with a password and only from some networks.
+type AuthRule struct {
+	Pattern      string   `json:"pattern"`      // worker name glob, e.g. "rig-*"
+	PasswordHash string   `json:"passwordHash"` // PBKDF2 hash; empty = any password
+	CIDRs        []string `json:"cidrs"`        // empty = any IP
+}
+
+// BanConfig scores misbehaving IPs and bans them once the score reaches
+// Threshold. Scores drain by DecayPerMin points a minute.
+type BanConfig struct {
+	Enabled            bool     `json:"enabled"`
+	Threshold          int      `json:"threshold"`
+	DurationMin        int      `json:"durationMin"`
+	DecayPerMin        int      `json:"decayPerMin"`
+	MalformedPoints    int      `json:"malformedPoints"`    // unparsable or invalid request
+	InvalidSharePoints int      `json:"invalidSharePoints"` // share rejected as invalid (not stale or duplicate)
+	AllowList          []string `json:"allowList"`          // IPs or CIDRs never limited or banned
+	AuthFailPoints     int      `json:"authFailPoints"`     // mining.authorize refused
 }
 
 type MiningConfig struct {
@@ -64,6 +170,75 @@ type AppConfig struct {
 	Theme           string  `json:"theme"`
 	LogLevel        string  `json:"logLevel"`
 	ElectricityCost float64 `json:"electricityCost"`
+
+	// RoundEpochHours starts a new round after this many hours even if no
+	// block was found (0 = rounds only end on a found block).
+	RoundEpochHours int `json:"roundEpochHours"`
+
+	// WorkerIdentity selects how sessions map to persistent workers:
+	// "name" (worker name only), "name_ip" or "name_mac" (AxeOS MAC,
+	// falling back to IP until the MAC is known).
+	WorkerIdentity string `json:"workerIdentity"`
+
+	Retention RetentionConfig `json:"retention"`
+	Backup    BackupConfig    `json:"backup"`
+	Roster    RosterConfig    `json:"roster"`
+	Telemetry TelemetryConfig `json:"telemetry"`
+	Discovery DiscoveryConfig `json:"discovery"`
+
+	// Tariff adds time-of-use periods on top of ElectricityCost, which is
+	// the rate outside every period.
+	Tariff TariffConfig `json:"tariff"`
+
+	Scheduler SchedulerConfig `json:"scheduler"`
+}
+
+// DiscoveryConfig controls network scans for miners.
+type DiscoveryConfig struct {
+	// Targets are CIDRs ("192.168.1.0/24") or interface names ("eth0").
+	// Empty scans the /24 of the first non-loopback IPv4 address.
+	Targets     []string `json:"targets"`
+	Concurrency int      `json:"concurrency"` // parallel probes
+	TimeoutMs   int      `json:"timeoutMs"`   // per-host connect timeout
+	BudgetSec   int      `json:"budgetSec"`   // time limit for a whole scan
+	MaxHosts    int      `json:"maxHosts"`    // larger target sets are refused
+	MDNS        bool     `json:"mdns"`        // probe devices that advertise over mDNS
+}
+
+// TelemetryConfig controls device telemetry polling and alert thresholds.
+// A threshold of 0 disables that alert.
+type TelemetryConfig struct {
+	IntervalSec            int     `json:"intervalSec"` // 0 = polling disabled
+	RetentionDays          int     `json:"retentionDays"`
+	MaxTemp                float64 `json:"maxTemp"`                // ASIC °C
+	MaxVRTemp              float64 `json:"maxVrTemp"`              // voltage regulator °C
+	MinFreeHeap            int     `json:"minFreeHeap"`            // bytes
+	MaxHashrateMismatchPct float64 `json:"maxHashrateMismatchPct"` // device vs. pool-side estimate
+}
+
+// RosterConfig sets when expected workers are flagged.
+type RosterConfig struct {
+	GraceMinutes        int     `json:"graceMinutes"`        // offline longer than this = missing
+	UnderperformPct     float64 `json:"underperformPct"`     // percent of nominal hashrate
+	UnderperformMinutes int     `json:"underperformMinutes"` // sustained for this long
+	RejectSpikePct      float64 `json:"rejectSpikePct"`      // rejected + stale percent
+	RejectWindowMinutes int     `json:"rejectWindowMinutes"`
+	MinShares           int     `json:"minShares"` // shares needed in the window to judge
+}
+
+// BackupConfig controls scheduled database snapshots.
+type BackupConfig struct {
+	IntervalHours int `json:"intervalHours"` // 0 = scheduled snapshots disabled
+	Keep          int `json:"keep"`          // number of snapshots to retain
+}
+
+// RetentionConfig controls how long share history is kept at each
+// resolution. Raw shares are condensed into rollups before they are pruned.
+type RetentionConfig struct {
+	RawDays     int `json:"rawDays"`     // raw shares and hashrate snapshots
+	FiveMinDays int `json:"fiveMinDays"` // 5-minute rollups (0 = forever)
+	HourlyDays  int `json:"hourlyDays"`  // hourly rollups (0 = forever)
+	DailyDays   int `json:"dailyDays"`   // daily rollups (0 = forever)
 }
 
 func configDir() (string, error) {
@@ -137,6 +312,23 @@ func (c *Config) Save() error {
 	return nil
 }
 
+// Clone returns a deep copy of the config, to be changed and applied with
+// Update without touching slices that running services still read.
+func (c *Config) Clone() (*Config, error) {
+	c.mu.RLock()
+	data, err := json.Marshal(c)
+	c.mu.RUnlock()
+	if err != nil {
+		return nil, err
+	}
+	clone := Defaults()
+	if err := json.Unmarshal(data, clone); err != nil {
+		return nil, err
+	}
+	clone.path = c.path
+	return clone, nil
+}
+
 func (c *Config) Update(newCfg *Config) error {
 	c.mu.Lock()
 	c.Node = newCfg.Node
@@ -146,6 +338,7 @@ func (c *Config) Update(newCfg *Config) error {
 	c.App = newCfg.App
 	c.Proxy = newCfg.Proxy
 	c.MiningMode = newCfg.MiningMode
+	c.Notifications = newCfg.Notifications
 	c.mu.Unlock()
 	return c.Save()
 }
@@ -159,6 +352,38 @@ func (c *Config) Validate() error {
 	if c.Stratum.Port < 1 || c.Stratum.Port > 65535 {
 		return fmt.Errorf("invalid stratum port: %d", c.Stratum.Port)
 	}
+	if c.Stratum.MaxConn < 0 || c.Stratum.MaxConnPerIP < 0 || c.Stratum.MaxPending < 0 {
+		return fmt.Errorf("stratum connection limits must not be negative")
+	}
+	if c.Stratum.HandshakeTimeoutSec < 0 || c.Stratum.ResumeTTLSec < 0 {
+		return fmt.Errorf("stratum timeouts must not be negative")
+	}
+	if err := c.Stratum.Ban.validate(); err != nil {
+		return err
+	}
+	if err := c.Stratum.Auth.validate(); err != nil {
+		return err
+	}
+	if err := c.Stratum.Jobs.validate(); err != nil {
+		return err
+	}
+	if len(c.Stratum.BindAddrs) == 0 {
+		return fmt.Errorf("stratum needs at least one bind address")
+	}
+	seen := make(map[string]bool, len(c.Stratum.BindAddrs))
+	for _, addr := range c.Stratum.BindAddrs {
+		ip := net.ParseIP(addr)
+		if ip == nil {
+			return fmt.Errorf("invalid stratum bind address %q", addr)
+		}
+		if seen[ip.String()] {
+			return fmt.Errorf("duplicate stratum bind address %q", addr)
+		}
+		seen[ip.String()] = true
+	}
+	if err := c.Stratum.ProxyProtocol.validate(); err != nil {
+		return err
+	}
 
 	if c.MiningMode == "proxy" {
 		if c.Proxy.URL == "" {
@@ -185,6 +410,193 @@ func (c *Config) Validate() error {
 	if c.Vardiff.TargetTimeSec < 1 {
 		return fmt.Errorf("vardiff target_time must be at least 1 second")
 	}
+	if c.App.RoundEpochHours < 0 {
+		return fmt.Errorf("round epoch must not be negative")
+	}
+	switch c.App.WorkerIdentity {
+	case "", "name", "name_ip", "name_mac":
+	default:
+		return fmt.Errorf("invalid worker identity mode: %s", c.App.WorkerIdentity)
+	}
+	r := c.App.Retention
+	if r.RawDays < 1 {
+		return fmt.Errorf("raw share retention must be at least 1 day")
+	}
+	if r.FiveMinDays < 0 || r.HourlyDays < 0 || r.DailyDays < 0 {
+		return fmt.Errorf("rollup retention must not be negative")
+	}
+	if c.App.Backup.IntervalHours < 0 {
+		return fmt.Errorf("backup interval must not be negative")
+	}
+	if c.App.Backup.Keep < 1 {
+		return fmt.Errorf("must keep at least 1 backup")
+	}
+	ro := c.App.Roster
+	if ro.GraceMinutes < 1 || ro.UnderperformMinutes < 1 || ro.RejectWindowMinutes < 1 {
+		return fmt.Errorf("roster time windows must be at least 1 minute")
+	}
+	if ro.UnderperformPct < 0 || ro.UnderperformPct > 100 || ro.RejectSpikePct < 0 || ro.RejectSpikePct > 100 {
+		return fmt.Errorf("roster percentages must be between 0 and 100")
+	}
+	te := c.App.Telemetry
+	if te.IntervalSec < 0 || (te.IntervalSec > 0 && te.IntervalSec < 10) {
+		return fmt.Errorf("telemetry interval must be 0 (off) or at least 10 seconds")
+	}
+	if te.RetentionDays < 1 {
+		return fmt.Errorf("telemetry retention must be at least 1 day")
+	}
+	if te.MaxTemp < 0 || te.MaxVRTemp < 0 || te.MinFreeHeap < 0 || te.MaxHashrateMismatchPct < 0 {
+		return fmt.Errorf("telemetry thresholds must not be negative")
+	}
+	di := c.App.Discovery
+	for _, t := range di.Targets {
+		if strings.TrimSpace(t) == "" {
+			return fmt.Errorf("empty discovery target")
+		}
+		if strings.Contains(t, "/") {
+			if _, _, err := net.ParseCIDR(t); err != nil {
+				return fmt.Errorf("invalid discovery target %q: %w", t, err)
+			}
+		}
+	}
+	if di.Concurrency < 1 || di.Concurrency > 512 {
+		return fmt.Errorf("discovery concurrency must be between 1 and 512")
+	}
+	if di.TimeoutMs < 100 || di.TimeoutMs > 10000 {
+		return fmt.Errorf("discovery timeout must be between 100 and 10000 ms")
+	}
+	if di.BudgetSec < 5 {
+		return fmt.Errorf("discovery time budget must be at least 5 seconds")
+	}
+	if di.MaxHosts < 1 || di.MaxHosts > 65536 {
+		return fmt.Errorf("discovery host limit must be between 1 and 65536")
+	}
+	if err := c.App.Tariff.validate(); err != nil {
+		return err
+	}
+	if err := c.App.Scheduler.validate(); err != nil {
+		return err
+	}
+	if err := c.Notifications.validate(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (b *BanConfig) validate() error {
+	if b.Enabled && (b.Threshold < 1 || b.DurationMin < 1) {
+		return fmt.Errorf("ban threshold and duration must be positive")
+	}
+	if b.DecayPerMin < 0 || b.MalformedPoints < 0 || b.InvalidSharePoints < 0 || b.AuthFailPoints < 0 {
+		return fmt.Errorf("ban scores must not be negative")
+	}
+	for _, entry := range b.AllowList {
+		if net.ParseIP(entry) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(entry); err != nil {
+			return fmt.Errorf("invalid ban allow-list entry %q", entry)
+		}
+	}
+	return nil
+}
+
+func (p *ProxyProtocolConfig) validate() error {
+	if p.Enabled && len(p.Trusted) == 0 {
+		return fmt.Errorf("PROXY protocol needs at least one trusted relay")
+	}
+	for _, entry := range p.Trusted {
+		if net.ParseIP(entry) != nil {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(entry); err != nil {
+			return fmt.Errorf("invalid trusted relay %q", entry)
+		}
+	}
+	return nil
+}
+
+func (j *JobRetentionConfig) validate() error {
+	if j.MaxAgeSec < 1 || j.MaxJobs < 1 {
+		return fmt.Errorf("job retention age and job cap must be positive")
+	}
+	if j.StaleGraceSec < 0 {
+		return fmt.Errorf("job stale grace must not be negative")
+	}
+	return nil
+}
+
+func (a *AuthConfig) validate() error {
+	switch a.Mode {
+	case "open":
+	case "restricted":
+		if len(a.Rules) == 0 {
+			return fmt.Errorf("restricted worker access needs at least one rule")
+		}
+	default:
+		return fmt.Errorf("invalid worker access mode: %q", a.Mode)
+	}
+	for _, r := range a.Rules {
+		if _, err := path.Match(r.Pattern, ""); err != nil || r.Pattern == "" {
+			return fmt.Errorf("invalid worker pattern %q", r.Pattern)
+		}
+		if r.PasswordHash != "" && !strings.HasPrefix(r.PasswordHash, "pbkdf2-sha256$") {
+			return fmt.Errorf("worker %q: password must be stored hashed", r.Pattern)
+		}
+		for _, cidr := range r.CIDRs {
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				return fmt.Errorf("worker %q: invalid CIDR %q", r.Pattern, cidr)
+			}
+		}
+	}
+	return nil
+}
+
+func (n *NotificationsConfig) validate() error {
+	seen := make(map[string]bool, len(n.Channels))
+	for _, ch := range n.Channels {
+		if ch.ID == "" {
+			return fmt.Errorf("notification channel %q has no id", ch.Name)
+		}
+		if seen[ch.ID] {
+			return fmt.Errorf("duplicate notification channel id: %s", ch.ID)
+		}
+		seen[ch.ID] = true
+
+		switch ch.Type {
+		case "webhook", "discord":
+			if ch.URL == "" {
+				return fmt.Errorf("notification channel %q needs a URL", ch.Name)
+			}
+		case "telegram":
+			if ch.BotToken == "" || ch.ChatID == "" {
+				return fmt.Errorf("notification channel %q needs a bot token and chat id", ch.Name)
+			}
+		case "email":
+			if ch.SMTPHost == "" || ch.SMTPPort < 1 || ch.SMTPPort > 65535 {
+				return fmt.Errorf("notification channel %q needs an SMTP host and port", ch.Name)
+			}
+			if ch.From == "" || len(ch.To) == 0 {
+				return fmt.Errorf("notification channel %q needs from and to addresses", ch.Name)
+			}
+		default:
+			return fmt.Errorf("invalid notification channel type: %s", ch.Type)
+		}
+
+		for _, t := range []string{ch.QuietStart, ch.QuietEnd} {
+			if t == "" {
+				continue
+			}
+			if _, err := time.Parse("15:04", t); err != nil {
+				return fmt.Errorf("invalid quiet hours time %q in channel %q", t, ch.Name)
+			}
+		}
+		for _, r := range ch.Rules {
+			if r.MinIntervalSec < 0 {
+				return fmt.Errorf("notification rate limit must not be negative")
+			}
+		}
+	}
 	return nil
 }
 
@@ -199,3 +611,7 @@ func (c *Config) LogDir() string {
 func (c *Config) DBPath() string {
 	return filepath.Join(filepath.Dir(c.path), "govault.db")
 }
+
+func (c *Config) BackupDir() string {
+	return filepath.Join(filepath.Dir(c.path), "backups")
+}
@@ -11,8 +11,37 @@ func Defaults() *Config {
 		},
 		Stratum: StratumConfig{
 			Port:      10333,
+			BindAddrs: []string{"0.0.0.0"},
 			MaxConn:   100,
 			AutoStart: false,
+
+			MaxConnPerIP:        0,
+			MaxPending:          50,
+			HandshakeTimeoutSec: 30,
+			ResumeTTLSec:        300,
+			Ban: BanConfig{
+				Enabled:            false,
+				Threshold:          100,
+				DurationMin:        30,
+				DecayPerMin:        10,
+				MalformedPoints:    10,
+				InvalidSharePoints: 2,
+				AllowList:          []string{"127.0.0.1", "::1"},
+				AuthFailPoints:     20,
+			},
+			Auth: AuthConfig{
+				Mode:  "open",
+				Rules: []AuthRule{},
+			},
+			Jobs: JobRetentionConfig{
+				MaxAgeSec:     600,
+				StaleGraceSec: 60,
+				MaxJobs:       1000,
+			},
+			ProxyProtocol: ProxyProtocolConfig{
+				Enabled: false,
+				Trusted: []string{},
+			},
 		},
 		Mining: MiningConfig{
 			Coin:          "btc",
@@ -31,10 +60,54 @@ func Defaults() *Config {
 			Theme:           "dark",
 			LogLevel:        "info",
 			ElectricityCost: 0.10,
+			WorkerIdentity:  "name",
+			Retention: RetentionConfig{
+				RawDays:     30,
+				FiveMinDays: 90,
+				HourlyDays:  365,
+				DailyDays:   0,
+			},
+			Backup: BackupConfig{
+				IntervalHours: 24,
+				Keep:          7,
+			},
+			Roster: RosterConfig{
+				GraceMinutes:        10,
+				UnderperformPct:     70,
+				UnderperformMinutes: 15,
+				RejectSpikePct:      10,
+				RejectWindowMinutes: 30,
+				MinShares:           20,
+			},
+			Telemetry: TelemetryConfig{
+				IntervalSec:            60,
+				RetentionDays:          30,
+				MaxTemp:                70,
+				MaxVRTemp:              80,
+				MaxHashrateMismatchPct: 25,
+			},
+			Discovery: DiscoveryConfig{
+				Targets:     []string{},
+				Concurrency: 50,
+				TimeoutMs:   1000,
+				BudgetSec:   120,
+				MaxHosts:    4096,
+				MDNS:        true,
+			},
+			Tariff: TariffConfig{
+				Currency: "USD",
+				Periods:  []TariffPeriod{},
+			},
+			Scheduler: SchedulerConfig{
+				Rules: []ScheduleRule{},
+			},
 		},
 		Proxy: ProxyConfig{
 			Password: "x",
 		},
 		MiningMode: "solo",
+		Notifications: NotificationsConfig{
+			Channels: []NotifyChannel{},
+		},
 	}
 }
@@ -0,0 +1,110 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Schedule actions.
+const (
+	ScheduleWithhold  = "withhold"  // stop sending mining.notify
+	ScheduleReconnect = "reconnect" // client.reconnect with a delay until the rule ends
+	ScheduleThrottle  = "throttle"  // lower the AxeOS frequency
+	ScheduleShutdown  = "shutdown"  // stop hashing through the device API
+)
+
+// SchedulerConfig pauses or throttles worker groups on a schedule.
+type SchedulerConfig struct {
+	Enabled bool           `json:"enabled"`
+	Rules   []ScheduleRule `json:"rules"`
+}
+
+// ScheduleRule acts on a group while all of its conditions hold: the day
+// and time window, the tariff period and the minimum rate. Conditions left
+// empty are ignored, but at least one must be set.
+type ScheduleRule struct {
+	ID      string `json:"id"`
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+	GroupID int64  `json:"groupId"` // 0 = every worker
+
+	Days         string  `json:"days"`  // "all", "weekday" or "weekend"
+	Start        string  `json:"start"` // local "HH:MM"; empty = all day
+	End          string  `json:"end"`
+	TariffPeriod string  `json:"tariffPeriod"` // tariff period name
+	MinRate      float64 `json:"minRate"`      // per kWh; 0 = any rate
+
+	Action    string  `json:"action"`    // see Schedule* constants
+	Frequency float64 `json:"frequency"` // MHz, for "throttle"
+}
+
+// ScheduleActiveAt reports whether a rule's conditions hold at a moment. Disabled
+// rules are never active.
+func (a *AppConfig) ScheduleActiveAt(r ScheduleRule, t time.Time) bool {
+	if !r.Enabled {
+		return false
+	}
+	if r.Start != "" {
+		if !inWindow(t, r.Days, r.Start, r.End) {
+			return false
+		}
+	} else if !onDays(t.Local(), r.Days) {
+		return false
+	}
+	if r.TariffPeriod != "" && !strings.EqualFold(a.TariffPeriodAt(t), r.TariffPeriod) {
+		return false
+	}
+	if r.MinRate > 0 && a.RateAt(t) < r.MinRate {
+		return false
+	}
+	return true
+}
+
+func (s *SchedulerConfig) validate() error {
+	seen := make(map[string]bool, len(s.Rules))
+	for _, r := range s.Rules {
+		if r.ID == "" {
+			return fmt.Errorf("schedule rule %q has no ID", r.Name)
+		}
+		if seen[r.ID] {
+			return fmt.Errorf("duplicate schedule rule ID %q", r.ID)
+		}
+		seen[r.ID] = true
+		switch r.Days {
+		case "", "all", "weekday", "weekend":
+		default:
+			return fmt.Errorf("schedule rule %q: days must be all, weekday or weekend", r.Name)
+		}
+		if (r.Start == "") != (r.End == "") {
+			return fmt.Errorf("schedule rule %q needs both a start and an end", r.Name)
+		}
+		if r.Start != "" {
+			if _, err := clockMinutes(r.Start); err != nil {
+				return fmt.Errorf("schedule rule %q: invalid start %q", r.Name, r.Start)
+			}
+			if _, err := clockMinutes(r.End); err != nil {
+				return fmt.Errorf("schedule rule %q: invalid end %q", r.Name, r.End)
+			}
+			if r.Start == r.End {
+				return fmt.Errorf("schedule rule %q: time window is empty", r.Name)
+			}
+		}
+		if r.MinRate < 0 {
+			return fmt.Errorf("schedule rule %q: minimum rate must not be negative", r.Name)
+		}
+		if r.Start == "" && r.TariffPeriod == "" && r.MinRate == 0 && (r.Days == "" || r.Days == "all") {
+			return fmt.Errorf("schedule rule %q needs a time window, days, tariff period or minimum rate", r.Name)
+		}
+		switch r.Action {
+		case ScheduleWithhold, ScheduleReconnect, ScheduleShutdown:
+		case ScheduleThrottle:
+			if r.Frequency <= 0 {
+				return fmt.Errorf("schedule rule %q: throttle needs a frequency", r.Name)
+			}
+		default:
+			return fmt.Errorf("schedule rule %q: unknown action %q", r.Name, r.Action)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// TariffConfig describes electricity pricing.
+type TariffConfig struct {
+	Currency string         `json:"currency"` // ISO code shown in reports, e.g. "USD"
+	Periods  []TariffPeriod `json:"periods"`  // first matching period wins
+}
+
+// TariffPeriod is a time-of-use rate. Start and End are local "HH:MM"
+// times; a period with End before Start runs past midnight. Days is
+// matched against the day of the moment being priced.
+type TariffPeriod struct {
+	Name  string  `json:"name"`
+	Days  string  `json:"days"` // "all", "weekday" or "weekend"
+	Start string  `json:"start"`
+	End   string  `json:"end"`
+	Rate  float64 `json:"rate"` // per kWh
+}
+
+// RateAt returns the electricity rate per kWh at a moment: the first
+// matching tariff period, or ElectricityCost.
+func (a *AppConfig) RateAt(t time.Time) float64 {
+	if p := a.periodAt(t); p != nil {
+		return p.Rate
+	}
+	return a.ElectricityCost
+}
+
+// TariffPeriodAt returns the name of the tariff period in effect at a
+// moment, or "" outside every period.
+func (a *AppConfig) TariffPeriodAt(t time.Time) string {
+	if p := a.periodAt(t); p != nil {
+		return p.Name
+	}
+	return ""
+}
+
+func (a *AppConfig) periodAt(t time.Time) *TariffPeriod {
+	for i, p := range a.Tariff.Periods {
+		if inWindow(t, p.Days, p.Start, p.End) {
+			return &a.Tariff.Periods[i]
+		}
+	}
+	return nil
+}
+
+// AverageRate returns the time-weighted rate over a week starting at the
+// given moment, sampled every 15 minutes.
+func (a *AppConfig) AverageRate(from time.Time) float64 {
+	const step = 15 * time.Minute
+	const samples = int(7 * 24 * time.Hour / step)
+	var sum float64
+	for i := 0; i < samples; i++ {
+		sum += a.RateAt(from.Add(time.Duration(i) * step))
+	}
+	return sum / float64(samples)
+}
+
+func (t *TariffConfig) validate() error {
+	if len(t.Currency) > 8 {
+		return fmt.Errorf("invalid currency: %q", t.Currency)
+	}
+	for _, p := range t.Periods {
+		switch p.Days {
+		case "all", "weekday", "weekend":
+		default:
+			return fmt.Errorf("tariff period %q: days must be all, weekday or weekend", p.Name)
+		}
+		if _, err := clockMinutes(p.Start); err != nil {
+			return fmt.Errorf("tariff period %q: invalid start %q", p.Name, p.Start)
+		}
+		if _, err := clockMinutes(p.End); err != nil {
+			return fmt.Errorf("tariff period %q: invalid end %q", p.Name, p.End)
+		}
+		if p.Start == p.End {
+			return fmt.Errorf("tariff period %q is empty", p.Name)
+		}
+		if p.Rate < 0 {
+			return fmt.Errorf("tariff period %q: rate must not be negative", p.Name)
+		}
+	}
+	return nil
+}
+
+// inWindow reports whether t (local time) falls on one of the days and
+// between the "HH:MM" start and end. A window with end before start runs
+// past midnight; an unparsable window never matches.
+func inWindow(t time.Time, days, start, end string) bool {
+	t = t.Local()
+	if !onDays(t, days) {
+		return false
+	}
+	from, err1 := clockMinutes(start)
+	to, err2 := clockMinutes(end)
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	minute := t.Hour()*60 + t.Minute()
+	if from <= to {
+		return minute >= from && minute < to
+	}
+	return minute >= from || minute < to
+}
+
+// onDays reports whether t falls on "all", "weekday" or "weekend" days.
+func onDays(t time.Time, days string) bool {
+	weekend := t.Weekday() == time.Saturday || t.Weekday() == time.Sunday
+	return !(days == "weekday" && weekend) && !(days == "weekend" && !weekend)
+}
+
+// clockMinutes parses "HH:MM" into minutes after midnight.
+func clockMinutes(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
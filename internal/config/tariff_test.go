@@ -0,0 +1,104 @@
+package config
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// testTariff has an overnight off-peak rate on weekdays, a cheap weekend
+// and a weekday evening peak, over a flat 0.20 rate.
+func testTariff() *AppConfig {
+	return &AppConfig{
+		ElectricityCost: 0.20,
+		Tariff: TariffConfig{
+			Currency: "EUR",
+			Periods: []TariffPeriod{
+				{Name: "weekend", Days: "weekend", Start: "00:00", End: "23:59", Rate: 0.08},
+				{Name: "night", Days: "weekday", Start: "22:00", End: "06:00", Rate: 0.12},
+				{Name: "peak", Days: "weekday", Start: "17:00", End: "21:00", Rate: 0.35},
+				{Name: "late peak", Days: "all", Start: "20:00", End: "23:00", Rate: 0.50},
+			},
+		},
+	}
+}
+
+func TestTariffPeriodAt(t *testing.T) {
+	at := func(day, hour, min int) time.Time {
+		return time.Date(2026, time.October, day, hour, min, 0, 0, time.Local) // the 16th is a Friday
+	}
+	cases := []struct {
+		name   string
+		t      time.Time
+		period string
+		rate   float64
+	}{
+		{"weekday daytime", at(14, 12, 0), "", 0.20},
+		{"peak starts inclusive", at(14, 17, 0), "peak", 0.35},
+		{"peak ends exclusive", at(14, 21, 0), "late peak", 0.50},
+		{"first match wins", at(14, 20, 30), "peak", 0.35},
+		{"night before midnight", at(14, 22, 0), "night", 0.12},
+		{"night after midnight", at(15, 5, 59), "night", 0.12},
+		{"night ends exclusive", at(15, 6, 0), "", 0.20},
+		{"saturday uses its own day", at(17, 1, 0), "weekend", 0.08},
+		{"friday night before midnight", at(16, 23, 30), "night", 0.12},
+		{"weekend gap before midnight", at(18, 23, 59), "", 0.20},
+		{"monday after midnight", at(19, 0, 30), "night", 0.12},
+	}
+	a := testTariff()
+	for _, c := range cases {
+		if got := a.TariffPeriodAt(c.t); got != c.period {
+			t.Errorf("%s: period %q, want %q", c.name, got, c.period)
+		}
+		if got := a.RateAt(c.t); got != c.rate {
+			t.Errorf("%s: rate %v, want %v", c.name, got, c.rate)
+		}
+	}
+}
+
+func TestTariffNoPeriods(t *testing.T) {
+	a := &AppConfig{ElectricityCost: 0.15}
+	now := time.Now()
+	if a.RateAt(now) != 0.15 || a.TariffPeriodAt(now) != "" || math.Abs(a.AverageRate(now)-0.15) > 1e-9 {
+		t.Error("without periods every moment should use ElectricityCost")
+	}
+}
+
+func TestAverageRate(t *testing.T) {
+	a := &AppConfig{
+		ElectricityCost: 0.20,
+		Tariff: TariffConfig{Periods: []TariffPeriod{
+			{Name: "night", Days: "all", Start: "00:00", End: "06:00", Rate: 0.10},
+		}},
+	}
+	// A quarter of every day at half the rate.
+	from := time.Date(2026, time.October, 12, 9, 7, 0, 0, time.Local)
+	if got := a.AverageRate(from); math.Abs(got-0.175) > 1e-9 {
+		t.Errorf("AverageRate = %v, want 0.175", got)
+	}
+}
+
+func TestTariffValidate(t *testing.T) {
+	valid := TariffPeriod{Name: "night", Days: "weekday", Start: "22:00", End: "06:00", Rate: 0.1}
+	cases := []struct {
+		name string
+		edit func(*TariffConfig)
+		ok   bool
+	}{
+		{"valid", func(*TariffConfig) {}, true},
+		{"long currency", func(c *TariffConfig) { c.Currency = "DOLLARS!!" }, false},
+		{"unknown days", func(c *TariffConfig) { c.Periods[0].Days = "monday" }, false},
+		{"bad start", func(c *TariffConfig) { c.Periods[0].Start = "25:00" }, false},
+		{"bad end", func(c *TariffConfig) { c.Periods[0].End = "6pm" }, false},
+		{"empty window", func(c *TariffConfig) { c.Periods[0].End = c.Periods[0].Start }, false},
+		{"negative rate", func(c *TariffConfig) { c.Periods[0].Rate = -0.01 }, false},
+		{"free power", func(c *TariffConfig) { c.Periods[0].Rate = 0 }, true},
+	}
+	for _, c := range cases {
+		tc := TariffConfig{Currency: "USD", Periods: []TariffPeriod{valid}}
+		c.edit(&tc)
+		if err := tc.validate(); (err == nil) != c.ok {
+			t.Errorf("%s: validate = %v, want ok %v", c.name, err, c.ok)
+		}
+	}
+}
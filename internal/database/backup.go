@@ -0,0 +1,254 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SnapshotInfo describes a database snapshot file.
+type SnapshotInfo struct {
+	Name      string `json:"name"`
+	Path      string `json:"path"`
+	Size      int64  `json:"size"`
+	CreatedAt int64  `json:"createdAt"`
+}
+
+const snapshotPrefix = "govault-"
+
+// Snapshot writes a consistent online copy of the database into dir.
+// label is appended to the file name (e.g. "pre-restore") and may be empty.
+func (db *DB) Snapshot(dir, label string) (SnapshotInfo, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return SnapshotInfo{}, fmt.Errorf("create backup dir: %w", err)
+	}
+
+	now := time.Now()
+	name := snapshotPrefix + now.Format("20060102-150405")
+	if label != "" {
+		name += "-" + label
+	}
+	name += ".db"
+	path := filepath.Join(dir, name)
+
+	if err := db.vacuumInto(path); err != nil {
+		return SnapshotInfo{}, fmt.Errorf("snapshot: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return SnapshotInfo{}, err
+	}
+	return SnapshotInfo{Name: name, Path: path, Size: info.Size(), CreatedAt: now.Unix()}, nil
+}
+
+// ListSnapshots returns the snapshots in dir, newest first.
+func ListSnapshots(dir string) ([]SnapshotInfo, error) {
+	entries, err := os.ReadDir(dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var result []SnapshotInfo
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, snapshotPrefix) || !strings.HasSuffix(name, ".db") {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			continue
+		}
+		result = append(result, SnapshotInfo{
+			Name:      name,
+			Path:      filepath.Join(dir, name),
+			Size:      info.Size(),
+			CreatedAt: info.ModTime().Unix(),
+		})
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].CreatedAt > result[j].CreatedAt
+	})
+	return result, nil
+}
+
+// RotateSnapshots deletes all but the newest keep snapshots in dir.
+// Returns the number of snapshots removed.
+func RotateSnapshots(dir string, keep int) (int, error) {
+	snapshots, err := ListSnapshots(dir)
+	if err != nil {
+		return 0, err
+	}
+	removed := 0
+	for i := keep; i < len(snapshots); i++ {
+		if err := os.Remove(snapshots[i].Path); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// RestoreFrom replaces the contents of the open database with the snapshot
+// at path. The snapshot is migrated to the current schema first, and the
+// copy runs in a single transaction, so the database is never left half
+// restored. Snapshots from a newer GoVault are refused.
+func (db *DB) RestoreFrom(path string) error {
+	tmp := db.path + ".restore"
+	if err := copyFile(path, tmp); err != nil {
+		return fmt.Errorf("copy snapshot: %w", err)
+	}
+	defer removeDBFiles(tmp)
+
+	src, err := Open(tmp)
+	if err != nil {
+		return fmt.Errorf("open snapshot: %w", err)
+	}
+	if backup := src.UpgradeBackup(); backup != "" {
+		defer os.Remove(backup)
+	}
+	src.Close()
+
+	// ATTACH is per-connection, so pin one for the whole restore.
+	ctx := context.Background()
+	conn, err := db.conn.Conn(ctx)
+	if err != nil {
+		return err
+	}
+	defer conn.Close()
+
+	if _, err := conn.ExecContext(ctx, `ATTACH DATABASE ? AS restore`, tmp); err != nil {
+		return fmt.Errorf("attach snapshot: %w", err)
+	}
+	defer conn.ExecContext(ctx, `DETACH DATABASE restore`)
+
+	tables, err := listTables(ctx, conn, "main")
+	if err != nil {
+		return err
+	}
+	srcTables, err := listTables(ctx, conn, "restore")
+	if err != nil {
+		return err
+	}
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	for table, mainCols := range tables {
+		if table == "schema_version" {
+			continue
+		}
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`DELETE FROM main.%q`, table)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("clear %s: %w", table, err)
+		}
+		srcCols, ok := srcTables[table]
+		if !ok {
+			continue
+		}
+		var cols []string
+		for _, c := range mainCols {
+			if contains(srcCols, c) {
+				cols = append(cols, fmt.Sprintf("%q", c))
+			}
+		}
+		if len(cols) == 0 {
+			continue
+		}
+		list := strings.Join(cols, ", ")
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`INSERT INTO main.%q (%s) SELECT %s FROM restore.%q`,
+			table, list, list, table)); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("restore %s: %w", table, err)
+		}
+	}
+	return tx.Commit()
+}
+
+// listTables returns the user tables of a schema with their columns.
+func listTables(ctx context.Context, conn *sql.Conn, schema string) (map[string][]string, error) {
+	rows, err := conn.QueryContext(ctx, fmt.Sprintf(
+		`SELECT name FROM %s.sqlite_master WHERE type = 'table' AND name NOT LIKE 'sqlite_%%'`, schema))
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	tables := make(map[string][]string, len(names))
+	for _, name := range names {
+		rows, err := conn.QueryContext(ctx, `SELECT name FROM pragma_table_info(?, ?)`, name, schema)
+		if err != nil {
+			return nil, err
+		}
+		var cols []string
+		for rows.Next() {
+			var col string
+			if err := rows.Scan(&col); err != nil {
+				rows.Close()
+				return nil, err
+			}
+			cols = append(cols, col)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+		tables[name] = cols
+	}
+	return tables, nil
+}
+
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// removeDBFiles deletes a database file and its WAL/SHM companions.
+func removeDBFiles(path string) {
+	for _, suffix := range []string{"", "-wal", "-shm"} {
+		os.Remove(path + suffix)
+	}
+}
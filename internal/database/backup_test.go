@@ -0,0 +1,144 @@
+package database
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func openTestDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := Open(filepath.Join(t.TempDir(), "govault.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func shareWorkers(t *testing.T, db *DB) []string {
+	t.Helper()
+	shares, err := db.RecentShares(100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	workers := make([]string, len(shares))
+	for i, s := range shares {
+		workers[len(shares)-1-i] = s.Worker
+	}
+	return workers
+}
+
+func TestSnapshotRestoreRoundTrip(t *testing.T) {
+	db := openTestDB(t)
+	if err := db.InsertShares([]ShareEntry{
+		{Timestamp: 1700000000, MinerID: "s1", Worker: "rig1", Difficulty: 512, Accepted: true},
+		{Timestamp: 1700000001, MinerID: "s2", Worker: "rig2", Difficulty: 256, RejectCode: "stale-job"},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	db.UpsertWorker("rig1", "rig1", "192.0.2.1", "aa:bb:cc:dd:ee:ff", 1700000000)
+
+	dir := t.TempDir()
+	snap, err := db.Snapshot(dir, "test")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := ListSnapshots(dir); len(list) != 1 || list[0].Name != snap.Name {
+		t.Errorf("snapshots listed %+v, want %s", list, snap.Name)
+	}
+
+	db.InsertShares([]ShareEntry{{Timestamp: 1700000002, MinerID: "s3", Worker: "rig3", Difficulty: 1}})
+	db.ClearRejectedShares()
+	if err := db.RestoreFrom(snap.Path); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := shareWorkers(t, db); len(got) != 2 || got[0] != "rig1" || got[1] != "rig2" {
+		t.Errorf("shares after restore from %v, want rig1 and rig2", got)
+	}
+	if w, err := db.GetWorker("rig1"); err != nil || w == nil || w.MACAddress != "aa:bb:cc:dd:ee:ff" {
+		t.Errorf("worker after restore: %+v, %v", w, err)
+	}
+	if db.SchemaVersion() != LatestSchemaVersion() {
+		t.Errorf("schema v%d after restore", db.SchemaVersion())
+	}
+	if leftovers, _ := filepath.Glob(db.Path() + ".restore*"); len(leftovers) != 0 {
+		t.Errorf("restore left %v behind", leftovers)
+	}
+}
+
+func TestRestoreOlderSchema(t *testing.T) {
+	db := openTestDB(t)
+	db.InsertShares([]ShareEntry{{Timestamp: 1700000009, MinerID: "s9", Worker: "current", Difficulty: 1}})
+
+	old := filepath.Join(t.TempDir(), "govault-20240101-000000.db")
+	createV1(t, old)
+	if err := db.RestoreFrom(old); err != nil {
+		t.Fatal(err)
+	}
+
+	shares, err := db.RecentShares(10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shares) != 1 || shares[0].Worker != "rig" || shares[0].Difficulty != 512 || !shares[0].Accepted {
+		t.Fatalf("shares after restoring a v1 snapshot: %+v", shares)
+	}
+	if shares[0].RejectCode != "" || shares[0].TipAgeMs != 0 {
+		t.Errorf("columns added since v1 not defaulted: %+v", shares[0])
+	}
+	if v := schemaVersionOf(t, old); v != 1 {
+		t.Errorf("snapshot itself migrated to v%d", v)
+	}
+	if leftovers, _ := filepath.Glob(db.Path() + ".restore*"); len(leftovers) != 0 {
+		t.Errorf("restore left %v behind", leftovers)
+	}
+}
+
+func TestRestoreNewerSchema(t *testing.T) {
+	db := openTestDB(t)
+	db.InsertShares([]ShareEntry{{Timestamp: 1700000000, MinerID: "s1", Worker: "rig1", Difficulty: 1}})
+
+	future := openTestDB(t)
+	future.conn.Exec(`INSERT INTO schema_version (version, name, applied_at) VALUES (?, 'future', 0)`, LatestSchemaVersion()+1)
+	snap, err := future.Snapshot(t.TempDir(), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.RestoreFrom(snap.Path); !errors.Is(err, ErrSchemaTooNew) {
+		t.Errorf("RestoreFrom = %v, want ErrSchemaTooNew", err)
+	}
+	if got := shareWorkers(t, db); len(got) != 1 {
+		t.Errorf("refused restore changed the database: %v", got)
+	}
+}
+
+func TestRotateSnapshots(t *testing.T) {
+	db := openTestDB(t)
+	dir := t.TempDir()
+	for i, label := range []string{"a", "b", "c", "d"} {
+		snap, err := db.Snapshot(dir, label)
+		if err != nil {
+			t.Fatal(err)
+		}
+		mod := time.Now().Add(time.Duration(i-4) * time.Hour)
+		os.Chtimes(snap.Path, mod, mod)
+	}
+	os.WriteFile(filepath.Join(dir, "notes.txt"), nil, 0644)
+
+	removed, err := RotateSnapshots(dir, 2)
+	if err != nil || removed != 2 {
+		t.Fatalf("RotateSnapshots = %d, %v; want 2 removed", removed, err)
+	}
+	list, _ := ListSnapshots(dir)
+	if len(list) != 2 || !strings.HasSuffix(list[0].Name, "-d.db") || !strings.HasSuffix(list[1].Name, "-c.db") {
+		t.Errorf("kept %+v, want the d and c snapshots", list)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Error("rotation removed a file that is not a snapshot")
+	}
+}
@@ -14,11 +14,15 @@ const (
 type ShareEntry struct {
 	Timestamp    int64
 	MinerID      string
-	Worker       string
+	Worker       string // persistent worker ID (see WorkerEntry)
 	Difficulty   float64
 	SessionDiff  float64
 	Accepted     bool
-	RejectReason string
+	RejectCode   string // reject taxonomy code, e.g. "stale-job"
+	RejectReason string // detail within RejectCode
+	JobAgeMs     int64  // job age at submit
+	CleanAgeMs   int64  // time since the last clean job
+	TipAgeMs     int64  // stale-block shares: time since the tip changed
 }
 
 // Buffer batches share writes and flushes them periodically or when full.
@@ -0,0 +1,80 @@
+package database
+
+import "database/sql"
+
+// DeviceChangeEntry records one settings change pushed to a device. Before
+// and After hold the changed settings as JSON.
+type DeviceChangeEntry struct {
+	ID           int64  `json:"id"`
+	Timestamp    int64  `json:"timestamp"`
+	BatchID      string `json:"batchId"`
+	IP           string `json:"ip"`
+	Action       string `json:"action"` // "apply", "rollback" or "restart"
+	Before       string `json:"before"`
+	After        string `json:"after"`
+	Status       string `json:"status"` // "ok" or "failed"
+	Error        string `json:"error"`
+	RolledBackBy int64  `json:"rolledBackBy"` // ID of the rollback change, 0 if none
+}
+
+const deviceChangeColumns = `id, timestamp, batch_id, ip, action, before, after, status, error, rolled_back_by`
+
+// InsertDeviceChange records a device change and returns its ID.
+func (db *DB) InsertDeviceChange(c DeviceChangeEntry) (int64, error) {
+	result, err := db.conn.Exec(`INSERT INTO device_changes (timestamp, batch_id, ip, action, before, after, status, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		c.Timestamp, c.BatchID, c.IP, c.Action, c.Before, c.After, c.Status, c.Error)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// MarkDeviceChangeRolledBack links a change to the rollback that undid it.
+func (db *DB) MarkDeviceChangeRolledBack(id, rollbackID int64) error {
+	_, err := db.conn.Exec(`UPDATE device_changes SET rolled_back_by = ? WHERE id = ?`, rollbackID, id)
+	return err
+}
+
+// GetDeviceChange returns a single device change, or nil if it does not exist.
+func (db *DB) GetDeviceChange(id int64) (*DeviceChangeEntry, error) {
+	row := db.conn.QueryRow(`SELECT `+deviceChangeColumns+` FROM device_changes WHERE id = ?`, id)
+	var c DeviceChangeEntry
+	err := row.Scan(&c.ID, &c.Timestamp, &c.BatchID, &c.IP, &c.Action, &c.Before, &c.After, &c.Status, &c.Error, &c.RolledBackBy)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+// RecentDeviceChanges returns the most recent N changes, newest first,
+// optionally for one device.
+func (db *DB) RecentDeviceChanges(ip string, limit int) ([]DeviceChangeEntry, error) {
+	query := `SELECT ` + deviceChangeColumns + ` FROM device_changes`
+	var args []interface{}
+	if ip != "" {
+		query += ` WHERE ip = ?`
+		args = append(args, ip)
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []DeviceChangeEntry
+	for rows.Next() {
+		var c DeviceChangeEntry
+		if err := rows.Scan(&c.ID, &c.Timestamp, &c.BatchID, &c.IP, &c.Action, &c.Before, &c.After, &c.Status, &c.Error, &c.RolledBackBy); err != nil {
+			return nil, err
+		}
+		result = append(result, c)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,61 @@
+package database
+
+// DiscoveredDeviceEntry is a device found by a network scan or mDNS.
+type DiscoveredDeviceEntry struct {
+	IP          string `json:"ip"`
+	MACAddress  string `json:"macAddress"`
+	Hostname    string `json:"hostname"`
+	Model       string `json:"model"`
+	Firmware    string `json:"firmware"`
+	Driver      string `json:"driver"`
+	CurrentPool string `json:"currentPool"`
+	Source      string `json:"source"`
+	FirstSeen   int64  `json:"firstSeen"`
+	LastSeen    int64  `json:"lastSeen"`
+}
+
+// UpsertDiscoveredDevice records a sighting of a device, keeping its
+// first-seen time.
+func (db *DB) UpsertDiscoveredDevice(d DiscoveredDeviceEntry) error {
+	_, err := db.conn.Exec(`INSERT INTO discovered_devices
+			(ip, mac, hostname, model, firmware, driver, current_pool, source, first_seen, last_seen)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(ip) DO UPDATE SET
+			mac          = CASE WHEN excluded.mac != '' THEN excluded.mac ELSE discovered_devices.mac END,
+			hostname     = CASE WHEN excluded.hostname != '' THEN excluded.hostname ELSE discovered_devices.hostname END,
+			model        = excluded.model,
+			firmware     = excluded.firmware,
+			driver       = excluded.driver,
+			current_pool = excluded.current_pool,
+			source       = excluded.source,
+			last_seen    = excluded.last_seen`,
+		d.IP, d.MACAddress, d.Hostname, d.Model, d.Firmware, d.Driver, d.CurrentPool, d.Source, d.LastSeen, d.LastSeen)
+	return err
+}
+
+// ListDiscoveredDevices returns all discovered devices, most recently seen first.
+func (db *DB) ListDiscoveredDevices() ([]DiscoveredDeviceEntry, error) {
+	rows, err := db.conn.Query(`SELECT ip, mac, hostname, model, firmware, driver, current_pool, source, first_seen, last_seen
+		FROM discovered_devices ORDER BY last_seen DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []DiscoveredDeviceEntry
+	for rows.Next() {
+		var d DiscoveredDeviceEntry
+		if err := rows.Scan(&d.IP, &d.MACAddress, &d.Hostname, &d.Model, &d.Firmware, &d.Driver,
+			&d.CurrentPool, &d.Source, &d.FirstSeen, &d.LastSeen); err != nil {
+			return nil, err
+		}
+		result = append(result, d)
+	}
+	return result, rows.Err()
+}
+
+// DeleteDiscoveredDevice forgets a discovered device.
+func (db *DB) DeleteDiscoveredDevice(ip string) error {
+	_, err := db.conn.Exec(`DELETE FROM discovered_devices WHERE ip = ?`, ip)
+	return err
+}
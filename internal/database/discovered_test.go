@@ -0,0 +1,35 @@
+package database
+
+import "testing"
+
+func TestUpsertDiscoveredDevice(t *testing.T) {
+	db := openTestDB(t)
+	db.UpsertDiscoveredDevice(DiscoveredDeviceEntry{
+		IP: "192.0.2.10", MACAddress: "aa:bb:cc:dd:ee:ff", Hostname: "bitaxe1", Model: "BM1366",
+		Driver: "axeos", Source: "scan", LastSeen: 1700000000,
+	})
+	// An mDNS sighting without MAC or hostname keeps the earlier ones.
+	db.UpsertDiscoveredDevice(DiscoveredDeviceEntry{
+		IP: "192.0.2.10", Model: "BM1368", Driver: "axeos", Source: "mdns", LastSeen: 1700000600,
+	})
+	db.UpsertDiscoveredDevice(DiscoveredDeviceEntry{IP: "192.0.2.11", Driver: "cgminer", Source: "scan", LastSeen: 1700000300})
+
+	devices, err := db.ListDiscoveredDevices()
+	if err != nil || len(devices) != 2 {
+		t.Fatalf("ListDiscoveredDevices = %+v, %v; want two devices", devices, err)
+	}
+	want := DiscoveredDeviceEntry{
+		IP: "192.0.2.10", MACAddress: "aa:bb:cc:dd:ee:ff", Hostname: "bitaxe1", Model: "BM1368",
+		Driver: "axeos", Source: "mdns", FirstSeen: 1700000000, LastSeen: 1700000600,
+	}
+	if devices[0] != want {
+		t.Errorf("device %+v\nwant   %+v", devices[0], want)
+	}
+
+	if err := db.DeleteDiscoveredDevice("192.0.2.10"); err != nil {
+		t.Fatal(err)
+	}
+	if devices, _ := db.ListDiscoveredDevices(); len(devices) != 1 || devices[0].IP != "192.0.2.11" {
+		t.Errorf("after delete %+v", devices)
+	}
+}
@@ -0,0 +1,70 @@
+package database
+
+import "fmt"
+
+// EnergyEntry is metered energy for one device and worker in one hour.
+type EnergyEntry struct {
+	Bucket   int64   `json:"bucket"` // hour start (unix)
+	IP       string  `json:"ip"`
+	Worker   string  `json:"worker"`
+	WattHour float64 `json:"wattHour"`
+	Cost     float64 `json:"cost"`
+}
+
+// AddEnergy adds metered energy to the hourly totals.
+func (db *DB) AddEnergy(entries []EnergyEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO energy_usage (bucket, ip, worker, wh, cost) VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(bucket, ip, worker) DO UPDATE SET
+			wh   = wh + excluded.wh,
+			cost = cost + excluded.cost`)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("prepare: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, e := range entries {
+		if _, err := stmt.Exec(e.Bucket, e.IP, e.Worker, e.WattHour, e.Cost); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("exec: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// EnergyUsage returns hourly energy rows in [since, until), oldest first.
+func (db *DB) EnergyUsage(since, until int64) ([]EnergyEntry, error) {
+	rows, err := db.conn.Query(`SELECT bucket, ip, worker, wh, cost FROM energy_usage
+		WHERE bucket >= ? AND bucket < ? ORDER BY bucket`, since, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []EnergyEntry
+	for rows.Next() {
+		var e EnergyEntry
+		if err := rows.Scan(&e.Bucket, &e.IP, &e.Worker, &e.WattHour, &e.Cost); err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, rows.Err()
+}
+
+// FirstEnergyBucket returns the oldest metered hour, or 0 if none.
+func (db *DB) FirstEnergyBucket() (int64, error) {
+	var first int64
+	err := db.conn.QueryRow(`SELECT COALESCE(MIN(bucket), 0) FROM energy_usage`).Scan(&first)
+	return first, err
+}
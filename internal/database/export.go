@@ -0,0 +1,244 @@
+package database
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// ExportFilter narrows an export. Zero values mean unbounded.
+type ExportFilter struct {
+	From   int64  `json:"from"`   // unix seconds, inclusive
+	To     int64  `json:"to"`     // unix seconds, exclusive
+	Worker string `json:"worker"` // worker name or persistent worker ID
+}
+
+// exportPageSize is how many rows Export reads per query. The connection
+// is released between pages, so share flushes and UI queries are not held
+// up for the length of a large export.
+const exportPageSize = 5000
+
+// exportSpec describes how one dataset is read for export.
+type exportSpec struct {
+	table      string
+	columns    []string
+	timeCol    string   // "" if the dataset has no time axis
+	workerCols []string // columns matched by the worker filter
+	idCols     []string // worker ID columns, also matched by the worker's name
+}
+
+var exportSpecs = map[string]exportSpec{
+	"shares": {
+		table: "shares",
+		columns: []string{"id", "timestamp", "miner_id", "worker", "difficulty", "session_diff", "accepted",
+			"reject_code", "reject_reason", "job_age_ms", "clean_age_ms", "tip_age_ms"},
+		timeCol: "timestamp",
+		idCols:  []string{"worker"}, // worker ID since persistent identities
+	},
+	"blocks": {
+		table:      "blocks",
+		columns:    []string{"id", "timestamp", "height", "hash", "miner_id", "worker", "difficulty"},
+		timeCol:    "timestamp",
+		workerCols: []string{"worker"},
+	},
+	"sessions": {
+		table: "miner_sessions",
+		columns: []string{"session_id", "worker", "worker_id", "ip_address", "user_agent", "connected_at", "disconnected_at",
+			"shares_accepted", "shares_rejected", "best_difficulty"},
+		timeCol:    "connected_at",
+		workerCols: []string{"worker"},
+		idCols:     []string{"worker_id"},
+	},
+	"hashrate": {
+		table:   "hashrate_history",
+		columns: []string{"timestamp", "hashrate"},
+		timeCol: "timestamp",
+	},
+	"cumulative": {
+		table:   "cumulative_stats",
+		columns: []string{"total_accepted", "total_rejected", "best_difficulty", "blocks_found"},
+	},
+}
+
+// ExportDatasets lists the datasets accepted by Export.
+func ExportDatasets() []string {
+	return []string{"shares", "blocks", "sessions", "hashrate", "cumulative"}
+}
+
+// Export streams a dataset to w as "csv" (with a header row) or "ndjson"
+// (one JSON object per line). Rows are written as they are read, so large
+// ranges never sit in memory. Returns the number of rows written.
+func (db *DB) Export(w io.Writer, dataset, format string, f ExportFilter) (int64, error) {
+	spec, ok := exportSpecs[dataset]
+	if !ok {
+		return 0, fmt.Errorf("unknown export dataset: %s", dataset)
+	}
+	if format != "csv" && format != "ndjson" {
+		return 0, fmt.Errorf("unknown export format: %s", format)
+	}
+	if f.Worker != "" && len(spec.workerCols)+len(spec.idCols) == 0 {
+		return 0, fmt.Errorf("%s export does not support a worker filter", dataset)
+	}
+
+	var where []string
+	var args []interface{}
+	if spec.timeCol != "" {
+		if f.From > 0 {
+			where = append(where, spec.timeCol+" >= ?")
+			args = append(args, f.From)
+		}
+		if f.To > 0 {
+			where = append(where, spec.timeCol+" < ?")
+			args = append(args, f.To)
+		}
+	}
+	if f.Worker != "" {
+		var or []string
+		for _, c := range spec.workerCols {
+			or = append(or, c+" = ?")
+			args = append(args, f.Worker)
+		}
+		for _, c := range spec.idCols {
+			or = append(or, c+" = ?", c+" IN (SELECT id FROM workers WHERE name = ?)")
+			args = append(args, f.Worker, f.Worker)
+		}
+		where = append(where, "("+strings.Join(or, " OR ")+")")
+	}
+
+	// Pages are read in (time, rowid) order, each starting after the last
+	// row of the previous page.
+	timeKey, order, after := "0", "rowid", "rowid > ?"
+	if spec.timeCol != "" {
+		timeKey, order = spec.timeCol, spec.timeCol+", rowid"
+		after = "(" + spec.timeCol + " > ? OR (" + spec.timeCol + " = ? AND rowid > ?))"
+	}
+	selectCols := "SELECT rowid, " + timeKey + ", " + strings.Join(spec.columns, ", ") + " FROM " + spec.table
+
+	bw := bufio.NewWriter(w)
+	var cw *csv.Writer
+	if format == "csv" {
+		cw = csv.NewWriter(bw)
+		if err := cw.Write(spec.columns); err != nil {
+			return 0, err
+		}
+	}
+
+	var lastID, lastTime int64
+	values := make([]interface{}, len(spec.columns))
+	ptrs := []interface{}{&lastID, &lastTime}
+	for i := range values {
+		ptrs = append(ptrs, &values[i])
+	}
+	record := make([]string, len(values))
+	write := func() error {
+		if cw == nil {
+			return writeNDJSONRow(bw, spec.columns, values)
+		}
+		for i, v := range values {
+			record[i] = formatExportValue(v)
+		}
+		return cw.Write(record)
+	}
+
+	var n int64
+	for page := 0; ; page++ {
+		conds, pageArgs := where, args
+		if page > 0 {
+			conds = append(conds[:len(conds):len(conds)], after)
+			if spec.timeCol != "" {
+				pageArgs = append(pageArgs[:len(pageArgs):len(pageArgs)], lastTime, lastTime, lastID)
+			} else {
+				pageArgs = append(pageArgs[:len(pageArgs):len(pageArgs)], lastID)
+			}
+		}
+		query := selectCols
+		if len(conds) > 0 {
+			query += " WHERE " + strings.Join(conds, " AND ")
+		}
+		query += " ORDER BY " + order + " LIMIT " + strconv.Itoa(exportPageSize)
+
+		read, err := db.exportPage(query, pageArgs, ptrs, write)
+		n += read
+		if err != nil {
+			return n, err
+		}
+		if read < exportPageSize {
+			break
+		}
+	}
+
+	if cw != nil {
+		cw.Flush()
+		if err := cw.Error(); err != nil {
+			return n, err
+		}
+	}
+	return n, bw.Flush()
+}
+
+// exportPage runs one page query, scanning each row into ptrs and passing
+// it to write. Returns the number of rows written.
+func (db *DB) exportPage(query string, args, ptrs []interface{}, write func() error) (int64, error) {
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var n int64
+	for rows.Next() {
+		if err := rows.Scan(ptrs...); err != nil {
+			return n, err
+		}
+		if err := write(); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, rows.Err()
+}
+
+func formatExportValue(v interface{}) string {
+	switch x := v.(type) {
+	case nil:
+		return ""
+	case int64:
+		return strconv.FormatInt(x, 10)
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+	case []byte:
+		return string(x)
+	case string:
+		return x
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+// writeNDJSONRow writes one JSON object, keeping keys in column order.
+func writeNDJSONRow(w *bufio.Writer, columns []string, values []interface{}) error {
+	w.WriteByte('{')
+	for i, c := range columns {
+		if i > 0 {
+			w.WriteByte(',')
+		}
+		key, _ := json.Marshal(c)
+		w.Write(key)
+		w.WriteByte(':')
+		v := values[i]
+		if b, ok := v.([]byte); ok {
+			v = string(b)
+		}
+		val, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		w.Write(val)
+	}
+	w.WriteByte('}')
+	return w.WriteByte('\n')
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// GroupEntry is a stored worker group. Rules holds the group's rules as JSON.
+type GroupEntry struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	Rules     string `json:"rules"`
+	CreatedAt int64  `json:"createdAt"`
+}
+
+// TagEntry attaches a free-form tag to a worker, device or IP.
+type TagEntry struct {
+	Kind   string `json:"kind"` // "worker", "device" or "ip"
+	Target string `json:"target"`
+	Tag    string `json:"tag"`
+}
+
+// SaveGroup creates a group (ID 0) or updates an existing one and returns
+// its ID.
+func (db *DB) SaveGroup(g GroupEntry) (int64, error) {
+	if g.ID == 0 {
+		result, err := db.conn.Exec(`INSERT INTO worker_groups (name, rules, created_at) VALUES (?, ?, ?)`,
+			g.Name, g.Rules, g.CreatedAt)
+		if err != nil {
+			return 0, err
+		}
+		return result.LastInsertId()
+	}
+	result, err := db.conn.Exec(`UPDATE worker_groups SET name = ?, rules = ? WHERE id = ?`, g.Name, g.Rules, g.ID)
+	if err != nil {
+		return 0, err
+	}
+	if n, _ := result.RowsAffected(); n == 0 {
+		return 0, fmt.Errorf("group %d not found", g.ID)
+	}
+	return g.ID, nil
+}
+
+// DeleteGroup removes a group.
+func (db *DB) DeleteGroup(id int64) error {
+	_, err := db.conn.Exec(`DELETE FROM worker_groups WHERE id = ?`, id)
+	return err
+}
+
+// ListGroups returns all groups ordered by name.
+func (db *DB) ListGroups() ([]GroupEntry, error) {
+	rows, err := db.conn.Query(`SELECT id, name, rules, created_at FROM worker_groups ORDER BY name`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []GroupEntry
+	for rows.Next() {
+		var g GroupEntry
+		if err := rows.Scan(&g.ID, &g.Name, &g.Rules, &g.CreatedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, g)
+	}
+	return result, rows.Err()
+}
+
+// SetTags replaces the tags of one target.
+func (db *DB) SetTags(kind, target string, tags []string) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM tags WHERE kind = ? AND target = ?`, kind, target); err != nil {
+		tx.Rollback()
+		return err
+	}
+	for _, tag := range tags {
+		if _, err := tx.Exec(`INSERT OR IGNORE INTO tags (kind, target, tag) VALUES (?, ?, ?)`, kind, target, tag); err != nil {
+			tx.Rollback()
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// ListTags returns all tags, optionally of one kind.
+func (db *DB) ListTags(kind string) ([]TagEntry, error) {
+	var rows *sql.Rows
+	var err error
+	if kind == "" {
+		rows, err = db.conn.Query(`SELECT kind, target, tag FROM tags ORDER BY kind, target, tag`)
+	} else {
+		rows, err = db.conn.Query(`SELECT kind, target, tag FROM tags WHERE kind = ? ORDER BY target, tag`, kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []TagEntry
+	for rows.Next() {
+		var t TagEntry
+		if err := rows.Scan(&t.Kind, &t.Target, &t.Tag); err != nil {
+			return nil, err
+		}
+		result = append(result, t)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,51 @@
+package database
+
+import "testing"
+
+func TestSaveGroup(t *testing.T) {
+	db := openTestDB(t)
+	id, err := db.SaveGroup(GroupEntry{Name: "shelf", Rules: `[{"type":"tag","pattern":"shelf"}]`, CreatedAt: 1700000000})
+	if err != nil || id == 0 {
+		t.Fatalf("SaveGroup = %d, %v", id, err)
+	}
+	db.SaveGroup(GroupEntry{Name: "garage", Rules: `[]`, CreatedAt: 1700000100})
+
+	if got, err := db.SaveGroup(GroupEntry{ID: id, Name: "top shelf", Rules: `[]`, CreatedAt: 1800000000}); err != nil || got != id {
+		t.Fatalf("update = %d, %v; want %d", got, err, id)
+	}
+	groups, _ := db.ListGroups()
+	if len(groups) != 2 || groups[0].Name != "garage" || groups[1].Name != "top shelf" ||
+		groups[1].Rules != `[]` || groups[1].CreatedAt != 1700000000 {
+		t.Errorf("groups %+v, want both by name with the update keeping the creation time", groups)
+	}
+
+	if _, err := db.SaveGroup(GroupEntry{ID: id + 100, Name: "ghost"}); err == nil {
+		t.Error("updating a missing group succeeded")
+	}
+	db.DeleteGroup(id)
+	if groups, _ := db.ListGroups(); len(groups) != 1 || groups[0].Name != "garage" {
+		t.Errorf("after delete %+v", groups)
+	}
+}
+
+func TestSetTags(t *testing.T) {
+	db := openTestDB(t)
+	db.SetTags("worker", "rig1", []string{"garage", "shelf-2"})
+	db.SetTags("ip", "10.1.2.3", []string{"garage"})
+	if err := db.SetTags("worker", "rig1", []string{"loft", "loft"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tags, _ := db.ListTags("worker")
+	if len(tags) != 1 || tags[0] != (TagEntry{Kind: "worker", Target: "rig1", Tag: "loft"}) {
+		t.Errorf("worker tags %+v, want only the replacement", tags)
+	}
+	if all, _ := db.ListTags(""); len(all) != 2 || all[0].Kind != "ip" {
+		t.Errorf("all tags %+v, want ip then worker", all)
+	}
+
+	db.SetTags("ip", "10.1.2.3", nil)
+	if tags, _ := db.ListTags("ip"); len(tags) != 0 {
+		t.Errorf("ip tags %+v after clearing", tags)
+	}
+}
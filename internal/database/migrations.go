@@ -0,0 +1,548 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// ErrSchemaTooNew is returned when the database was written by a newer
+// GoVault than this one.
+var ErrSchemaTooNew = errors.New("database was created by a newer version of GoVault")
+
+// keepUpgradeBackups is how many pre-upgrade backups are kept beside the
+// database; older ones are deleted after each upgrade.
+const keepUpgradeBackups = 3
+
+// migration is one ordered, transactional schema change. Migrations must be
+// idempotent so they can run against databases created before schema
+// versioning existed.
+type migration struct {
+	version int
+	name    string
+	up      func(tx *sql.Tx) error
+}
+
+// migrations lists every schema change in order. Append only: never edit or
+// reorder a migration that has shipped.
+var migrations = []migration{
+	{1, "baseline", migrateBaseline},
+	{2, "rounds", migrateRounds},
+	{3, "workers", migrateWorkers},
+	{4, "share rollups", migrateShareRollups},
+	{5, "notification log", migrateNotificationLog},
+	{6, "roster", migrateRoster},
+	{7, "device telemetry", migrateDeviceTelemetry},
+	{8, "device changes", migrateDeviceChanges},
+	{9, "discovered devices", migrateDiscoveredDevices},
+	{10, "groups and tags", migrateGroupsAndTags},
+	{11, "energy usage", migrateEnergyUsage},
+	{12, "schedule actions", migrateScheduleActions},
+	{13, "share tip age", migrateShareTipAge},
+	{14, "reject taxonomy", migrateRejectTaxonomy},
+}
+
+// SchemaVersion returns the schema version of the open database.
+func (db *DB) SchemaVersion() int {
+	return db.version
+}
+
+// UpgradeBackup returns the path of the backup taken before the last schema
+// upgrade, or "" if no upgrade was needed.
+func (db *DB) UpgradeBackup() string {
+	return db.upgradeBackup
+}
+
+// LatestSchemaVersion returns the schema version this build writes.
+func LatestSchemaVersion() int {
+	return migrations[len(migrations)-1].version
+}
+
+func (db *DB) migrate() error {
+	if _, err := db.conn.Exec(`CREATE TABLE IF NOT EXISTS schema_version (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT    NOT NULL,
+		applied_at INTEGER NOT NULL
+	)`); err != nil {
+		return fmt.Errorf("create schema_version: %w", err)
+	}
+
+	var current int
+	if err := db.conn.QueryRow(`SELECT COALESCE(MAX(version), 0) FROM schema_version`).Scan(&current); err != nil {
+		return fmt.Errorf("read schema version: %w", err)
+	}
+	latest := LatestSchemaVersion()
+	if current > latest {
+		return fmt.Errorf("%w (schema v%d, this build supports up to v%d)", ErrSchemaTooNew, current, latest)
+	}
+	if current == latest {
+		db.version = current
+		return nil
+	}
+
+	// Back up existing data before touching it. A database without a
+	// schema_version row but with tables predates versioning.
+	hasData, err := db.hasTable("shares")
+	if err != nil {
+		return err
+	}
+	if hasData {
+		backup := fmt.Sprintf("%s.v%d-%s.bak", db.path, current, time.Now().Format("20060102-150405"))
+		if err := db.vacuumInto(backup); err != nil {
+			return fmt.Errorf("backup before upgrade: %w", err)
+		}
+		db.upgradeBackup = backup
+		db.rotateUpgradeBackups()
+	}
+
+	for _, m := range migrations {
+		if m.version <= current {
+			continue
+		}
+		if err := db.applyMigration(m); err != nil {
+			return fmt.Errorf("migration %d (%s): %w", m.version, m.name, err)
+		}
+		db.version = m.version
+	}
+	return nil
+}
+
+// rotateUpgradeBackups deletes all but the newest keepUpgradeBackups
+// pre-upgrade backups of the database.
+func (db *DB) rotateUpgradeBackups() {
+	paths, err := filepath.Glob(db.path + ".v*.bak")
+	if err != nil || len(paths) <= keepUpgradeBackups {
+		return
+	}
+	modTimes := make(map[string]time.Time, len(paths))
+	for _, p := range paths {
+		if info, err := os.Stat(p); err == nil {
+			modTimes[p] = info.ModTime()
+		}
+	}
+	sort.Slice(paths, func(i, j int) bool {
+		return modTimes[paths[i]].After(modTimes[paths[j]])
+	})
+	for _, p := range paths[keepUpgradeBackups:] {
+		os.Remove(p)
+	}
+}
+
+func (db *DB) applyMigration(m migration) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+	if err := m.up(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_version (version, name, applied_at) VALUES (?, ?, ?)`,
+		m.version, m.name, time.Now().Unix()); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// vacuumInto writes a consistent copy of the database to path.
+func (db *DB) vacuumInto(path string) error {
+	os.Remove(path)
+	_, err := db.conn.Exec(`VACUUM INTO ?`, path)
+	return err
+}
+
+func (db *DB) hasTable(name string) (bool, error) {
+	var n int
+	err := db.conn.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`, name).Scan(&n)
+	return n > 0, err
+}
+
+// addColumn adds a column unless it already exists.
+func addColumn(tx *sql.Tx, table, column, def string) error {
+	rows, err := tx.Query(`SELECT name FROM pragma_table_info(?)`, table)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			return err
+		}
+		if name == column {
+			return nil
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	rows.Close()
+	_, err = tx.Exec(fmt.Sprintf(`ALTER TABLE %s ADD COLUMN %s %s`, table, column, def))
+	return err
+}
+
+func migrateBaseline(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS shares (
+			id         INTEGER PRIMARY KEY AUTOINCREMENT,
+			timestamp  INTEGER NOT NULL,
+			miner_id   TEXT    NOT NULL,
+			worker     TEXT    NOT NULL DEFAULT '',
+			difficulty REAL    NOT NULL,
+			accepted   INTEGER NOT NULL DEFAULT 1,
+			reject_reason TEXT NOT NULL DEFAULT ''
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_shares_timestamp ON shares(timestamp);
+		CREATE INDEX IF NOT EXISTS idx_shares_miner     ON shares(miner_id);
+
+		CREATE TABLE IF NOT EXISTS blocks (
+			id        INTEGER PRIMARY KEY AUTOINCREMENT,
+			timestamp INTEGER NOT NULL,
+			height    INTEGER NOT NULL,
+			hash      TEXT    NOT NULL,
+			miner_id  TEXT    NOT NULL DEFAULT '',
+			worker    TEXT    NOT NULL DEFAULT '',
+			difficulty REAL   NOT NULL DEFAULT 0
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_blocks_timestamp ON blocks(timestamp);
+
+		CREATE TABLE IF NOT EXISTS hashrate_history (
+			id        INTEGER PRIMARY KEY AUTOINCREMENT,
+			timestamp INTEGER NOT NULL,
+			hashrate  REAL    NOT NULL
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_hashrate_timestamp ON hashrate_history(timestamp);
+
+		CREATE TABLE IF NOT EXISTS miner_sessions (
+			session_id      TEXT PRIMARY KEY,
+			worker          TEXT    NOT NULL DEFAULT '',
+			ip_address      TEXT    NOT NULL DEFAULT '',
+			connected_at    INTEGER NOT NULL,
+			disconnected_at INTEGER NOT NULL DEFAULT 0,
+			shares_accepted INTEGER NOT NULL DEFAULT 0,
+			shares_rejected INTEGER NOT NULL DEFAULT 0,
+			best_difficulty REAL    NOT NULL DEFAULT 0
+		);
+
+		CREATE TABLE IF NOT EXISTS cumulative_stats (
+			id              INTEGER PRIMARY KEY CHECK (id = 1),
+			total_accepted  INTEGER NOT NULL DEFAULT 0,
+			total_rejected  INTEGER NOT NULL DEFAULT 0,
+			best_difficulty REAL    NOT NULL DEFAULT 0,
+			blocks_found    INTEGER NOT NULL DEFAULT 0
+		);
+
+		INSERT OR IGNORE INTO cumulative_stats (id, total_accepted, total_rejected, best_difficulty, blocks_found)
+		VALUES (1, 0, 0, 0, 0);
+
+		CREATE TABLE IF NOT EXISTS worker_diffs (
+			worker     TEXT PRIMARY KEY,
+			difficulty REAL NOT NULL,
+			updated_at INTEGER NOT NULL
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	if err := addColumn(tx, "shares", "session_diff", "REAL NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+
+	// Composite index for per-miner hashrate history queries
+	_, err = tx.Exec(`CREATE INDEX IF NOT EXISTS idx_shares_miner_ts ON shares(miner_id, timestamp)`)
+	return err
+}
+
+func migrateRounds(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS rounds (
+			id            INTEGER PRIMARY KEY AUTOINCREMENT,
+			started_at    INTEGER NOT NULL,
+			ended_at      INTEGER NOT NULL DEFAULT 0,
+			end_reason    TEXT    NOT NULL DEFAULT '',
+			work          REAL    NOT NULL DEFAULT 0,
+			expected_work REAL    NOT NULL DEFAULT 0,
+			effort        REAL    NOT NULL DEFAULT 0,
+			shares        INTEGER NOT NULL DEFAULT 0,
+			best_share    REAL    NOT NULL DEFAULT 0,
+			best_worker   TEXT    NOT NULL DEFAULT '',
+			block_height  INTEGER NOT NULL DEFAULT 0,
+			block_hash    TEXT    NOT NULL DEFAULT ''
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_rounds_started ON rounds(started_at);
+
+		CREATE TABLE IF NOT EXISTS round_workers (
+			round_id   INTEGER NOT NULL,
+			worker     TEXT    NOT NULL,
+			work       REAL    NOT NULL DEFAULT 0,
+			shares     INTEGER NOT NULL DEFAULT 0,
+			best_share REAL    NOT NULL DEFAULT 0,
+			PRIMARY KEY (round_id, worker)
+		);
+	`)
+	return err
+}
+
+func migrateWorkers(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS workers (
+			id              TEXT PRIMARY KEY,
+			name            TEXT    NOT NULL DEFAULT '',
+			ip_address      TEXT    NOT NULL DEFAULT '',
+			mac_address     TEXT    NOT NULL DEFAULT '',
+			first_seen      INTEGER NOT NULL,
+			last_seen       INTEGER NOT NULL,
+			sessions        INTEGER NOT NULL DEFAULT 0,
+			shares_accepted INTEGER NOT NULL DEFAULT 0,
+			shares_rejected INTEGER NOT NULL DEFAULT 0,
+			best_difficulty REAL    NOT NULL DEFAULT 0,
+			total_work      REAL    NOT NULL DEFAULT 0
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Persistent worker identity for sessions, plus per-worker history index
+	if err := addColumn(tx, "miner_sessions", "worker_id", "TEXT NOT NULL DEFAULT ''"); err != nil {
+		return err
+	}
+	_, err = tx.Exec(`
+		CREATE INDEX IF NOT EXISTS idx_sessions_worker ON miner_sessions(worker_id, connected_at);
+		CREATE INDEX IF NOT EXISTS idx_shares_worker_ts ON shares(worker, timestamp);
+	`)
+	return err
+}
+
+func migrateShareRollups(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS share_rollups (
+			tier            TEXT    NOT NULL,
+			bucket          INTEGER NOT NULL,
+			worker          TEXT    NOT NULL DEFAULT '',
+			work            REAL    NOT NULL DEFAULT 0,
+			accepted        INTEGER NOT NULL DEFAULT 0,
+			rejected        INTEGER NOT NULL DEFAULT 0,
+			stale           INTEGER NOT NULL DEFAULT 0,
+			best_difficulty REAL    NOT NULL DEFAULT 0,
+			PRIMARY KEY (tier, bucket, worker)
+		);
+
+		CREATE TABLE IF NOT EXISTS rollup_state (
+			tier      TEXT PRIMARY KEY,
+			watermark INTEGER NOT NULL DEFAULT 0
+		);
+	`)
+	return err
+}
+
+func migrateNotificationLog(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS notification_log (
+			id           INTEGER PRIMARY KEY AUTOINCREMENT,
+			timestamp    INTEGER NOT NULL,
+			channel_id   TEXT    NOT NULL,
+			channel_name TEXT    NOT NULL DEFAULT '',
+			event        TEXT    NOT NULL,
+			title        TEXT    NOT NULL DEFAULT '',
+			status       TEXT    NOT NULL,
+			attempts     INTEGER NOT NULL DEFAULT 0,
+			error        TEXT    NOT NULL DEFAULT ''
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_notification_log_timestamp ON notification_log(timestamp);
+	`)
+	return err
+}
+
+func migrateRoster(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS roster (
+			worker_id        TEXT PRIMARY KEY,
+			label            TEXT    NOT NULL DEFAULT '',
+			nominal_hashrate REAL    NOT NULL DEFAULT 0,
+			added_at         INTEGER NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS roster_events (
+			id        INTEGER PRIMARY KEY AUTOINCREMENT,
+			timestamp INTEGER NOT NULL,
+			worker_id TEXT    NOT NULL,
+			kind      TEXT    NOT NULL,
+			detail    TEXT    NOT NULL DEFAULT ''
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_roster_events_worker ON roster_events(worker_id, timestamp);
+	`)
+	return err
+}
+
+func migrateDeviceTelemetry(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS device_telemetry (
+			id            INTEGER PRIMARY KEY AUTOINCREMENT,
+			timestamp     INTEGER NOT NULL,
+			ip            TEXT    NOT NULL,
+			hostname      TEXT    NOT NULL DEFAULT '',
+			temp          REAL    NOT NULL DEFAULT 0,
+			vr_temp       REAL    NOT NULL DEFAULT 0,
+			power         REAL    NOT NULL DEFAULT 0,
+			voltage       REAL    NOT NULL DEFAULT 0,
+			current       REAL    NOT NULL DEFAULT 0,
+			frequency     REAL    NOT NULL DEFAULT 0,
+			fan_speed     REAL    NOT NULL DEFAULT 0,
+			fan_rpm       REAL    NOT NULL DEFAULT 0,
+			hashrate      REAL    NOT NULL DEFAULT 0,
+			pool_hashrate REAL    NOT NULL DEFAULT 0,
+			best_diff     REAL    NOT NULL DEFAULT 0,
+			free_heap     INTEGER NOT NULL DEFAULT 0
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_telemetry_ip_ts ON device_telemetry(ip, timestamp);
+		CREATE INDEX IF NOT EXISTS idx_telemetry_ts    ON device_telemetry(timestamp);
+
+		CREATE TABLE IF NOT EXISTS device_alerts (
+			id        INTEGER PRIMARY KEY AUTOINCREMENT,
+			timestamp INTEGER NOT NULL,
+			ip        TEXT    NOT NULL,
+			metric    TEXT    NOT NULL,
+			value     REAL    NOT NULL DEFAULT 0,
+			threshold REAL    NOT NULL DEFAULT 0,
+			state     TEXT    NOT NULL, -- "raised" or "cleared"
+			detail    TEXT    NOT NULL DEFAULT ''
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_device_alerts_ts ON device_alerts(timestamp);
+	`)
+	return err
+}
+
+func migrateDeviceChanges(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS device_changes (
+			id             INTEGER PRIMARY KEY AUTOINCREMENT,
+			timestamp      INTEGER NOT NULL,
+			batch_id       TEXT    NOT NULL,
+			ip             TEXT    NOT NULL,
+			action         TEXT    NOT NULL, -- "apply", "rollback" or "restart"
+			before         TEXT    NOT NULL DEFAULT '{}',
+			after          TEXT    NOT NULL DEFAULT '{}',
+			status         TEXT    NOT NULL, -- "ok" or "failed"
+			error          TEXT    NOT NULL DEFAULT '',
+			rolled_back_by INTEGER NOT NULL DEFAULT 0
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_device_changes_ip    ON device_changes(ip, id);
+		CREATE INDEX IF NOT EXISTS idx_device_changes_batch ON device_changes(batch_id);
+	`)
+	return err
+}
+
+func migrateDiscoveredDevices(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS discovered_devices (
+			ip           TEXT PRIMARY KEY,
+			mac          TEXT    NOT NULL DEFAULT '',
+			hostname     TEXT    NOT NULL DEFAULT '',
+			model        TEXT    NOT NULL DEFAULT '',
+			firmware     TEXT    NOT NULL DEFAULT '',
+			driver       TEXT    NOT NULL DEFAULT '',
+			current_pool TEXT    NOT NULL DEFAULT '',
+			source       TEXT    NOT NULL DEFAULT '', -- "scan" or "mdns", last seen by
+			first_seen   INTEGER NOT NULL,
+			last_seen    INTEGER NOT NULL
+		);
+	`)
+	return err
+}
+
+func migrateGroupsAndTags(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS worker_groups (
+			id         INTEGER PRIMARY KEY AUTOINCREMENT,
+			name       TEXT    NOT NULL UNIQUE,
+			rules      TEXT    NOT NULL DEFAULT '[]', -- JSON array of {type, pattern}
+			created_at INTEGER NOT NULL
+		);
+
+		CREATE TABLE IF NOT EXISTS tags (
+			kind   TEXT NOT NULL, -- "worker", "device" or "ip"
+			target TEXT NOT NULL,
+			tag    TEXT NOT NULL,
+			PRIMARY KEY (kind, target, tag)
+		);
+	`)
+	return err
+}
+
+func migrateEnergyUsage(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS energy_usage (
+			bucket INTEGER NOT NULL, -- hour start (unix)
+			ip     TEXT    NOT NULL,
+			worker TEXT    NOT NULL DEFAULT '', -- '' = device with no connected worker
+			wh     REAL    NOT NULL DEFAULT 0,
+			cost   REAL    NOT NULL DEFAULT 0, -- priced at the tariff in force when metered
+			PRIMARY KEY (bucket, ip, worker)
+		);
+	`)
+	return err
+}
+
+func migrateScheduleActions(tx *sql.Tx) error {
+	_, err := tx.Exec(`
+		CREATE TABLE IF NOT EXISTS schedule_actions (
+			id        INTEGER PRIMARY KEY AUTOINCREMENT,
+			timestamp INTEGER NOT NULL,
+			rule_id   TEXT    NOT NULL,
+			rule_name TEXT    NOT NULL DEFAULT '',
+			action    TEXT    NOT NULL, -- withhold, reconnect, throttle, shutdown, override
+			phase     TEXT    NOT NULL, -- start, apply, stop, revert, set
+			target    TEXT    NOT NULL DEFAULT '', -- worker, session or IP acted on
+			status    TEXT    NOT NULL DEFAULT 'ok',
+			detail    TEXT    NOT NULL DEFAULT ''
+		);
+		CREATE INDEX IF NOT EXISTS idx_schedule_actions_rule ON schedule_actions(rule_id, timestamp);
+	`)
+	return err
+}
+
+// migrateShareTipAge records, for stale-block shares, how long after the
+// chain tip changed they were submitted.
+func migrateShareTipAge(tx *sql.Tx) error {
+	return addColumn(tx, "shares", "tip_age_ms", "INTEGER NOT NULL DEFAULT 0")
+}
+
+// migrateRejectTaxonomy adds a reject code next to the free-text reason,
+// which becomes its detail, plus share timing and session user agents for
+// reject analytics. Existing rejects are classified from their text.
+func migrateRejectTaxonomy(tx *sql.Tx) error {
+	for _, c := range []struct{ table, column, def string }{
+		{"shares", "reject_code", "TEXT NOT NULL DEFAULT ''"},
+		{"shares", "job_age_ms", "INTEGER NOT NULL DEFAULT 0"},
+		{"shares", "clean_age_ms", "INTEGER NOT NULL DEFAULT 0"},
+		{"miner_sessions", "user_agent", "TEXT NOT NULL DEFAULT ''"},
+	} {
+		if err := addColumn(tx, c.table, c.column, c.def); err != nil {
+			return err
+		}
+	}
+	_, err := tx.Exec(`
+		UPDATE shares SET reject_code = CASE
+			WHEN reject_reason LIKE 'job not found%' THEN 'stale-job'
+			WHEN reject_reason = 'stale block' THEN 'stale-block'
+			ELSE 'malformed' END
+		WHERE accepted = 0 AND reject_code = '';
+	`)
+	return err
+}
@@ -0,0 +1,149 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// createV1 writes a database at path as the first versioned release left
+// it, with one share in it.
+func createV1(t *testing.T, path string) {
+	t.Helper()
+	conn, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	tx, err := conn.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec(`CREATE TABLE schema_version (
+		version    INTEGER PRIMARY KEY,
+		name       TEXT    NOT NULL,
+		applied_at INTEGER NOT NULL
+	)`); err != nil {
+		t.Fatal(err)
+	}
+	if err := migrateBaseline(tx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_version (version, name, applied_at) VALUES (1, 'baseline', 0)`); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tx.Exec(`INSERT INTO shares (timestamp, miner_id, worker, difficulty) VALUES (1700000000, 's1', 'rig', 512)`); err != nil {
+		t.Fatal(err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func schemaVersionOf(t *testing.T, path string) int {
+	t.Helper()
+	conn, err := sql.Open("sqlite", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	var v int
+	if err := conn.QueryRow(`SELECT MAX(version) FROM schema_version`).Scan(&v); err != nil {
+		t.Fatal(err)
+	}
+	return v
+}
+
+func TestMigrateFromV1(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "govault.db")
+	createV1(t, path)
+
+	// Backups left by earlier upgrades, oldest first.
+	for i, name := range []string{"v0-20240101-000000", "v0-20240201-000000", "v0-20240301-000000"} {
+		old := path + "." + name + ".bak"
+		if err := os.WriteFile(old, nil, 0644); err != nil {
+			t.Fatal(err)
+		}
+		mod := time.Now().Add(-time.Duration(3-i) * time.Hour)
+		os.Chtimes(old, mod, mod)
+	}
+
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if v := db.SchemaVersion(); v != LatestSchemaVersion() {
+		t.Errorf("schema v%d after upgrade, want v%d", v, LatestSchemaVersion())
+	}
+	var shares int
+	if err := db.conn.QueryRow(`SELECT COUNT(*) FROM shares WHERE worker = 'rig'`).Scan(&shares); err != nil || shares != 1 {
+		t.Errorf("%d shares after upgrade (%v), want 1", shares, err)
+	}
+
+	backup := db.UpgradeBackup()
+	if backup == "" {
+		t.Fatal("no backup taken before the upgrade")
+	}
+	if v := schemaVersionOf(t, backup); v != 1 {
+		t.Errorf("backup is schema v%d, want v1", v)
+	}
+	backups, _ := filepath.Glob(path + ".v*.bak")
+	if len(backups) != keepUpgradeBackups {
+		t.Errorf("%d upgrade backups kept, want %d: %v", len(backups), keepUpgradeBackups, backups)
+	}
+	if _, err := os.Stat(path + ".v0-20240101-000000.bak"); !os.IsNotExist(err) {
+		t.Error("oldest upgrade backup not deleted")
+	}
+	if _, err := os.Stat(backup); err != nil {
+		t.Errorf("new backup deleted: %v", err)
+	}
+}
+
+func TestOpenUpToDate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "govault.db")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if db.UpgradeBackup() != "" {
+		t.Error("backup taken for a new database")
+	}
+	db.Close()
+
+	db, err = Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	if db.UpgradeBackup() != "" {
+		t.Error("backup taken for a database already up to date")
+	}
+}
+
+func TestOpenSchemaTooNew(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "govault.db")
+	db, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.conn.Exec(`INSERT INTO schema_version (version, name, applied_at) VALUES (?, 'future', 0)`,
+		LatestSchemaVersion()+1); err != nil {
+		t.Fatal(err)
+	}
+	db.Close()
+
+	if db, err := Open(path); !errors.Is(err, ErrSchemaTooNew) {
+		if db != nil {
+			db.Close()
+		}
+		t.Fatalf("Open = %v, want ErrSchemaTooNew", err)
+	}
+	if v := schemaVersionOf(t, path); v != LatestSchemaVersion()+1 {
+		t.Errorf("database changed to schema v%d", v)
+	}
+}
@@ -9,7 +9,9 @@ import (
 type MinerSessionEntry struct {
 	SessionID      string  `json:"sessionId"`
 	Worker         string  `json:"worker"`
+	WorkerID       string  `json:"workerId"`
 	IPAddress      string  `json:"ipAddress"`
+	UserAgent      string  `json:"userAgent"`
 	ConnectedAt    int64   `json:"connectedAt"`
 	DisconnectedAt int64   `json:"disconnectedAt"`
 	SharesAccepted int64   `json:"sharesAccepted"`
@@ -20,18 +22,25 @@ type MinerSessionEntry struct {
 // UpsertMinerSession inserts or updates a miner session.
 func (db *DB) UpsertMinerSession(s MinerSessionEntry) error {
 	_, err := db.conn.Exec(`INSERT INTO miner_sessions
-		(session_id, worker, ip_address, connected_at, disconnected_at, shares_accepted, shares_rejected, best_difficulty)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+		(session_id, worker, worker_id, ip_address, user_agent, connected_at, disconnected_at, shares_accepted, shares_rejected, best_difficulty)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
 		ON CONFLICT(session_id) DO UPDATE SET
 			disconnected_at = excluded.disconnected_at,
 			shares_accepted = excluded.shares_accepted,
 			shares_rejected = excluded.shares_rejected,
 			best_difficulty = excluded.best_difficulty`,
-		s.SessionID, s.Worker, s.IPAddress, s.ConnectedAt, s.DisconnectedAt,
+		s.SessionID, s.Worker, s.WorkerID, s.IPAddress, s.UserAgent, s.ConnectedAt, s.DisconnectedAt,
 		s.SharesAccepted, s.SharesRejected, s.BestDifficulty)
 	return err
 }
 
+// SetSessionWorker moves a session to another worker identity.
+func (db *DB) SetSessionWorker(sessionID, workerID string) error {
+	_, err := db.conn.Exec(`UPDATE miner_sessions SET worker_id = ? WHERE session_id = ?`,
+		workerID, sessionID)
+	return err
+}
+
 // DisconnectMiner marks a session as disconnected.
 func (db *DB) DisconnectMiner(sessionID string, disconnectedAt int64) error {
 	_, err := db.conn.Exec(`UPDATE miner_sessions SET disconnected_at = ? WHERE session_id = ?`,
@@ -41,7 +50,7 @@ func (db *DB) DisconnectMiner(sessionID string, disconnectedAt int64) error {
 
 // RecentSessions returns the most recent N miner sessions.
 func (db *DB) RecentSessions(limit int) ([]MinerSessionEntry, error) {
-	rows, err := db.conn.Query(`SELECT session_id, worker, ip_address, connected_at, disconnected_at,
+	rows, err := db.conn.Query(`SELECT session_id, worker, worker_id, ip_address, user_agent, connected_at, disconnected_at,
 		shares_accepted, shares_rejected, best_difficulty
 		FROM miner_sessions ORDER BY connected_at DESC LIMIT ?`, limit)
 	if err != nil {
@@ -52,7 +61,7 @@ func (db *DB) RecentSessions(limit int) ([]MinerSessionEntry, error) {
 	var result []MinerSessionEntry
 	for rows.Next() {
 		var s MinerSessionEntry
-		if err := rows.Scan(&s.SessionID, &s.Worker, &s.IPAddress, &s.ConnectedAt,
+		if err := rows.Scan(&s.SessionID, &s.Worker, &s.WorkerID, &s.IPAddress, &s.UserAgent, &s.ConnectedAt,
 			&s.DisconnectedAt, &s.SharesAccepted, &s.SharesRejected, &s.BestDifficulty); err != nil {
 			return nil, err
 		}
@@ -0,0 +1,56 @@
+package database
+
+import "time"
+
+// NotificationEntry records one notification delivery.
+type NotificationEntry struct {
+	ID          int64  `json:"id"`
+	Timestamp   int64  `json:"timestamp"`
+	ChannelID   string `json:"channelId"`
+	ChannelName string `json:"channelName"`
+	Event       string `json:"event"`
+	Title       string `json:"title"`
+	Status      string `json:"status"` // "sent" or "failed"
+	Attempts    int    `json:"attempts"`
+	Error       string `json:"error"`
+}
+
+// InsertNotification records a notification delivery.
+func (db *DB) InsertNotification(n NotificationEntry) error {
+	_, err := db.conn.Exec(`INSERT INTO notification_log
+		(timestamp, channel_id, channel_name, event, title, status, attempts, error)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		n.Timestamp, n.ChannelID, n.ChannelName, n.Event, n.Title, n.Status, n.Attempts, n.Error)
+	return err
+}
+
+// RecentNotifications returns the most recent N deliveries, newest first.
+func (db *DB) RecentNotifications(limit int) ([]NotificationEntry, error) {
+	rows, err := db.conn.Query(`SELECT id, timestamp, channel_id, channel_name, event, title, status, attempts, error
+		FROM notification_log ORDER BY id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []NotificationEntry
+	for rows.Next() {
+		var n NotificationEntry
+		if err := rows.Scan(&n.ID, &n.Timestamp, &n.ChannelID, &n.ChannelName, &n.Event, &n.Title,
+			&n.Status, &n.Attempts, &n.Error); err != nil {
+			return nil, err
+		}
+		result = append(result, n)
+	}
+	return result, rows.Err()
+}
+
+// PruneNotifications deletes notification log entries older than the given duration.
+func (db *DB) PruneNotifications(maxAge time.Duration) (int64, error) {
+	cutoff := time.Now().Add(-maxAge).Unix()
+	result, err := db.conn.Exec(`DELETE FROM notification_log WHERE timestamp < ?`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
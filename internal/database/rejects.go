@@ -0,0 +1,118 @@
+package database
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// RejectBucket holds one worker's or user agent's shares for one time bucket.
+type RejectBucket struct {
+	Bucket         int64              `json:"bucket"`         // bucket start (unix seconds)
+	Key            string             `json:"key"`            // worker ID or user agent
+	Shares         int64              `json:"shares"`         // accepted and rejected
+	Rejects        map[string]int64   `json:"rejects"`        // by reject code
+	JobAgeMs       float64            `json:"jobAgeMs"`       // average job age at submit
+	CleanAgeMs     float64            `json:"cleanAgeMs"`     // average time since the last clean job
+	RejectJobAgeMs map[string]float64 `json:"rejectJobAgeMs"` // average job age at submit, by reject code
+}
+
+// RejectAnalytics groups shares since a time into buckets per worker, or
+// per session user agent if byUserAgent, with reject counts by code and
+// average share timing. Pool rejects are split by the pool's error code,
+// as "upstream-reject:<code>". Only the raw shares table is read.
+func (db *DB) RejectAnalytics(since, bucketSec int64, byUserAgent bool) ([]RejectBucket, error) {
+	key, join := "s.worker", ""
+	if byUserAgent {
+		key, join = "COALESCE(m.user_agent, '')", "LEFT JOIN miner_sessions m ON m.session_id = s.miner_id"
+	}
+	rows, err := db.conn.Query(`SELECT (s.timestamp / ?) * ?, `+key+`, s.accepted, s.reject_code,
+			CASE WHEN s.reject_code = 'upstream-reject' THEN s.reject_reason ELSE '' END,
+			COUNT(*), SUM(s.job_age_ms), SUM(s.job_age_ms > 0), SUM(s.clean_age_ms), SUM(s.clean_age_ms > 0)
+		FROM shares s `+join+`
+		WHERE s.timestamp >= ?
+		GROUP BY 1, 2, 3, 4, 5`, bucketSec, bucketSec, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	type sums struct {
+		jobAge, jobAged, cleanAge, cleanAged int64
+		rejectAge, rejectAged                map[string]int64
+	}
+	type bucketKey struct {
+		bucket int64
+		key    string
+	}
+	buckets := make(map[bucketKey]*RejectBucket)
+	totals := make(map[bucketKey]*sums)
+	for rows.Next() {
+		var k bucketKey
+		var accepted int
+		var code, poolErr string
+		var n, jobAge, jobAged, cleanAge, cleanAged int64
+		if err := rows.Scan(&k.bucket, &k.key, &accepted, &code, &poolErr,
+			&n, &jobAge, &jobAged, &cleanAge, &cleanAged); err != nil {
+			return nil, err
+		}
+		b, ok := buckets[k]
+		if !ok {
+			b = &RejectBucket{Bucket: k.bucket, Key: k.key, Rejects: map[string]int64{}, RejectJobAgeMs: map[string]float64{}}
+			buckets[k] = b
+			totals[k] = &sums{rejectAge: map[string]int64{}, rejectAged: map[string]int64{}}
+		}
+		t := totals[k]
+		b.Shares += n
+		t.jobAge += jobAge
+		t.jobAged += jobAged
+		t.cleanAge += cleanAge
+		t.cleanAged += cleanAged
+		if accepted == 1 {
+			continue
+		}
+		if code == "upstream-reject" {
+			code += ":" + poolErrorCode(poolErr)
+		}
+		b.Rejects[code] += n
+		t.rejectAge[code] += jobAge
+		t.rejectAged[code] += jobAged
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	result := make([]RejectBucket, 0, len(buckets))
+	for k, b := range buckets {
+		t := totals[k]
+		if t.jobAged > 0 {
+			b.JobAgeMs = float64(t.jobAge) / float64(t.jobAged)
+		}
+		if t.cleanAged > 0 {
+			b.CleanAgeMs = float64(t.cleanAge) / float64(t.cleanAged)
+		}
+		for code, aged := range t.rejectAged {
+			if aged > 0 {
+				b.RejectJobAgeMs[code] = float64(t.rejectAge[code]) / float64(aged)
+			}
+		}
+		result = append(result, *b)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Bucket != result[j].Bucket {
+			return result[i].Bucket < result[j].Bucket
+		}
+		return result[i].Key < result[j].Key
+	})
+	return result, nil
+}
+
+// poolErrorCode returns the numeric code leading a pool reject detail, or
+// "other" if there is none (e.g. the upstream connection dropped).
+func poolErrorCode(detail string) string {
+	code, _, _ := strings.Cut(detail, " ")
+	if _, err := strconv.Atoi(code); err != nil {
+		return "other"
+	}
+	return code
+}
@@ -0,0 +1,198 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+)
+
+// RollupTier is one resolution of condensed share history. Each tier is
+// built from the tier below it (5m from raw shares, 1h from 5m, 1d from 1h),
+// so coarser tiers survive pruning of the finer ones.
+type RollupTier struct {
+	Name      string
+	BucketSec int64
+}
+
+// RollupTiers lists the rollup tiers from finest to coarsest.
+var RollupTiers = []RollupTier{
+	{Name: "5m", BucketSec: 300},
+	{Name: "1h", BucketSec: 3600},
+	{Name: "1d", BucketSec: 86400},
+}
+
+// RollupEntry holds one worker's aggregated shares for one bucket.
+type RollupEntry struct {
+	Tier           string  `json:"tier"`
+	Bucket         int64   `json:"bucket"` // bucket start (unix seconds)
+	Worker         string  `json:"worker"`
+	Work           float64 `json:"work"` // sum of session difficulty of accepted shares
+	Accepted       int64   `json:"accepted"`
+	Rejected       int64   `json:"rejected"` // excluding stale
+	Stale          int64   `json:"stale"`
+	BestDifficulty float64 `json:"bestDifficulty"`
+}
+
+// staleCodes lists the reject codes counted as stale rather than rejected.
+const staleCodes = "('stale-job', 'stale-block')"
+
+// RollupWatermark returns the end of the last fully rolled-up bucket of a
+// tier. Everything before it has been condensed into the tier.
+func (db *DB) RollupWatermark(tier string) (int64, error) {
+	var wm int64
+	err := db.conn.QueryRow(`SELECT watermark FROM rollup_state WHERE tier = ?`, tier).Scan(&wm)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	return wm, err
+}
+
+// RollupShares condenses source rows into the given tier for all complete
+// buckets before until, and advances the tier's watermark. The source is the
+// raw shares table for the first tier and the previous tier otherwise.
+// Returns the number of rollup rows written.
+func (db *DB) RollupShares(tierIdx int, until int64) (int64, error) {
+	if tierIdx < 0 || tierIdx >= len(RollupTiers) {
+		return 0, fmt.Errorf("unknown rollup tier: %d", tierIdx)
+	}
+	tier := RollupTiers[tierIdx]
+
+	from, err := db.RollupWatermark(tier.Name)
+	if err != nil {
+		return 0, err
+	}
+	end := (until / tier.BucketSec) * tier.BucketSec
+	if end <= from {
+		return 0, nil
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin tx: %w", err)
+	}
+
+	// Watermarks are bucket-aligned and keep source ranges disjoint; the
+	// upsert keeps rollups additive should a bucket ever be revisited.
+	var result sql.Result
+	if tierIdx == 0 {
+		result, err = tx.Exec(`INSERT INTO share_rollups
+			(tier, bucket, worker, work, accepted, rejected, stale, best_difficulty)
+			SELECT ?, (timestamp / ?) * ?, worker,
+				SUM(CASE WHEN accepted = 1 THEN session_diff ELSE 0 END),
+				SUM(CASE WHEN accepted = 1 THEN 1 ELSE 0 END),
+				SUM(CASE WHEN accepted = 0 AND reject_code NOT IN `+staleCodes+` THEN 1 ELSE 0 END),
+				SUM(CASE WHEN accepted = 0 AND reject_code IN `+staleCodes+` THEN 1 ELSE 0 END),
+				MAX(CASE WHEN accepted = 1 THEN difficulty ELSE 0 END)
+			FROM shares WHERE timestamp >= ? AND timestamp < ?
+			GROUP BY 2, worker
+			ON CONFLICT(tier, bucket, worker) DO UPDATE SET
+				work = work + excluded.work,
+				accepted = accepted + excluded.accepted,
+				rejected = rejected + excluded.rejected,
+				stale = stale + excluded.stale,
+				best_difficulty = MAX(best_difficulty, excluded.best_difficulty)`,
+			tier.Name, tier.BucketSec, tier.BucketSec, from, end)
+	} else {
+		result, err = tx.Exec(`INSERT INTO share_rollups
+			(tier, bucket, worker, work, accepted, rejected, stale, best_difficulty)
+			SELECT ?, (bucket / ?) * ?, worker,
+				SUM(work), SUM(accepted), SUM(rejected), SUM(stale), MAX(best_difficulty)
+			FROM share_rollups WHERE tier = ? AND bucket >= ? AND bucket < ?
+			GROUP BY 2, worker
+			ON CONFLICT(tier, bucket, worker) DO UPDATE SET
+				work = work + excluded.work,
+				accepted = accepted + excluded.accepted,
+				rejected = rejected + excluded.rejected,
+				stale = stale + excluded.stale,
+				best_difficulty = MAX(best_difficulty, excluded.best_difficulty)`,
+			tier.Name, tier.BucketSec, tier.BucketSec,
+			RollupTiers[tierIdx-1].Name, from, end)
+	}
+	if err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("rollup %s: %w", tier.Name, err)
+	}
+	n, _ := result.RowsAffected()
+
+	if _, err := tx.Exec(`INSERT INTO rollup_state (tier, watermark) VALUES (?, ?)
+		ON CONFLICT(tier) DO UPDATE SET watermark = excluded.watermark`, tier.Name, end); err != nil {
+		tx.Rollback()
+		return 0, fmt.Errorf("update watermark: %w", err)
+	}
+
+	return n, tx.Commit()
+}
+
+// PruneRollups deletes rollup rows of a tier with buckets before cutoff.
+// Rows not yet condensed into the next tier are kept.
+func (db *DB) PruneRollups(tierIdx int, cutoff int64) (int64, error) {
+	if tierIdx < 0 || tierIdx >= len(RollupTiers) {
+		return 0, fmt.Errorf("unknown rollup tier: %d", tierIdx)
+	}
+	if tierIdx+1 < len(RollupTiers) {
+		wm, err := db.RollupWatermark(RollupTiers[tierIdx+1].Name)
+		if err != nil {
+			return 0, err
+		}
+		if wm < cutoff {
+			cutoff = wm
+		}
+	}
+	result, err := db.conn.Exec(`DELETE FROM share_rollups WHERE tier = ? AND bucket < ?`,
+		RollupTiers[tierIdx].Name, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// RollupHashrateHistory computes hashrate over time buckets from a rollup
+// tier. bucketSec must be a multiple of the tier's bucket size. An empty
+// worker returns the hashrate of all workers combined.
+func (db *DB) RollupHashrateHistory(tier, worker string, since, bucketSec int64) ([]HashrateEntry, error) {
+	rows, err := db.conn.Query(
+		`SELECT (bucket / ?) * ? AS b, SUM(work) FROM share_rollups
+		 WHERE tier = ? AND bucket >= ? AND (? = '' OR worker = ?)
+		 GROUP BY b ORDER BY b`, bucketSec, bucketSec, tier, since, worker, worker)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []HashrateEntry
+	for rows.Next() {
+		var bucket int64
+		var work float64
+		if err := rows.Scan(&bucket, &work); err != nil {
+			return nil, err
+		}
+		result = append(result, HashrateEntry{
+			Timestamp: bucket,
+			Hashrate:  work * math.Pow(2, 32) / float64(bucketSec),
+		})
+	}
+	return result, rows.Err()
+}
+
+// Rollups returns rollup rows of a tier since the given time, optionally
+// filtered by worker.
+func (db *DB) Rollups(tier, worker string, since int64) ([]RollupEntry, error) {
+	rows, err := db.conn.Query(`SELECT tier, bucket, worker, work, accepted, rejected, stale, best_difficulty
+		FROM share_rollups WHERE tier = ? AND bucket >= ? AND (? = '' OR worker = ?)
+		ORDER BY bucket, worker`, tier, since, worker, worker)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []RollupEntry
+	for rows.Next() {
+		var r RollupEntry
+		if err := rows.Scan(&r.Tier, &r.Bucket, &r.Worker, &r.Work, &r.Accepted, &r.Rejected,
+			&r.Stale, &r.BestDifficulty); err != nil {
+			return nil, err
+		}
+		result = append(result, r)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,81 @@
+package database
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRollupShares(t *testing.T) {
+	db := openTestDB(t)
+	const base = 1700000400 // 5m-aligned
+	db.InsertShares([]ShareEntry{
+		{Timestamp: base + 10, Worker: "rig1", Difficulty: 900, SessionDiff: 512, Accepted: true},
+		{Timestamp: base + 20, Worker: "rig1", Difficulty: 3000, SessionDiff: 512, Accepted: true},
+		{Timestamp: base + 30, Worker: "rig1", SessionDiff: 512, RejectCode: "stale-job"},
+		{Timestamp: base + 40, Worker: "rig1", SessionDiff: 512, RejectCode: "stale-block"},
+		{Timestamp: base + 50, Worker: "rig1", SessionDiff: 512, RejectCode: "duplicate"},
+		{Timestamp: base + 60, Worker: "rig2", Difficulty: 300, SessionDiff: 256, Accepted: true},
+		{Timestamp: base + 310, Worker: "rig1", Difficulty: 600, SessionDiff: 512, Accepted: true},
+		{Timestamp: base + 610, Worker: "rig1", Difficulty: 600, SessionDiff: 512, Accepted: true}, // bucket not complete
+	})
+
+	n, err := db.RollupShares(0, base+610)
+	if err != nil || n != 3 {
+		t.Fatalf("RollupShares = %d, %v; want 3 rows", n, err)
+	}
+	rows, _ := db.Rollups("5m", "rig1", 0)
+	if len(rows) != 2 {
+		t.Fatalf("rig1 rollups %+v, want two buckets", rows)
+	}
+	first := rows[0]
+	if first.Bucket != base || first.Work != 1024 || first.Accepted != 2 || first.Rejected != 1 || first.Stale != 2 || first.BestDifficulty != 3000 {
+		t.Errorf("first bucket %+v", first)
+	}
+	if wm, _ := db.RollupWatermark("5m"); wm != base+600 {
+		t.Errorf("watermark %d, want %d", wm, base+600)
+	}
+	if n, _ := db.RollupShares(0, base+610); n != 0 {
+		t.Errorf("rolling up again wrote %d rows", n)
+	}
+
+	// The hour tier is built from the 5m tier.
+	hour := int64(base/3600+1) * 3600
+	if _, err := db.RollupShares(1, hour); err != nil {
+		t.Fatal(err)
+	}
+	hours, _ := db.Rollups("1h", "", 0)
+	var work float64
+	for _, r := range hours {
+		work += r.Work
+	}
+	if work != 512*3+256 {
+		t.Errorf("1h work %v, want the 5m work condensed", work)
+	}
+
+	history, err := db.RollupHashrateHistory("5m", "rig2", 0, 300)
+	if err != nil || len(history) != 1 || math.Abs(history[0].Hashrate-256*math.Pow(2, 32)/300) > 1 {
+		t.Errorf("rig2 history %+v, %v", history, err)
+	}
+}
+
+func TestPruneRollupsKeepsUncondensed(t *testing.T) {
+	db := openTestDB(t)
+	const base = 1700002800 // 5m- and 1h-aligned
+	db.InsertShares([]ShareEntry{
+		{Timestamp: base + 10, Worker: "rig1", Difficulty: 1, SessionDiff: 1, Accepted: true},
+		{Timestamp: base + 3610, Worker: "rig1", Difficulty: 1, SessionDiff: 1, Accepted: true},
+	})
+	db.RollupShares(0, base+7200)
+	db.RollupShares(1, base+3600) // only the first hour condensed
+
+	n, err := db.PruneRollups(0, base+7200)
+	if err != nil || n != 1 {
+		t.Fatalf("PruneRollups = %d, %v; want only the condensed row", n, err)
+	}
+	if rows, _ := db.Rollups("5m", "", 0); len(rows) != 1 || rows[0].Bucket != base+3600 {
+		t.Errorf("5m rows left %+v, want the hour not yet condensed", rows)
+	}
+	if _, err := db.RollupShares(len(RollupTiers), base); err == nil {
+		t.Error("unknown tier accepted")
+	}
+}
@@ -0,0 +1,83 @@
+package database
+
+// RosterEntry is an expected worker with its nominal hashrate.
+type RosterEntry struct {
+	WorkerID        string  `json:"workerId"`
+	Label           string  `json:"label"`
+	NominalHashrate float64 `json:"nominalHashrate"` // H/s
+	AddedAt         int64   `json:"addedAt"`
+}
+
+// RosterEventEntry records a change in a roster worker's condition.
+type RosterEventEntry struct {
+	ID        int64  `json:"id"`
+	Timestamp int64  `json:"timestamp"`
+	WorkerID  string `json:"workerId"`
+	Kind      string `json:"kind"`
+	Detail    string `json:"detail"`
+}
+
+// UpsertRosterEntry adds a worker to the roster or updates it.
+func (db *DB) UpsertRosterEntry(e RosterEntry) error {
+	_, err := db.conn.Exec(`INSERT INTO roster (worker_id, label, nominal_hashrate, added_at)
+		VALUES (?, ?, ?, ?)
+		ON CONFLICT(worker_id) DO UPDATE SET
+			label = excluded.label,
+			nominal_hashrate = excluded.nominal_hashrate`,
+		e.WorkerID, e.Label, e.NominalHashrate, e.AddedAt)
+	return err
+}
+
+// DeleteRosterEntry removes a worker from the roster.
+func (db *DB) DeleteRosterEntry(workerID string) error {
+	_, err := db.conn.Exec(`DELETE FROM roster WHERE worker_id = ?`, workerID)
+	return err
+}
+
+// ListRoster returns all roster entries.
+func (db *DB) ListRoster() ([]RosterEntry, error) {
+	rows, err := db.conn.Query(`SELECT worker_id, label, nominal_hashrate, added_at
+		FROM roster ORDER BY label, worker_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []RosterEntry
+	for rows.Next() {
+		var e RosterEntry
+		if err := rows.Scan(&e.WorkerID, &e.Label, &e.NominalHashrate, &e.AddedAt); err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, rows.Err()
+}
+
+// InsertRosterEvent records a roster condition change.
+func (db *DB) InsertRosterEvent(e RosterEventEntry) error {
+	_, err := db.conn.Exec(`INSERT INTO roster_events (timestamp, worker_id, kind, detail) VALUES (?, ?, ?, ?)`,
+		e.Timestamp, e.WorkerID, e.Kind, e.Detail)
+	return err
+}
+
+// RecentRosterEvents returns the most recent N roster events, newest first.
+// An empty workerID returns events for all workers.
+func (db *DB) RecentRosterEvents(workerID string, limit int) ([]RosterEventEntry, error) {
+	rows, err := db.conn.Query(`SELECT id, timestamp, worker_id, kind, detail FROM roster_events
+		WHERE ? = '' OR worker_id = ? ORDER BY id DESC LIMIT ?`, workerID, workerID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []RosterEventEntry
+	for rows.Next() {
+		var e RosterEventEntry
+		if err := rows.Scan(&e.ID, &e.Timestamp, &e.WorkerID, &e.Kind, &e.Detail); err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,149 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+)
+
+// RoundEntry represents a mining round — all work done between two found
+// blocks (or round epoch boundaries).
+type RoundEntry struct {
+	ID           int64   `json:"id"`
+	StartedAt    int64   `json:"startedAt"`
+	EndedAt      int64   `json:"endedAt"`      // 0 while the round is open
+	EndReason    string  `json:"endReason"`    // "block", "epoch", or "" while open
+	Work         float64 `json:"work"`         // sum of difficulty-weighted shares
+	ExpectedWork float64 `json:"expectedWork"` // network difficulty at last update
+	Effort       float64 `json:"effort"`       // percent of expected work done
+	Shares       uint64  `json:"shares"`
+	BestShare    float64 `json:"bestShare"`
+	BestWorker   string  `json:"bestWorker"`
+	BlockHeight  int64   `json:"blockHeight"`
+	BlockHash    string  `json:"blockHash"`
+}
+
+// RoundWorkerEntry holds one worker's contribution to a round.
+type RoundWorkerEntry struct {
+	RoundID   int64   `json:"roundId"`
+	Worker    string  `json:"worker"`
+	Work      float64 `json:"work"`
+	Shares    uint64  `json:"shares"`
+	BestShare float64 `json:"bestShare"`
+}
+
+// InsertRound starts a new round and returns its ID.
+func (db *DB) InsertRound(r RoundEntry) (int64, error) {
+	result, err := db.conn.Exec(`INSERT INTO rounds
+		(started_at, ended_at, end_reason, work, expected_work, effort, shares, best_share, best_worker, block_height, block_hash)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		r.StartedAt, r.EndedAt, r.EndReason, r.Work, r.ExpectedWork, r.Effort,
+		r.Shares, r.BestShare, r.BestWorker, r.BlockHeight, r.BlockHash)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// UpdateRound writes the current totals of a round.
+func (db *DB) UpdateRound(r RoundEntry) error {
+	_, err := db.conn.Exec(`UPDATE rounds SET
+		ended_at = ?, end_reason = ?, work = ?, expected_work = ?, effort = ?, shares = ?,
+		best_share = ?, best_worker = ?, block_height = ?, block_hash = ?
+		WHERE id = ?`,
+		r.EndedAt, r.EndReason, r.Work, r.ExpectedWork, r.Effort, r.Shares,
+		r.BestShare, r.BestWorker, r.BlockHeight, r.BlockHash, r.ID)
+	return err
+}
+
+// OpenRound returns the most recent round that has not ended yet.
+// Returns nil if there is no open round.
+func (db *DB) OpenRound() (*RoundEntry, error) {
+	var r RoundEntry
+	err := db.conn.QueryRow(`SELECT id, started_at, ended_at, end_reason, work, expected_work, effort,
+		shares, best_share, best_worker, block_height, block_hash
+		FROM rounds WHERE ended_at = 0 ORDER BY id DESC LIMIT 1`).
+		Scan(&r.ID, &r.StartedAt, &r.EndedAt, &r.EndReason, &r.Work, &r.ExpectedWork, &r.Effort,
+			&r.Shares, &r.BestShare, &r.BestWorker, &r.BlockHeight, &r.BlockHash)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &r, nil
+}
+
+// RecentRounds returns the most recent N rounds, newest first.
+func (db *DB) RecentRounds(limit int) ([]RoundEntry, error) {
+	rows, err := db.conn.Query(`SELECT id, started_at, ended_at, end_reason, work, expected_work, effort,
+		shares, best_share, best_worker, block_height, block_hash
+		FROM rounds ORDER BY id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []RoundEntry
+	for rows.Next() {
+		var r RoundEntry
+		if err := rows.Scan(&r.ID, &r.StartedAt, &r.EndedAt, &r.EndReason, &r.Work, &r.ExpectedWork, &r.Effort,
+			&r.Shares, &r.BestShare, &r.BestWorker, &r.BlockHeight, &r.BlockHash); err != nil {
+			return nil, err
+		}
+		result = append(result, r)
+	}
+	return result, rows.Err()
+}
+
+// SaveRoundWorkers upserts the per-worker totals of a round.
+func (db *DB) SaveRoundWorkers(workers []RoundWorkerEntry) error {
+	if len(workers) == 0 {
+		return nil
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO round_workers (round_id, worker, work, shares, best_share)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(round_id, worker) DO UPDATE SET
+			work = excluded.work,
+			shares = excluded.shares,
+			best_share = excluded.best_share`)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("prepare: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, w := range workers {
+		if _, err := stmt.Exec(w.RoundID, w.Worker, w.Work, w.Shares, w.BestShare); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("exec: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// RoundWorkers returns the per-worker totals for a round, largest contributor first.
+func (db *DB) RoundWorkers(roundID int64) ([]RoundWorkerEntry, error) {
+	rows, err := db.conn.Query(`SELECT round_id, worker, work, shares, best_share
+		FROM round_workers WHERE round_id = ? ORDER BY work DESC`, roundID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []RoundWorkerEntry
+	for rows.Next() {
+		var w RoundWorkerEntry
+		if err := rows.Scan(&w.RoundID, &w.Worker, &w.Work, &w.Shares, &w.BestShare); err != nil {
+			return nil, err
+		}
+		result = append(result, w)
+	}
+	return result, rows.Err()
+}
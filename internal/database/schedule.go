@@ -0,0 +1,51 @@
+package database
+
+// ScheduleActionEntry records one thing the scheduler did.
+type ScheduleActionEntry struct {
+	ID        int64  `json:"id"`
+	Timestamp int64  `json:"timestamp"`
+	RuleID    string `json:"ruleId"`
+	RuleName  string `json:"ruleName"`
+	Action    string `json:"action"` // "withhold", "reconnect", "throttle", "shutdown" or "override"
+	Phase     string `json:"phase"`  // "start", "apply", "stop", "revert" or "set" (override)
+	Target    string `json:"target"`
+	Status    string `json:"status"` // "ok" or "failed"
+	Detail    string `json:"detail"`
+}
+
+// InsertScheduleAction records a scheduler action.
+func (db *DB) InsertScheduleAction(e ScheduleActionEntry) error {
+	_, err := db.conn.Exec(`INSERT INTO schedule_actions (timestamp, rule_id, rule_name, action, phase, target, status, detail)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
+		e.Timestamp, e.RuleID, e.RuleName, e.Action, e.Phase, e.Target, e.Status, e.Detail)
+	return err
+}
+
+// RecentScheduleActions returns the most recent N actions, newest first,
+// optionally for one rule.
+func (db *DB) RecentScheduleActions(ruleID string, limit int) ([]ScheduleActionEntry, error) {
+	query := `SELECT id, timestamp, rule_id, rule_name, action, phase, target, status, detail FROM schedule_actions`
+	var args []interface{}
+	if ruleID != "" {
+		query += ` WHERE rule_id = ?`
+		args = append(args, ruleID)
+	}
+	query += ` ORDER BY id DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []ScheduleActionEntry
+	for rows.Next() {
+		var e ScheduleActionEntry
+		if err := rows.Scan(&e.ID, &e.Timestamp, &e.RuleID, &e.RuleName, &e.Action, &e.Phase, &e.Target, &e.Status, &e.Detail); err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, rows.Err()
+}
@@ -18,8 +18,9 @@ func (db *DB) InsertShares(shares []ShareEntry) error {
 		return fmt.Errorf("begin tx: %w", err)
 	}
 
-	stmt, err := tx.Prepare(`INSERT INTO shares (timestamp, miner_id, worker, difficulty, accepted, reject_reason, session_diff)
-		VALUES (?, ?, ?, ?, ?, ?, ?)`)
+	stmt, err := tx.Prepare(`INSERT INTO shares (timestamp, miner_id, worker, difficulty, accepted, reject_code, reject_reason,
+		session_diff, job_age_ms, clean_age_ms, tip_age_ms)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
 	if err != nil {
 		tx.Rollback()
 		return fmt.Errorf("prepare: %w", err)
@@ -31,12 +32,18 @@ func (db *DB) InsertShares(shares []ShareEntry) error {
 		if s.Accepted {
 			accepted = 1
 		}
-		if _, err := stmt.Exec(s.Timestamp, s.MinerID, s.Worker, s.Difficulty, accepted, s.RejectReason, s.SessionDiff); err != nil {
+		if _, err := stmt.Exec(s.Timestamp, s.MinerID, s.Worker, s.Difficulty, accepted, s.RejectCode, s.RejectReason,
+			s.SessionDiff, s.JobAgeMs, s.CleanAgeMs, s.TipAgeMs); err != nil {
 			tx.Rollback()
 			return fmt.Errorf("exec: %w", err)
 		}
 	}
 
+	if err := updateWorkerTotals(tx, shares); err != nil {
+		tx.Rollback()
+		return fmt.Errorf("update workers: %w", err)
+	}
+
 	return tx.Commit()
 }
 
@@ -52,7 +59,7 @@ func (db *DB) ShareCount() (accepted, rejected uint64, err error) {
 
 // RecentShares returns the most recent N shares.
 func (db *DB) RecentShares(limit int) ([]ShareEntry, error) {
-	rows, err := db.conn.Query(`SELECT timestamp, miner_id, worker, difficulty, accepted, reject_reason
+	rows, err := db.conn.Query(`SELECT timestamp, miner_id, worker, difficulty, accepted, reject_code, reject_reason
 		FROM shares ORDER BY id DESC LIMIT ?`, limit)
 	if err != nil {
 		return nil, err
@@ -63,7 +70,7 @@ func (db *DB) RecentShares(limit int) ([]ShareEntry, error) {
 	for rows.Next() {
 		var s ShareEntry
 		var acc int
-		if err := rows.Scan(&s.Timestamp, &s.MinerID, &s.Worker, &s.Difficulty, &acc, &s.RejectReason); err != nil {
+		if err := rows.Scan(&s.Timestamp, &s.MinerID, &s.Worker, &s.Difficulty, &acc, &s.RejectCode, &s.RejectReason); err != nil {
 			return nil, err
 		}
 		s.Accepted = acc == 1
@@ -72,9 +79,17 @@ func (db *DB) RecentShares(limit int) ([]ShareEntry, error) {
 	return result, rows.Err()
 }
 
-// PruneShares deletes shares older than the given duration.
+// PruneShares deletes shares older than the given duration. Shares not yet
+// condensed into the 5-minute rollup tier are kept.
 func (db *DB) PruneShares(maxAge time.Duration) (int64, error) {
 	cutoff := time.Now().Add(-maxAge).Unix()
+	wm, err := db.RollupWatermark(RollupTiers[0].Name)
+	if err != nil {
+		return 0, err
+	}
+	if wm < cutoff {
+		cutoff = wm
+	}
 	result, err := db.conn.Exec(`DELETE FROM shares WHERE timestamp < ?`, cutoff)
 	if err != nil {
 		return 0, err
@@ -0,0 +1,129 @@
+package database
+
+import (
+	"fmt"
+	"time"
+)
+
+// TelemetryEntry is one stored device telemetry reading.
+type TelemetryEntry struct {
+	Timestamp    int64   `json:"timestamp"`
+	IP           string  `json:"ip"`
+	Hostname     string  `json:"hostname"`
+	Temp         float64 `json:"temp"`
+	VRTemp       float64 `json:"vrTemp"`
+	Power        float64 `json:"power"`
+	Voltage      float64 `json:"voltage"`
+	Current      float64 `json:"current"`
+	Frequency    float64 `json:"frequency"`
+	FanSpeed     float64 `json:"fanSpeed"`
+	FanRPM       float64 `json:"fanRpm"`
+	Hashrate     float64 `json:"hashrate"`
+	PoolHashrate float64 `json:"poolHashrate"`
+	BestDiff     float64 `json:"bestDiff"`
+	FreeHeap     int     `json:"freeHeap"`
+}
+
+// DeviceAlertEntry records a device threshold alert being raised or cleared.
+type DeviceAlertEntry struct {
+	ID        int64   `json:"id"`
+	Timestamp int64   `json:"timestamp"`
+	IP        string  `json:"ip"`
+	Metric    string  `json:"metric"`
+	Value     float64 `json:"value"`
+	Threshold float64 `json:"threshold"`
+	State     string  `json:"state"` // "raised" or "cleared"
+	Detail    string  `json:"detail"`
+}
+
+const telemetryColumns = `timestamp, ip, hostname, temp, vr_temp, power, voltage, current, frequency,
+	fan_speed, fan_rpm, hashrate, pool_hashrate, best_diff, free_heap`
+
+// InsertTelemetry batch-inserts telemetry readings.
+func (db *DB) InsertTelemetry(entries []TelemetryEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return fmt.Errorf("begin tx: %w", err)
+	}
+
+	stmt, err := tx.Prepare(`INSERT INTO device_telemetry (` + telemetryColumns + `)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`)
+	if err != nil {
+		tx.Rollback()
+		return fmt.Errorf("prepare: %w", err)
+	}
+	defer stmt.Close()
+
+	for _, e := range entries {
+		if _, err := stmt.Exec(e.Timestamp, e.IP, e.Hostname, e.Temp, e.VRTemp, e.Power, e.Voltage, e.Current,
+			e.Frequency, e.FanSpeed, e.FanRPM, e.Hashrate, e.PoolHashrate, e.BestDiff, e.FreeHeap); err != nil {
+			tx.Rollback()
+			return fmt.Errorf("exec: %w", err)
+		}
+	}
+
+	return tx.Commit()
+}
+
+// TelemetryHistory returns a device's readings since the given time, oldest first.
+func (db *DB) TelemetryHistory(ip string, since int64) ([]TelemetryEntry, error) {
+	rows, err := db.conn.Query(`SELECT `+telemetryColumns+` FROM device_telemetry
+		WHERE ip = ? AND timestamp >= ? ORDER BY timestamp`, ip, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []TelemetryEntry
+	for rows.Next() {
+		var e TelemetryEntry
+		if err := rows.Scan(&e.Timestamp, &e.IP, &e.Hostname, &e.Temp, &e.VRTemp, &e.Power, &e.Voltage, &e.Current,
+			&e.Frequency, &e.FanSpeed, &e.FanRPM, &e.Hashrate, &e.PoolHashrate, &e.BestDiff, &e.FreeHeap); err != nil {
+			return nil, err
+		}
+		result = append(result, e)
+	}
+	return result, rows.Err()
+}
+
+// PruneTelemetry deletes telemetry readings older than the given duration.
+func (db *DB) PruneTelemetry(maxAge time.Duration) (int64, error) {
+	cutoff := time.Now().Add(-maxAge).Unix()
+	result, err := db.conn.Exec(`DELETE FROM device_telemetry WHERE timestamp < ?`, cutoff)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+// InsertDeviceAlert records a device alert state change.
+func (db *DB) InsertDeviceAlert(a DeviceAlertEntry) error {
+	_, err := db.conn.Exec(`INSERT INTO device_alerts (timestamp, ip, metric, value, threshold, state, detail)
+		VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		a.Timestamp, a.IP, a.Metric, a.Value, a.Threshold, a.State, a.Detail)
+	return err
+}
+
+// RecentDeviceAlerts returns the most recent N device alerts, newest first.
+func (db *DB) RecentDeviceAlerts(limit int) ([]DeviceAlertEntry, error) {
+	rows, err := db.conn.Query(`SELECT id, timestamp, ip, metric, value, threshold, state, detail
+		FROM device_alerts ORDER BY id DESC LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []DeviceAlertEntry
+	for rows.Next() {
+		var a DeviceAlertEntry
+		if err := rows.Scan(&a.ID, &a.Timestamp, &a.IP, &a.Metric, &a.Value, &a.Threshold, &a.State, &a.Detail); err != nil {
+			return nil, err
+		}
+		result = append(result, a)
+	}
+	return result, rows.Err()
+}
@@ -0,0 +1,186 @@
+package database
+
+import (
+	"database/sql"
+	"math"
+)
+
+// WorkerEntry is a persistent worker identity that aggregates all sessions
+// of one device across reconnects and restarts.
+type WorkerEntry struct {
+	ID             string  `json:"id"` // identity key (worker name, optionally with IP or MAC)
+	Name           string  `json:"name"`
+	IPAddress      string  `json:"ipAddress"`
+	MACAddress     string  `json:"macAddress"`
+	FirstSeen      int64   `json:"firstSeen"`
+	LastSeen       int64   `json:"lastSeen"`
+	Sessions       int64   `json:"sessions"`
+	SharesAccepted int64   `json:"sharesAccepted"`
+	SharesRejected int64   `json:"sharesRejected"`
+	BestDifficulty float64 `json:"bestDifficulty"`
+	TotalWork      float64 `json:"totalWork"` // sum of difficulty-weighted shares
+}
+
+// UpsertWorker records a new session for a worker, creating it if needed.
+func (db *DB) UpsertWorker(id, name, ip, mac string, seenAt int64) error {
+	_, err := db.conn.Exec(`INSERT INTO workers
+		(id, name, ip_address, mac_address, first_seen, last_seen, sessions)
+		VALUES (?, ?, ?, ?, ?, ?, 1)
+		ON CONFLICT(id) DO UPDATE SET
+			name = excluded.name,
+			ip_address = excluded.ip_address,
+			mac_address = CASE WHEN excluded.mac_address != '' THEN excluded.mac_address ELSE workers.mac_address END,
+			last_seen = excluded.last_seen,
+			sessions = workers.sessions + 1`,
+		id, name, ip, mac, seenAt, seenAt)
+	return err
+}
+
+// TouchWorker updates a worker's last-seen time.
+func (db *DB) TouchWorker(id string, seenAt int64) error {
+	_, err := db.conn.Exec(`UPDATE workers SET last_seen = MAX(last_seen, ?) WHERE id = ?`, seenAt, id)
+	return err
+}
+
+// ListWorkers returns all known workers, most recently seen first.
+func (db *DB) ListWorkers() ([]WorkerEntry, error) {
+	rows, err := db.conn.Query(`SELECT id, name, ip_address, mac_address, first_seen, last_seen, sessions,
+		shares_accepted, shares_rejected, best_difficulty, total_work
+		FROM workers ORDER BY last_seen DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []WorkerEntry
+	for rows.Next() {
+		var w WorkerEntry
+		if err := rows.Scan(&w.ID, &w.Name, &w.IPAddress, &w.MACAddress, &w.FirstSeen, &w.LastSeen, &w.Sessions,
+			&w.SharesAccepted, &w.SharesRejected, &w.BestDifficulty, &w.TotalWork); err != nil {
+			return nil, err
+		}
+		result = append(result, w)
+	}
+	return result, rows.Err()
+}
+
+// GetWorker returns a single worker, or nil if it is unknown.
+func (db *DB) GetWorker(id string) (*WorkerEntry, error) {
+	var w WorkerEntry
+	err := db.conn.QueryRow(`SELECT id, name, ip_address, mac_address, first_seen, last_seen, sessions,
+		shares_accepted, shares_rejected, best_difficulty, total_work
+		FROM workers WHERE id = ?`, id).
+		Scan(&w.ID, &w.Name, &w.IPAddress, &w.MACAddress, &w.FirstSeen, &w.LastSeen, &w.Sessions,
+			&w.SharesAccepted, &w.SharesRejected, &w.BestDifficulty, &w.TotalWork)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// WorkerSessions returns the most recent N sessions of a worker.
+func (db *DB) WorkerSessions(workerID string, limit int) ([]MinerSessionEntry, error) {
+	rows, err := db.conn.Query(`SELECT session_id, worker, worker_id, ip_address, user_agent, connected_at, disconnected_at,
+		shares_accepted, shares_rejected, best_difficulty
+		FROM miner_sessions WHERE worker_id = ? ORDER BY connected_at DESC LIMIT ?`, workerID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []MinerSessionEntry
+	for rows.Next() {
+		var s MinerSessionEntry
+		if err := rows.Scan(&s.SessionID, &s.Worker, &s.WorkerID, &s.IPAddress, &s.UserAgent, &s.ConnectedAt,
+			&s.DisconnectedAt, &s.SharesAccepted, &s.SharesRejected, &s.BestDifficulty); err != nil {
+			return nil, err
+		}
+		result = append(result, s)
+	}
+	return result, rows.Err()
+}
+
+// WorkerHashrateHistory computes a worker's hashrate over time buckets from
+// the shares table, across all of its sessions.
+func (db *DB) WorkerHashrateHistory(workerID string, since int64, bucketSec int64) ([]HashrateEntry, error) {
+	rows, err := db.conn.Query(
+		`SELECT (timestamp / ?) * ? AS bucket, SUM(session_diff) FROM shares
+		 WHERE worker = ? AND timestamp >= ? AND accepted = 1 AND session_diff > 0
+		 GROUP BY bucket ORDER BY bucket`, bucketSec, bucketSec, workerID, since)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []HashrateEntry
+	for rows.Next() {
+		var bucket int64
+		var sumDiff float64
+		if err := rows.Scan(&bucket, &sumDiff); err != nil {
+			return nil, err
+		}
+		result = append(result, HashrateEntry{
+			Timestamp: bucket,
+			Hashrate:  sumDiff * math.Pow(2, 32) / float64(bucketSec),
+		})
+	}
+	return result, rows.Err()
+}
+
+// updateWorkerTotals folds a batch of shares into the per-worker counters.
+// Called inside the share insert transaction so the two never diverge.
+func updateWorkerTotals(tx *sql.Tx, shares []ShareEntry) error {
+	type totals struct {
+		accepted, rejected int64
+		best, work         float64
+		lastSeen           int64
+	}
+	byWorker := make(map[string]*totals)
+	for _, s := range shares {
+		if s.Worker == "" {
+			continue
+		}
+		t, ok := byWorker[s.Worker]
+		if !ok {
+			t = &totals{}
+			byWorker[s.Worker] = t
+		}
+		if s.Accepted {
+			t.accepted++
+			t.work += s.SessionDiff
+			if s.Difficulty > t.best {
+				t.best = s.Difficulty
+			}
+		} else {
+			t.rejected++
+		}
+		if s.Timestamp > t.lastSeen {
+			t.lastSeen = s.Timestamp
+		}
+	}
+	if len(byWorker) == 0 {
+		return nil
+	}
+
+	stmt, err := tx.Prepare(`UPDATE workers SET
+		shares_accepted = shares_accepted + ?,
+		shares_rejected = shares_rejected + ?,
+		best_difficulty = MAX(best_difficulty, ?),
+		total_work = total_work + ?,
+		last_seen = MAX(last_seen, ?)
+		WHERE id = ?`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for id, t := range byWorker {
+		if _, err := stmt.Exec(t.accepted, t.rejected, t.best, t.work, t.lastSeen, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
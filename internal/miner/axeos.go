@@ -0,0 +1,439 @@
+package miner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// axeOSSystemInfo maps the AxeOS /api/system/info response.
+type axeOSSystemInfo struct {
+	Power        float64     `json:"power"`
+	Voltage      float64     `json:"voltage"`
+	Current      float64     `json:"current"`
+	Temp         float64     `json:"temp"`
+	VrTemp       float64     `json:"vrTemp"`
+	HashRate     float64     `json:"hashRate"`
+	BestDiff     interface{} `json:"bestDiff"`
+	FreeHeap     int         `json:"freeHeap"`
+	Hostname     string      `json:"hostname"`
+	ASICModel    string      `json:"ASICModel"`
+	StratumURL   string      `json:"stratumURL"`
+	StratumPort  int         `json:"stratumPort"`
+	StratumUser  string      `json:"stratumUser"`
+	Version      string      `json:"version"`
+	BoardVersion string      `json:"boardVersion"`
+	MACAddr      string      `json:"macAddr"`
+	Frequency    float64     `json:"frequency"`
+	FanSpeed     float64     `json:"fanspeed"`
+	FanRPM       float64     `json:"fanrpm"`
+}
+
+// axeOSDriver speaks the AxeOS HTTP API (Bitaxe, NerdAxe and forks).
+type axeOSDriver struct {
+	client *http.Client
+}
+
+func (x *axeOSDriver) Name() string { return "axeos" }
+
+func (x *axeOSDriver) Port() int { return 80 }
+
+// systemInfo fetches /api/system/info.
+func (x *axeOSDriver) systemInfo(ip string) (*axeOSSystemInfo, error) {
+	resp, err := x.client.Get(fmt.Sprintf("http://%s/api/system/info", ip))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var info axeOSSystemInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// Probe checks if an IP is running AxeOS by querying /api/system/info.
+func (x *axeOSDriver) Probe(ip string) (*DiscoveredMiner, error) {
+	// Quick TCP check first
+	conn, err := net.DialTimeout("tcp", ip+":80", 1*time.Second)
+	if err != nil {
+		return nil, err
+	}
+	conn.Close()
+
+	info, err := x.systemInfo(ip)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate it looks like an AxeOS device
+	if info.ASICModel == "" && info.HashRate == 0 {
+		return nil, fmt.Errorf("not a mining device")
+	}
+
+	currentPool := info.StratumURL
+	if info.StratumPort > 0 {
+		currentPool = fmt.Sprintf("%s:%d", info.StratumURL, info.StratumPort)
+	}
+
+	return &DiscoveredMiner{
+		IP:          ip,
+		Hostname:    info.Hostname,
+		Model:       info.ASICModel,
+		Hashrate:    info.HashRate / 1e9, // Convert to GH/s
+		Temperature: info.Temp,
+		CurrentPool: currentPool,
+		Firmware:    info.Version,
+		MACAddress:  strings.ToLower(info.MACAddr),
+	}, nil
+}
+
+// Telemetry reads the current telemetry of an AxeOS device.
+func (x *axeOSDriver) Telemetry(ip string) (*DeviceTelemetry, error) {
+	info, err := x.systemInfo(ip)
+	if err != nil {
+		return nil, err
+	}
+
+	power := info.Power
+	// Fallback: compute from voltage (mV) × current (mA) if power not reported
+	if power <= 0 && info.Voltage > 0 && info.Current > 0 {
+		power = (info.Voltage * info.Current) / 1_000_000
+	}
+
+	return &DeviceTelemetry{
+		Timestamp: time.Now().Unix(),
+		IP:        ip,
+		Hostname:  info.Hostname,
+		Model:     info.ASICModel,
+		Temp:      info.Temp,
+		VRTemp:    info.VrTemp,
+		Power:     power,
+		Voltage:   info.Voltage,
+		Current:   info.Current,
+		Frequency: info.Frequency,
+		FanSpeed:  info.FanSpeed,
+		FanRPM:    info.FanRPM,
+		Hashrate:  info.HashRate * 1e9, // AxeOS reports GH/s
+		BestDiff:  parseDiff(info.BestDiff),
+		FreeHeap:  info.FreeHeap,
+	}, nil
+}
+
+// SetPool sends new pool settings to an AxeOS device.
+func (x *axeOSDriver) SetPool(ip, stratumURL string, stratumPort int, stratumUser string) error {
+	payload := map[string]interface{}{
+		"stratumURL":  stratumURL,
+		"stratumPort": stratumPort,
+		"stratumUser": stratumUser,
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	url := fmt.Sprintf("http://%s/api/system", ip)
+	req, err := http.NewRequest("PATCH", url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := x.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("configure failed with status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
+// DeviceSettings are the AxeOS settings GoVault can change. Nil fields are
+// left untouched. Passwords are write-only: AxeOS never reports them.
+type DeviceSettings struct {
+	Frequency               *float64 `json:"frequency,omitempty"`   // ASIC MHz
+	CoreVoltage             *int     `json:"coreVoltage,omitempty"` // mV
+	AutoFan                 *bool    `json:"autoFan,omitempty"`
+	FanSpeed                *int     `json:"fanSpeed,omitempty"` // percent, manual mode
+	Hostname                *string  `json:"hostname,omitempty"`
+	StratumPassword         *string  `json:"stratumPassword,omitempty"`
+	FallbackStratumURL      *string  `json:"fallbackStratumURL,omitempty"`
+	FallbackStratumPort     *int     `json:"fallbackStratumPort,omitempty"`
+	FallbackStratumUser     *string  `json:"fallbackStratumUser,omitempty"`
+	FallbackStratumPassword *string  `json:"fallbackStratumPassword,omitempty"`
+}
+
+// DeviceLimits are the safe tuning ranges for an ASIC model.
+type DeviceLimits struct {
+	Model          string  `json:"model"`
+	MinFrequency   float64 `json:"minFrequency"`
+	MaxFrequency   float64 `json:"maxFrequency"`
+	MinCoreVoltage int     `json:"minCoreVoltage"`
+	MaxCoreVoltage int     `json:"maxCoreVoltage"`
+	MinFanSpeed    int     `json:"minFanSpeed"` // lowest manual fan speed
+}
+
+// deviceLimits holds conservative ranges per ASIC model. Tuning models not
+// listed here is refused.
+var deviceLimits = map[string]DeviceLimits{
+	"BM1397": {Model: "BM1397", MinFrequency: 300, MaxFrequency: 650, MinCoreVoltage: 1100, MaxCoreVoltage: 1400, MinFanSpeed: 20},
+	"BM1366": {Model: "BM1366", MinFrequency: 300, MaxFrequency: 600, MinCoreVoltage: 1100, MaxCoreVoltage: 1300, MinFanSpeed: 20},
+	"BM1368": {Model: "BM1368", MinFrequency: 300, MaxFrequency: 650, MinCoreVoltage: 1100, MaxCoreVoltage: 1300, MinFanSpeed: 20},
+	"BM1370": {Model: "BM1370", MinFrequency: 300, MaxFrequency: 700, MinCoreVoltage: 1000, MaxCoreVoltage: 1300, MinFanSpeed: 20},
+}
+
+// LimitsForModel returns the safe tuning limits for an ASIC model.
+func LimitsForModel(model string) (DeviceLimits, bool) {
+	l, ok := deviceLimits[strings.ToUpper(strings.TrimSpace(model))]
+	return l, ok
+}
+
+// AllDeviceLimits returns the tuning limits of every known ASIC model.
+func AllDeviceLimits() []DeviceLimits {
+	result := make([]DeviceLimits, 0, len(deviceLimits))
+	for _, l := range deviceLimits {
+		result = append(result, l)
+	}
+	return result
+}
+
+// Validate checks settings against the limits of the device's ASIC model.
+func (s DeviceSettings) Validate(model string) error {
+	tuning := s.Frequency != nil || s.CoreVoltage != nil
+	limits, ok := LimitsForModel(model)
+	if tuning && !ok {
+		return fmt.Errorf("no safe limits known for ASIC model %q", model)
+	}
+	if s.Frequency != nil && (*s.Frequency < limits.MinFrequency || *s.Frequency > limits.MaxFrequency) {
+		return fmt.Errorf("frequency %.0f MHz outside %.0f-%.0f MHz for %s",
+			*s.Frequency, limits.MinFrequency, limits.MaxFrequency, limits.Model)
+	}
+	if s.CoreVoltage != nil && (*s.CoreVoltage < limits.MinCoreVoltage || *s.CoreVoltage > limits.MaxCoreVoltage) {
+		return fmt.Errorf("core voltage %d mV outside %d-%d mV for %s",
+			*s.CoreVoltage, limits.MinCoreVoltage, limits.MaxCoreVoltage, limits.Model)
+	}
+	if s.FanSpeed != nil {
+		minFan := 20
+		if ok {
+			minFan = limits.MinFanSpeed
+		}
+		if *s.FanSpeed < minFan || *s.FanSpeed > 100 {
+			return fmt.Errorf("fan speed %d%% outside %d-100%%", *s.FanSpeed, minFan)
+		}
+	}
+	if s.Hostname != nil && !validHostname(*s.Hostname) {
+		return fmt.Errorf("invalid hostname: %q", *s.Hostname)
+	}
+	if s.FallbackStratumPort != nil && (*s.FallbackStratumPort < 1 || *s.FallbackStratumPort > 65535) {
+		return fmt.Errorf("invalid fallback stratum port: %d", *s.FallbackStratumPort)
+	}
+	return nil
+}
+
+// Only returns the current values of the fields set in want, so a change
+// can be undone by applying the result.
+func (s DeviceSettings) Only(want DeviceSettings) DeviceSettings {
+	var out DeviceSettings
+	if want.Frequency != nil {
+		out.Frequency = s.Frequency
+	}
+	if want.CoreVoltage != nil {
+		out.CoreVoltage = s.CoreVoltage
+	}
+	if want.AutoFan != nil {
+		out.AutoFan = s.AutoFan
+	}
+	if want.FanSpeed != nil {
+		out.FanSpeed = s.FanSpeed
+	}
+	if want.Hostname != nil {
+		out.Hostname = s.Hostname
+	}
+	if want.FallbackStratumURL != nil {
+		out.FallbackStratumURL = s.FallbackStratumURL
+	}
+	if want.FallbackStratumPort != nil {
+		out.FallbackStratumPort = s.FallbackStratumPort
+	}
+	if want.FallbackStratumUser != nil {
+		out.FallbackStratumUser = s.FallbackStratumUser
+	}
+	return out
+}
+
+// axeOSSettings maps the settings fields of /api/system/info.
+type axeOSSettings struct {
+	ASICModel           string  `json:"ASICModel"`
+	Frequency           float64 `json:"frequency"`
+	CoreVoltage         int     `json:"coreVoltage"`
+	AutoFanSpeed        int     `json:"autofanspeed"`
+	FanSpeed            int     `json:"fanspeed"`
+	Hostname            string  `json:"hostname"`
+	FallbackStratumURL  string  `json:"fallbackStratumURL"`
+	FallbackStratumPort int     `json:"fallbackStratumPort"`
+	FallbackStratumUser string  `json:"fallbackStratumUser"`
+}
+
+// ReadDeviceSettings returns the current settings and ASIC model of an
+// AxeOS device.
+func (d *Discovery) ReadDeviceSettings(ip string) (DeviceSettings, string, error) {
+	if err := d.requireAxeOS(ip); err != nil {
+		return DeviceSettings{}, "", err
+	}
+	resp, err := d.client.Get(fmt.Sprintf("http://%s/api/system/info", ip))
+	if err != nil {
+		return DeviceSettings{}, "", err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return DeviceSettings{}, "", fmt.Errorf("status %d", resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return DeviceSettings{}, "", err
+	}
+	var info axeOSSettings
+	if err := json.Unmarshal(body, &info); err != nil {
+		return DeviceSettings{}, "", err
+	}
+
+	autoFan := info.AutoFanSpeed != 0
+	return DeviceSettings{
+		Frequency:           &info.Frequency,
+		CoreVoltage:         &info.CoreVoltage,
+		AutoFan:             &autoFan,
+		FanSpeed:            &info.FanSpeed,
+		Hostname:            &info.Hostname,
+		FallbackStratumURL:  &info.FallbackStratumURL,
+		FallbackStratumPort: &info.FallbackStratumPort,
+		FallbackStratumUser: &info.FallbackStratumUser,
+	}, info.ASICModel, nil
+}
+
+// ApplyDeviceSettings PATCHes settings to an AxeOS device. Frequency and
+// voltage changes take effect after a restart.
+func (d *Discovery) ApplyDeviceSettings(ip string, s DeviceSettings) error {
+	if err := d.requireAxeOS(ip); err != nil {
+		return err
+	}
+	payload := make(map[string]interface{})
+	if s.Frequency != nil {
+		payload["frequency"] = *s.Frequency
+	}
+	if s.CoreVoltage != nil {
+		payload["coreVoltage"] = *s.CoreVoltage
+	}
+	if s.AutoFan != nil {
+		auto := 0
+		if *s.AutoFan {
+			auto = 1
+		}
+		payload["autofanspeed"] = auto
+	}
+	if s.FanSpeed != nil {
+		payload["fanspeed"] = *s.FanSpeed
+	}
+	if s.Hostname != nil {
+		payload["hostname"] = *s.Hostname
+	}
+	if s.StratumPassword != nil {
+		payload["stratumPassword"] = *s.StratumPassword
+	}
+	if s.FallbackStratumURL != nil {
+		payload["fallbackStratumURL"] = *s.FallbackStratumURL
+	}
+	if s.FallbackStratumPort != nil {
+		payload["fallbackStratumPort"] = *s.FallbackStratumPort
+	}
+	if s.FallbackStratumUser != nil {
+		payload["fallbackStratumUser"] = *s.FallbackStratumUser
+	}
+	if s.FallbackStratumPassword != nil {
+		payload["fallbackStratumPassword"] = *s.FallbackStratumPassword
+	}
+	if len(payload) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("PATCH", fmt.Sprintf("http://%s/api/system", ip), bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("update failed with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// RestartDevice asks an AxeOS device to reboot.
+func (d *Discovery) RestartDevice(ip string) error {
+	if err := d.requireAxeOS(ip); err != nil {
+		return err
+	}
+	resp, err := d.client.Post(fmt.Sprintf("http://%s/api/system/restart", ip), "application/json", nil)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return fmt.Errorf("restart failed with status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// requireAxeOS refuses device management on devices known to run other
+// firmware.
+func (d *Discovery) requireAxeOS(ip string) error {
+	if drv := d.knownDriver(ip); drv != nil && drv.Name() != "axeos" {
+		return fmt.Errorf("device management is not supported by the %s driver", drv.Name())
+	}
+	return nil
+}
+
+// validHostname checks a single DNS label (what AxeOS uses for mDNS).
+func validHostname(h string) bool {
+	if h == "" || len(h) > 32 {
+		return false
+	}
+	for i, c := range h {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-' && i > 0 && i < len(h)-1:
+		default:
+			return false
+		}
+	}
+	return true
+}
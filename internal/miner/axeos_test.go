@@ -0,0 +1,175 @@
+package miner
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func ptr[T any](v T) *T { return &v }
+
+func TestDeviceSettingsValidate(t *testing.T) {
+	cases := []struct {
+		name  string
+		model string
+		s     DeviceSettings
+		ok    bool
+	}{
+		{"in range", "BM1366", DeviceSettings{Frequency: ptr(485.0), CoreVoltage: ptr(1200)}, true},
+		{"model case and space", " bm1370 ", DeviceSettings{Frequency: ptr(700.0)}, true},
+		{"frequency high", "BM1366", DeviceSettings{Frequency: ptr(601.0)}, false},
+		{"frequency low", "BM1366", DeviceSettings{Frequency: ptr(299.0)}, false},
+		{"voltage high", "BM1366", DeviceSettings{CoreVoltage: ptr(1301)}, false},
+		{"voltage low", "BM1370", DeviceSettings{CoreVoltage: ptr(999)}, false},
+		{"tuning unknown model", "BM9999", DeviceSettings{Frequency: ptr(400.0)}, false},
+		{"fan on unknown model", "BM9999", DeviceSettings{FanSpeed: ptr(50)}, true},
+		{"fan too low", "BM1366", DeviceSettings{FanSpeed: ptr(19)}, false},
+		{"fan too high", "BM1366", DeviceSettings{FanSpeed: ptr(101)}, false},
+		{"hostname", "", DeviceSettings{Hostname: ptr("bitaxe-1")}, true},
+		{"bad hostname", "", DeviceSettings{Hostname: ptr("bit axe")}, false},
+		{"fallback port", "", DeviceSettings{FallbackStratumPort: ptr(3333)}, true},
+		{"fallback port zero", "", DeviceSettings{FallbackStratumPort: ptr(0)}, false},
+		{"fallback port high", "", DeviceSettings{FallbackStratumPort: ptr(65536)}, false},
+		{"nothing set", "", DeviceSettings{}, true},
+	}
+	for _, c := range cases {
+		if err := c.s.Validate(c.model); (err == nil) != c.ok {
+			t.Errorf("%s: Validate = %v, want ok %v", c.name, err, c.ok)
+		}
+	}
+}
+
+func TestValidHostname(t *testing.T) {
+	cases := map[string]bool{
+		"bitaxe":                            true,
+		"Bitaxe-Gamma-01":                   true,
+		"a":                                 true,
+		"":                                  false,
+		"-bitaxe":                           false,
+		"bitaxe-":                           false,
+		"bit.axe":                           false,
+		"bitaxé":                            false,
+		strings.Repeat("a", 32):             true,
+		strings.Repeat("a", 33):             false,
+		"bitaxe\r\nX-Injected: header-here": false,
+	}
+	for h, want := range cases {
+		if got := validHostname(h); got != want {
+			t.Errorf("validHostname(%q) = %v, want %v", h, got, want)
+		}
+	}
+}
+
+func TestDeviceSettingsOnly(t *testing.T) {
+	current := DeviceSettings{
+		Frequency: ptr(485.0), CoreVoltage: ptr(1200), AutoFan: ptr(true),
+		FanSpeed: ptr(60), Hostname: ptr("bitaxe1"), FallbackStratumPort: ptr(3333),
+	}
+	undo := current.Only(DeviceSettings{Frequency: ptr(550.0), AutoFan: ptr(false), StratumPassword: ptr("x")})
+	if undo.Frequency == nil || *undo.Frequency != 485 || undo.AutoFan == nil || !*undo.AutoFan {
+		t.Errorf("undo %+v, want the current frequency and fan mode", undo)
+	}
+	if undo.CoreVoltage != nil || undo.FanSpeed != nil || undo.Hostname != nil ||
+		undo.FallbackStratumPort != nil || undo.StratumPassword != nil {
+		t.Errorf("undo %+v carries fields that were not changed", undo)
+	}
+}
+
+// deviceRequest is one request received by fakeDevice.
+type deviceRequest struct {
+	method, path string
+	body         map[string]interface{}
+}
+
+// fakeDevice serves the AxeOS management API and records every request.
+func fakeDevice(t *testing.T, info string) (ip string, d *Discovery, requests *[]deviceRequest) {
+	requests = new([]deviceRequest)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		req := deviceRequest{method: r.Method, path: r.URL.Path}
+		if b, _ := io.ReadAll(r.Body); len(b) > 0 {
+			json.Unmarshal(b, &req.body)
+		}
+		*requests = append(*requests, req)
+		switch r.Method + " " + r.URL.Path {
+		case "GET /api/system/info":
+			w.Write([]byte(info))
+		case "PATCH /api/system", "POST /api/system/restart":
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	d = NewDiscovery()
+	d.client = srv.Client()
+	return strings.TrimPrefix(srv.URL, "http://"), d, requests
+}
+
+func TestReadDeviceSettings(t *testing.T) {
+	ip, d, _ := fakeDevice(t, `{"ASICModel":"BM1368","frequency":490,"coreVoltage":1166,"autofanspeed":1,
+		"fanspeed":40,"hostname":"gamma","fallbackStratumURL":"pool.example","fallbackStratumPort":3333,
+		"fallbackStratumUser":"bc1q.rig"}`)
+	s, model, err := d.ReadDeviceSettings(ip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if model != "BM1368" || *s.Frequency != 490 || *s.CoreVoltage != 1166 || !*s.AutoFan || *s.FanSpeed != 40 ||
+		*s.Hostname != "gamma" || *s.FallbackStratumURL != "pool.example" || *s.FallbackStratumPort != 3333 ||
+		*s.FallbackStratumUser != "bc1q.rig" || s.StratumPassword != nil {
+		t.Errorf("settings %+v, model %q", s, model)
+	}
+}
+
+func TestApplyDeviceSettings(t *testing.T) {
+	ip, d, requests := fakeDevice(t, "{}")
+	err := d.ApplyDeviceSettings(ip, DeviceSettings{
+		Frequency: ptr(525.0), AutoFan: ptr(false), FanSpeed: ptr(70), StratumPassword: ptr("secret"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(*requests) != 1 {
+		t.Fatalf("%d requests, want one PATCH", len(*requests))
+	}
+	req := (*requests)[0]
+	want := map[string]interface{}{"frequency": 525.0, "autofanspeed": 0.0, "fanspeed": 70.0, "stratumPassword": "secret"}
+	if req.method != "PATCH" || req.path != "/api/system" || len(req.body) != len(want) {
+		t.Fatalf("request %+v", req)
+	}
+	for k, v := range want {
+		if req.body[k] != v {
+			t.Errorf("payload %s = %v, want %v", k, req.body[k], v)
+		}
+	}
+
+	if err := d.ApplyDeviceSettings(ip, DeviceSettings{}); err != nil || len(*requests) != 1 {
+		t.Errorf("empty settings: %v, %d requests; want no request", err, len(*requests))
+	}
+
+	if err := d.RestartDevice(ip); err != nil {
+		t.Fatal(err)
+	}
+	if last := (*requests)[len(*requests)-1]; last.method != "POST" || last.path != "/api/system/restart" {
+		t.Errorf("restart sent %s %s", last.method, last.path)
+	}
+}
+
+func TestDeviceManagementRequiresAxeOS(t *testing.T) {
+	ip, d, requests := fakeDevice(t, "{}")
+	d.byIP[ip] = &cgminerDriver{}
+
+	if _, _, err := d.ReadDeviceSettings(ip); err == nil {
+		t.Error("read settings from a cgminer device")
+	}
+	if err := d.ApplyDeviceSettings(ip, DeviceSettings{FanSpeed: ptr(50)}); err == nil {
+		t.Error("applied settings to a cgminer device")
+	}
+	if err := d.RestartDevice(ip); err == nil {
+		t.Error("restarted a cgminer device")
+	}
+	if len(*requests) != 0 {
+		t.Errorf("%d requests sent to a cgminer device", len(*requests))
+	}
+}
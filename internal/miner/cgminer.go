@@ -0,0 +1,369 @@
+package miner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// cgminerDriver speaks the cgminer-compatible TCP JSON API on port 4028
+// (Avalon, Antminer and other cgminer/bmminer forks).
+type cgminerDriver struct {
+	port    int // 0 means 4028
+	timeout time.Duration
+}
+
+func (c *cgminerDriver) Name() string { return "cgminer" }
+
+func (c *cgminerDriver) Port() int {
+	if c.port == 0 {
+		return 4028
+	}
+	return c.port
+}
+
+func (c *cgminerDriver) addr(ip string) string {
+	return net.JoinHostPort(ip, strconv.Itoa(c.Port()))
+}
+
+// command sends one API command and returns the decoded response. A
+// response with an error STATUS is returned as an error.
+func (c *cgminerDriver) command(ip, cmd, param string) (map[string]interface{}, error) {
+	conn, err := net.DialTimeout("tcp", c.addr(ip), c.timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	conn.SetDeadline(time.Now().Add(c.timeout))
+
+	req := map[string]string{"command": cmd}
+	if param != "" {
+		req["parameter"] = param
+	}
+	if err := json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, err
+	}
+
+	raw, err := io.ReadAll(io.LimitReader(conn, 1<<20))
+	if err != nil && len(raw) == 0 {
+		return nil, err
+	}
+	// Most firmware NUL-terminates the response.
+	raw = bytes.TrimRight(raw, "\x00 \r\n\t")
+
+	var resp map[string]interface{}
+	if err := json.Unmarshal(raw, &resp); err != nil {
+		return nil, fmt.Errorf("%s: invalid response: %w", cmd, err)
+	}
+
+	if status := cgminerSection(resp, "STATUS"); len(status) > 0 {
+		if s, _ := status[0]["STATUS"].(string); s == "E" || s == "F" {
+			msg, _ := status[0]["Msg"].(string)
+			return nil, fmt.Errorf("%s: %s", cmd, msg)
+		}
+	}
+	return resp, nil
+}
+
+// Probe checks for a cgminer API by sending the "version" command.
+func (c *cgminerDriver) Probe(ip string) (*DiscoveredMiner, error) {
+	resp, err := c.command(ip, "version", "")
+	if err != nil {
+		return nil, err
+	}
+	versions := cgminerSection(resp, "VERSION")
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("not a cgminer API")
+	}
+	v := versions[0]
+
+	m := &DiscoveredMiner{
+		IP:         ip,
+		Model:      firstString(v, "PROD", "Type", "MODEL", "Model"),
+		Firmware:   firstString(v, "CGMiner", "BMMiner", "Miner", "Firmware"),
+		MACAddress: normalizeMAC(firstString(v, "MAC", "mac")),
+	}
+	if m.Model == "" {
+		m.Model = "cgminer"
+	}
+
+	if t, err := c.Telemetry(ip); err == nil {
+		m.Hashrate = t.Hashrate / 1e9 // GH/s, as for AxeOS
+		m.Temperature = t.Temp
+	}
+	if pools, err := c.pools(ip); err == nil {
+		for _, p := range pools {
+			if active, _ := p["Stratum Active"].(bool); active {
+				m.CurrentPool, _ = p["URL"].(string)
+				break
+			}
+		}
+	}
+	return m, nil
+}
+
+// Telemetry combines the summary, devs and stats commands. Fields the
+// firmware does not report are left at zero.
+func (c *cgminerDriver) Telemetry(ip string) (*DeviceTelemetry, error) {
+	resp, err := c.command(ip, "summary", "")
+	if err != nil {
+		return nil, err
+	}
+	t := &DeviceTelemetry{
+		Timestamp: time.Now().Unix(),
+		IP:        ip,
+	}
+	if s := cgminerSection(resp, "SUMMARY"); len(s) > 0 {
+		t.Hashrate = cgminerHashrate(s[0])
+		t.BestDiff = firstNumber(s[0], "Best Share")
+		t.Power = firstNumber(s[0], "Power")
+	}
+
+	if resp, err := c.command(ip, "devs", ""); err == nil {
+		var devHashrate float64
+		for _, dev := range cgminerSection(resp, "DEVS") {
+			t.Temp = maxFloat(t.Temp, firstNumber(dev, "Temperature"))
+			devHashrate += cgminerHashrate(dev)
+		}
+		if t.Hashrate == 0 {
+			t.Hashrate = devHashrate
+		}
+	}
+
+	if resp, err := c.command(ip, "stats", ""); err == nil {
+		for _, st := range cgminerSection(resp, "STATS") {
+			applyCgminerStats(t, st)
+		}
+	}
+
+	if resp, err := c.command(ip, "version", ""); err == nil {
+		if v := cgminerSection(resp, "VERSION"); len(v) > 0 {
+			t.Model = firstString(v[0], "PROD", "Type", "MODEL", "Model")
+		}
+	}
+	return t, nil
+}
+
+// SetPool points the device at a stratum server using addpool and
+// switchpool. Both are privileged commands; firmware that does not allow
+// them returns an access error.
+func (c *cgminerDriver) SetPool(ip, stratumURL string, stratumPort int, stratumUser string) error {
+	url := fmt.Sprintf("stratum+tcp://%s:%d", stratumURL, stratumPort)
+
+	index, err := c.findPool(ip, url, stratumUser)
+	if err != nil {
+		return err
+	}
+	if index < 0 {
+		param := cgminerEscape(url) + "," + cgminerEscape(stratumUser) + ",x"
+		if _, err := c.command(ip, "addpool", param); err != nil {
+			return err
+		}
+		if index, err = c.findPool(ip, url, stratumUser); err != nil {
+			return err
+		}
+		if index < 0 {
+			return fmt.Errorf("pool %s was not added", url)
+		}
+	}
+
+	_, err = c.command(ip, "switchpool", strconv.Itoa(index))
+	return err
+}
+
+// SetMining disables or enables every ASC and PGA device with the
+// privileged asc/pga enable and disable commands. If disabling fails part
+// way, the devices already disabled are enabled again, so the miner is
+// never left half stopped.
+func (c *cgminerDriver) SetMining(ip string, enabled bool) error {
+	resp, err := c.command(ip, "devs", "")
+	if err != nil {
+		return err
+	}
+	action := "disable"
+	if enabled {
+		action = "enable"
+	}
+	var done [][2]string // kind, index
+	for _, dev := range cgminerSection(resp, "DEVS") {
+		for _, kind := range []string{"asc", "pga"} {
+			v, ok := dev[strings.ToUpper(kind)]
+			if !ok {
+				continue
+			}
+			index := strconv.Itoa(int(parseDiff(v)))
+			if _, err := c.command(ip, kind+action, index); err != nil {
+				if !enabled {
+					for _, d := range done {
+						c.command(ip, d[0]+"enable", d[1])
+					}
+				}
+				return err
+			}
+			done = append(done, [2]string{kind, index})
+		}
+	}
+	if len(done) == 0 {
+		return fmt.Errorf("no ASC or PGA devices reported")
+	}
+	return nil
+}
+
+func (c *cgminerDriver) pools(ip string) ([]map[string]interface{}, error) {
+	resp, err := c.command(ip, "pools", "")
+	if err != nil {
+		return nil, err
+	}
+	return cgminerSection(resp, "POOLS"), nil
+}
+
+// findPool returns the index of a configured pool, or -1.
+func (c *cgminerDriver) findPool(ip, url, user string) (int, error) {
+	pools, err := c.pools(ip)
+	if err != nil {
+		return -1, err
+	}
+	for _, p := range pools {
+		pURL, _ := p["URL"].(string)
+		pUser, _ := p["User"].(string)
+		if strings.EqualFold(pURL, url) && pUser == user {
+			return int(firstNumber(p, "POOL")), nil
+		}
+	}
+	return -1, nil
+}
+
+// applyCgminerStats picks sensor values out of one STATS entry. Key names
+// differ per firmware; Avalon packs its sensors into "MM ID" strings of
+// Key[value] pairs.
+func applyCgminerStats(t *DeviceTelemetry, st map[string]interface{}) {
+	for key, v := range st {
+		lower := strings.ToLower(key)
+		if s, ok := v.(string); ok && strings.HasPrefix(key, "MM ID") {
+			fields := bracketFields(s)
+			temp := parseDiff(fields["TMax"])
+			if temp == 0 {
+				temp = parseDiff(fields["Temp"])
+			}
+			t.Temp = maxFloat(t.Temp, temp)
+			t.Frequency = maxFloat(t.Frequency, parseDiff(fields["Freq"]))
+			t.FanRPM = maxFloat(t.FanRPM, parseDiff(fields["Fan1"]))
+			t.FanSpeed = maxFloat(t.FanSpeed, parseDiff(strings.TrimSuffix(fields["FanR"], "%")))
+			if p := parseDiff(fields["WALLPOWER"]); p > 0 {
+				t.Power = p
+			}
+			continue
+		}
+		n := parseDiff(v)
+		switch {
+		case n <= 0:
+		case strings.HasPrefix(lower, "temp"):
+			t.Temp = maxFloat(t.Temp, n)
+		case strings.HasPrefix(lower, "fan") && !strings.HasPrefix(lower, "fan_num"):
+			t.FanRPM = maxFloat(t.FanRPM, n)
+		case lower == "frequency" || lower == "freq_avg":
+			t.Frequency = maxFloat(t.Frequency, n)
+		case lower == "power":
+			t.Power = n
+		}
+	}
+}
+
+// cgminerHashrate reads a hashrate in H/s from MHS or GHS fields.
+func cgminerHashrate(m map[string]interface{}) float64 {
+	if mhs := firstNumber(m, "MHS 5s", "MHS 1m", "MHS av"); mhs > 0 {
+		return mhs * 1e6
+	}
+	return firstNumber(m, "GHS 5s", "GHS av") * 1e9
+}
+
+// cgminerSection returns an array section of a response as objects.
+func cgminerSection(resp map[string]interface{}, name string) []map[string]interface{} {
+	list, _ := resp[name].([]interface{})
+	result := make([]map[string]interface{}, 0, len(list))
+	for _, item := range list {
+		if m, ok := item.(map[string]interface{}); ok {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+// bracketFields parses "Key[value] Key2[value2]" strings.
+func bracketFields(s string) map[string]string {
+	fields := make(map[string]string)
+	for {
+		open := strings.IndexByte(s, '[')
+		if open < 0 {
+			return fields
+		}
+		end := strings.IndexByte(s[open:], ']')
+		if end < 0 {
+			return fields
+		}
+		key := strings.TrimSpace(s[:open])
+		if i := strings.LastIndexByte(key, ' '); i >= 0 {
+			key = key[i+1:]
+		}
+		// Multi-value fields keep only the first value.
+		value := strings.Fields(s[open+1 : open+end])
+		if key != "" && len(value) > 0 {
+			fields[key] = value[0]
+		}
+		s = s[open+end+1:]
+	}
+}
+
+// cgminerEscape escapes the separators of a command parameter.
+func cgminerEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	return strings.ReplaceAll(s, ",", `\,`)
+}
+
+func firstString(m map[string]interface{}, keys ...string) string {
+	for _, k := range keys {
+		if s, ok := m[k].(string); ok && s != "" {
+			return s
+		}
+	}
+	return ""
+}
+
+func firstNumber(m map[string]interface{}, keys ...string) float64 {
+	for _, k := range keys {
+		if v, ok := m[k]; ok {
+			if n := parseDiff(v); n != 0 {
+				return n
+			}
+		}
+	}
+	return 0
+}
+
+// normalizeMAC lowercases a MAC and adds colons to bare hex forms.
+func normalizeMAC(mac string) string {
+	mac = strings.ToLower(strings.TrimSpace(mac))
+	if len(mac) == 12 && !strings.ContainsAny(mac, ":-") {
+		var b strings.Builder
+		for i := 0; i < 12; i += 2 {
+			if i > 0 {
+				b.WriteByte(':')
+			}
+			b.WriteString(mac[i : i+2])
+		}
+		return b.String()
+	}
+	return strings.ReplaceAll(mac, "-", ":")
+}
+
+func maxFloat(a, b float64) float64 {
+	if b > a {
+		return b
+	}
+	return a
+}
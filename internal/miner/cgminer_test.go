@@ -0,0 +1,289 @@
+package miner
+
+import (
+	"encoding/json"
+	"fmt"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeCgminer serves canned cgminer API responses, one JSON request per
+// connection, NUL-terminated like most firmware.
+type fakeCgminer struct {
+	listener net.Listener
+
+	mu       sync.Mutex
+	pools    []map[string]interface{}
+	commands []string        // "command" or "command|parameter", in order
+	denied   map[string]bool // "command" or "command|parameter"
+}
+
+func newFakeCgminer(t *testing.T) *fakeCgminer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := &fakeCgminer{
+		listener: l,
+		pools: []map[string]interface{}{
+			{"POOL": 0, "URL": "stratum+tcp://pool.example.com:3333", "User": "someone", "Stratum Active": true},
+		},
+		denied: make(map[string]bool),
+	}
+	go f.serve()
+	t.Cleanup(func() { l.Close() })
+	return f
+}
+
+func (f *fakeCgminer) driver() *cgminerDriver {
+	return &cgminerDriver{port: f.listener.Addr().(*net.TCPAddr).Port, timeout: 2 * time.Second}
+}
+
+func (f *fakeCgminer) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		go func() {
+			defer conn.Close()
+			var req map[string]string
+			if err := json.NewDecoder(conn).Decode(&req); err != nil {
+				return
+			}
+			resp, _ := json.Marshal(f.respond(req["command"], req["parameter"]))
+			conn.Write(append(resp, 0))
+		}()
+	}
+}
+
+func (f *fakeCgminer) respond(cmd, param string) map[string]interface{} {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if param != "" {
+		f.commands = append(f.commands, cmd+"|"+param)
+	} else {
+		f.commands = append(f.commands, cmd)
+	}
+	ok := []interface{}{map[string]interface{}{"STATUS": "S", "Msg": cmd}}
+	if f.denied[cmd] || f.denied[cmd+"|"+param] {
+		return map[string]interface{}{"STATUS": []interface{}{
+			map[string]interface{}{"STATUS": "E", "Msg": "Access denied to '" + cmd + "' command"},
+		}}
+	}
+
+	switch cmd {
+	case "version":
+		return map[string]interface{}{"STATUS": ok, "VERSION": []interface{}{
+			map[string]interface{}{"CGMiner": "4.11.1", "API": "3.7", "PROD": "AvalonMiner 1246", "MAC": "0A1B2C3D4E5F"},
+		}}
+	case "summary":
+		return map[string]interface{}{"STATUS": ok, "SUMMARY": []interface{}{
+			map[string]interface{}{"MHS 5s": 90000000.0, "MHS av": 88000000.0, "Best Share": 123456789.0},
+		}}
+	case "devs":
+		return map[string]interface{}{"STATUS": ok, "DEVS": []interface{}{
+			map[string]interface{}{"ASC": 0, "Temperature": 70.5, "MHS 5s": 45000000.0},
+			map[string]interface{}{"ASC": 1, "Temperature": 75.0, "MHS 5s": 45000000.0},
+		}}
+	case "stats":
+		return map[string]interface{}{"STATUS": ok, "STATS": []interface{}{
+			map[string]interface{}{
+				"STATS": 0, "ID": "AVA100",
+				"MM ID0": "Ver[1246-N-4] DNA[020100] Elapsed[1234] TMax[82] Freq[500.25 525 550] Fan1[3200] FanR[65%] WALLPOWER[3400]",
+			},
+		}}
+	case "pools":
+		return map[string]interface{}{"STATUS": ok, "POOLS": f.pools}
+	case "addpool":
+		parts := splitCgminerParam(param)
+		f.pools = append(f.pools, map[string]interface{}{
+			"POOL": len(f.pools), "URL": parts[0], "User": parts[1], "Stratum Active": false,
+		})
+		return map[string]interface{}{"STATUS": ok}
+	case "ascenable", "ascdisable", "pgaenable", "pgadisable":
+		return map[string]interface{}{"STATUS": ok}
+	case "switchpool":
+		for _, p := range f.pools {
+			p["Stratum Active"] = fmt.Sprint(p["POOL"]) == param
+		}
+		return map[string]interface{}{"STATUS": ok}
+	}
+	return map[string]interface{}{"STATUS": []interface{}{
+		map[string]interface{}{"STATUS": "E", "Msg": "Invalid command"},
+	}}
+}
+
+// splitCgminerParam splits a parameter on commas, undoing cgminerEscape.
+func splitCgminerParam(param string) []string {
+	var parts []string
+	var cur strings.Builder
+	for i := 0; i < len(param); i++ {
+		switch c := param[i]; {
+		case c == '\\' && i+1 < len(param):
+			i++
+			cur.WriteByte(param[i])
+		case c == ',':
+			parts = append(parts, cur.String())
+			cur.Reset()
+		default:
+			cur.WriteByte(c)
+		}
+	}
+	return append(parts, cur.String())
+}
+
+func (f *fakeCgminer) sent() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]string(nil), f.commands...)
+}
+
+func TestCgminerProbe(t *testing.T) {
+	f := newFakeCgminer(t)
+	m, err := f.driver().Probe("127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if m.Model != "AvalonMiner 1246" || m.Firmware != "4.11.1" || m.MACAddress != "0a:1b:2c:3d:4e:5f" {
+		t.Errorf("identity: %+v", m)
+	}
+	if m.Hashrate != 90000 || m.Temperature != 82 {
+		t.Errorf("hashrate %v GH/s, temp %v; want 90000 GH/s, 82", m.Hashrate, m.Temperature)
+	}
+	if m.CurrentPool != "stratum+tcp://pool.example.com:3333" {
+		t.Errorf("current pool %q", m.CurrentPool)
+	}
+}
+
+func TestCgminerProbeNotCgminer(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer l.Close()
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+			conn.Write([]byte("HTTP/1.0 400 Bad Request\r\n\r\n"))
+			conn.Close()
+		}
+	}()
+	d := &cgminerDriver{port: l.Addr().(*net.TCPAddr).Port, timeout: time.Second}
+	if _, err := d.Probe("127.0.0.1"); err == nil {
+		t.Error("probe of a non-cgminer service succeeded")
+	}
+}
+
+func TestCgminerTelemetry(t *testing.T) {
+	f := newFakeCgminer(t)
+	tel, err := f.driver().Telemetry("127.0.0.1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := DeviceTelemetry{
+		Timestamp: tel.Timestamp,
+		IP:        "127.0.0.1",
+		Model:     "AvalonMiner 1246",
+		Hashrate:  90e12,
+		BestDiff:  123456789,
+		Temp:      82, // MM ID TMax beats the per-device temperatures
+		Frequency: 500.25,
+		FanRPM:    3200,
+		FanSpeed:  65,
+		Power:     3400,
+	}
+	if *tel != want {
+		t.Errorf("got  %+v\nwant %+v", *tel, want)
+	}
+}
+
+func TestCgminerErrorStatus(t *testing.T) {
+	f := newFakeCgminer(t)
+	_, err := f.driver().command("127.0.0.1", "nosuchcommand", "")
+	if err == nil || !strings.Contains(err.Error(), "Invalid command") {
+		t.Errorf("got %v, want the STATUS message", err)
+	}
+}
+
+func TestCgminerSetPool(t *testing.T) {
+	f := newFakeCgminer(t)
+	if err := f.driver().SetPool("127.0.0.1", "192.168.1.5", 10333, "bc1qaddr,rig"); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"pools",
+		`addpool|stratum+tcp://192.168.1.5:10333,bc1qaddr\,rig,x`,
+		"pools",
+		"switchpool|1",
+	}
+	if got := f.sent(); strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("commands:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestCgminerSetPoolExisting(t *testing.T) {
+	f := newFakeCgminer(t)
+	if err := f.driver().SetPool("127.0.0.1", "pool.example.com", 3333, "someone"); err != nil {
+		t.Fatal(err)
+	}
+	if got := f.sent(); strings.Join(got, " ") != "pools switchpool|0" {
+		t.Errorf("commands %v, want pools then switchpool|0", got)
+	}
+}
+
+func TestCgminerSetPoolDenied(t *testing.T) {
+	f := newFakeCgminer(t)
+	f.denied["addpool"] = true
+	err := f.driver().SetPool("127.0.0.1", "192.168.1.5", 10333, "rig")
+	if err == nil || !strings.Contains(err.Error(), "Access denied") {
+		t.Errorf("got %v, want the access error", err)
+	}
+	for _, c := range f.sent() {
+		if strings.HasPrefix(c, "switchpool") {
+			t.Error("switched pool after addpool failed")
+		}
+	}
+}
+
+func TestBracketFields(t *testing.T) {
+	got := bracketFields("Ver[1246-N-4] DNA[020100] MM ID0 TMax[82] Freq[500.25 525 550] FanR[65%] Empty[] Open[1")
+	want := map[string]string{"Ver": "1246-N-4", "DNA": "020100", "TMax": "82", "Freq": "500.25", "FanR": "65%"}
+	if len(got) != len(want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %q, want %q", k, got[k], v)
+		}
+	}
+}
+
+func TestCgminerSetMining(t *testing.T) {
+	f := newFakeCgminer(t)
+	if err := f.driver().SetMining("127.0.0.1", false); err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(f.sent(), " "); got != "devs ascdisable|0 ascdisable|1" {
+		t.Errorf("commands %s", got)
+	}
+}
+
+func TestCgminerSetMiningPartialFailure(t *testing.T) {
+	f := newFakeCgminer(t)
+	f.denied["ascdisable|1"] = true
+	err := f.driver().SetMining("127.0.0.1", false)
+	if err == nil || !strings.Contains(err.Error(), "Access denied") {
+		t.Fatalf("got %v, want the access error", err)
+	}
+	// The device that was stopped is started again.
+	if got := strings.Join(f.sent(), " "); got != "devs ascdisable|0 ascdisable|1 ascenable|0" {
+		t.Errorf("commands %s", got)
+	}
+}
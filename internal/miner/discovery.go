@@ -1,12 +1,9 @@
 package miner
 
 import (
-	"encoding/json"
-	"fmt"
-	"io"
 	"net"
 	"net/http"
-	"strings"
+	"sort"
 	"sync"
 	"time"
 )
@@ -20,240 +17,123 @@ type DiscoveredMiner struct {
 	Temperature float64 `json:"temperature"`
 	CurrentPool string  `json:"currentPool"`
 	Firmware    string  `json:"firmware"`
+	MACAddress  string  `json:"macAddress"`
+	Driver      string  `json:"driver"` // "axeos" or "cgminer"
 }
 
-// axeOSSystemInfo maps the AxeOS /api/system/info response.
-type axeOSSystemInfo struct {
-	Power        float64 `json:"power"`
-	Voltage      float64 `json:"voltage"`
-	Current      float64 `json:"current"`
-	Temp         float64 `json:"temp"`
-	VrTemp       float64 `json:"vrTemp"`
-	HashRate     float64 `json:"hashRate"`
-	BestDiff     interface{} `json:"bestDiff"`
-	FreeHeap     int     `json:"freeHeap"`
-	Hostname     string  `json:"hostname"`
-	ASICModel    string  `json:"ASICModel"`
-	StratumURL   string  `json:"stratumURL"`
-	StratumPort  int     `json:"stratumPort"`
-	StratumUser  string  `json:"stratumUser"`
-	Version      string  `json:"version"`
-	BoardVersion string  `json:"boardVersion"`
-}
-
-// FleetPowerStats holds aggregated power data from AxeOS queries.
+// FleetPowerStats holds aggregated power data from device queries.
 type FleetPowerStats struct {
 	TotalWatts float64 `json:"totalWatts"`
 	Responded  int     `json:"responded"`
 	Queried    int     `json:"queried"`
 }
 
-// QueryFleetPower queries a list of IPs for AxeOS power data concurrently.
-// Non-AxeOS devices are silently skipped.
+// QueryFleetPower queries a list of IPs for power data through their
+// drivers. Devices that do not report power are skipped.
 func (d *Discovery) QueryFleetPower(ips []string) FleetPowerStats {
 	stats := FleetPowerStats{Queried: len(ips)}
-	if len(ips) == 0 {
-		return stats
-	}
-
-	var mu sync.Mutex
-	var wg sync.WaitGroup
-
-	for _, ip := range ips {
-		wg.Add(1)
-		go func(ip string) {
-			defer wg.Done()
-
-			url := fmt.Sprintf("http://%s/api/system/info", ip)
-			resp, err := d.client.Get(url)
-			if err != nil {
-				return
-			}
-			defer resp.Body.Close()
-
-			if resp.StatusCode != 200 {
-				return
-			}
-
-			body, err := io.ReadAll(resp.Body)
-			if err != nil {
-				return
-			}
-
-			var info axeOSSystemInfo
-			if err := json.Unmarshal(body, &info); err != nil {
-				return
-			}
-
-			watts := info.Power
-			// Fallback: compute from voltage (mV) × current (mA) if power not reported
-			if watts <= 0 && info.Voltage > 0 && info.Current > 0 {
-				watts = (info.Voltage * info.Current) / 1_000_000
-			}
-
-			if watts <= 0 {
-				return
-			}
-
-			mu.Lock()
-			stats.TotalWatts += watts
-			stats.Responded++
-			mu.Unlock()
-		}(ip)
+	for _, t := range d.CollectTelemetry(ips) {
+		if t.Power <= 0 {
+			continue
+		}
+		stats.TotalWatts += t.Power
+		stats.Responded++
 	}
-
-	wg.Wait()
 	return stats
 }
 
 // Discovery scans the local network for compatible mining devices.
 type Discovery struct {
 	client  *http.Client
-	results []DiscoveredMiner
+	drivers []Driver
+	byIP    map[string]Driver          // driver that last answered for each IP
+	known   map[string]DiscoveredMiner // every device found, by IP
 	mu      sync.Mutex
+
+	// OnFound is called for every device found by a scan ("scan") or an
+	// mDNS announcement ("mdns").
+	OnFound func(m DiscoveredMiner, source string)
 }
 
 func NewDiscovery() *Discovery {
+	client := &http.Client{
+		Timeout: 2 * time.Second,
+	}
 	return &Discovery{
-		client: &http.Client{
-			Timeout: 2 * time.Second,
+		client: client,
+		drivers: []Driver{
+			&axeOSDriver{client: client},
+			&cgminerDriver{timeout: 2 * time.Second},
 		},
+		byIP:  make(map[string]Driver),
+		known: make(map[string]DiscoveredMiner),
 	}
 }
 
-// ScanSubnet scans the local /24 subnet for AxeOS devices.
-func (d *Discovery) ScanSubnet() []DiscoveredMiner {
-	localIP := getLocalIP()
-	if localIP == "" {
-		return nil
-	}
-
-	// Get /24 subnet
-	parts := strings.Split(localIP, ".")
-	if len(parts) != 4 {
-		return nil
+// Known returns every device found by scans or mDNS, sorted by IP.
+func (d *Discovery) Known() []DiscoveredMiner {
+	d.mu.Lock()
+	result := make([]DiscoveredMiner, 0, len(d.known))
+	for _, m := range d.known {
+		result = append(result, m)
 	}
-	subnet := strings.Join(parts[:3], ".")
-
-	var results []DiscoveredMiner
-	var mu sync.Mutex
-	var wg sync.WaitGroup
-
-	// Scan all IPs in the subnet concurrently (limited to 50 at a time)
-	sem := make(chan struct{}, 50)
-
-	for i := 1; i <= 254; i++ {
-		ip := fmt.Sprintf("%s.%d", subnet, i)
-		if ip == localIP {
-			continue
-		}
-
-		wg.Add(1)
-		sem <- struct{}{}
-		go func(ip string) {
-			defer wg.Done()
-			defer func() { <-sem }()
+	d.mu.Unlock()
+	sort.Slice(result, func(i, j int) bool { return ipLess(result[i].IP, result[j].IP) })
+	return result
+}
 
-			miner, err := d.ProbeHost(ip)
-			if err == nil && miner != nil {
-				mu.Lock()
-				results = append(results, *miner)
-				mu.Unlock()
-			}
-		}(ip)
+// Seed adds previously discovered devices (e.g. loaded from the database)
+// to the known set without reporting them through OnFound.
+func (d *Discovery) Seed(devices []DiscoveredMiner) {
+	d.mu.Lock()
+	for _, m := range devices {
+		d.known[m.IP] = m
 	}
+	d.mu.Unlock()
+}
 
-	wg.Wait()
-
+// Forget drops a device from the known set.
+func (d *Discovery) Forget(ip string) {
 	d.mu.Lock()
-	d.results = results
+	delete(d.known, ip)
+	delete(d.byIP, ip)
 	d.mu.Unlock()
-
-	return results
 }
 
-// ProbeHost checks if an IP is running AxeOS by querying /api/system/info.
-func (d *Discovery) ProbeHost(ip string) (*DiscoveredMiner, error) {
-	// Quick TCP check first
-	conn, err := net.DialTimeout("tcp", ip+":80", 1*time.Second)
-	if err != nil {
-		return nil, err
-	}
-	conn.Close()
-
-	url := fmt.Sprintf("http://%s/api/system/info", ip)
-	resp, err := d.client.Get(url)
-	if err != nil {
-		return nil, err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return nil, fmt.Errorf("status %d", resp.StatusCode)
-	}
-
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, err
-	}
-
-	var info axeOSSystemInfo
-	if err := json.Unmarshal(body, &info); err != nil {
-		return nil, err
-	}
-
-	// Validate it looks like an AxeOS device
-	if info.ASICModel == "" && info.HashRate == 0 {
-		return nil, fmt.Errorf("not a mining device")
+// found records a discovered device and reports it through OnFound.
+func (d *Discovery) found(m DiscoveredMiner, source string) {
+	d.mu.Lock()
+	d.known[m.IP] = m
+	onFound := d.OnFound
+	d.mu.Unlock()
+	if onFound != nil {
+		onFound(m, source)
 	}
+}
 
-	currentPool := info.StratumURL
-	if info.StratumPort > 0 {
-		currentPool = fmt.Sprintf("%s:%d", info.StratumURL, info.StratumPort)
+// ProbeHost asks each driver in turn whether it recognises the device at ip.
+func (d *Discovery) ProbeHost(ip string) (*DiscoveredMiner, error) {
+	var lastErr error
+	for _, drv := range d.drivers {
+		m, err := drv.Probe(ip)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		m.Driver = drv.Name()
+		d.remember(ip, drv)
+		return m, nil
 	}
-
-	return &DiscoveredMiner{
-		IP:          ip,
-		Hostname:    info.Hostname,
-		Model:       info.ASICModel,
-		Hashrate:    info.HashRate / 1e9, // Convert to GH/s
-		Temperature: info.Temp,
-		CurrentPool: currentPool,
-		Firmware:    info.Version,
-	}, nil
+	return nil, lastErr
 }
 
-// ConfigureMiner sends new pool settings to an AxeOS device.
+// ConfigureMiner points a device at the given stratum server.
 func (d *Discovery) ConfigureMiner(ip, stratumURL string, stratumPort int, stratumUser string) error {
-	payload := map[string]interface{}{
-		"stratumURL":  stratumURL,
-		"stratumPort": stratumPort,
-		"stratumUser": stratumUser,
-	}
-
-	body, err := json.Marshal(payload)
-	if err != nil {
-		return err
-	}
-
-	url := fmt.Sprintf("http://%s/api/system", ip)
-	req, err := http.NewRequest("PATCH", url, strings.NewReader(string(body)))
+	drv, err := d.driverFor(ip)
 	if err != nil {
 		return err
 	}
-	req.Header.Set("Content-Type", "application/json")
-
-	resp, err := d.client.Do(req)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
-
-	if resp.StatusCode != 200 {
-		return fmt.Errorf("configure failed with status %d", resp.StatusCode)
-	}
-
-	return nil
+	return drv.SetPool(ip, stratumURL, stratumPort, stratumUser)
 }
 
 // getLocalIP returns the machine's local IPv4 address.
@@ -0,0 +1,63 @@
+package miner
+
+import "fmt"
+
+// Driver talks to one family of mining device firmware.
+type Driver interface {
+	// Name identifies the driver ("axeos", "cgminer").
+	Name() string
+	// Port is the TCP port the driver's API listens on.
+	Port() int
+	// Probe returns device details if ip runs firmware this driver speaks.
+	Probe(ip string) (*DiscoveredMiner, error)
+	// Telemetry reads the device's current sensors and hashrate.
+	Telemetry(ip string) (*DeviceTelemetry, error)
+	// SetPool points the device at a stratum server.
+	SetPool(ip, stratumURL string, stratumPort int, stratumUser string) error
+}
+
+// remember records which driver answered for ip.
+func (d *Discovery) remember(ip string, drv Driver) {
+	d.mu.Lock()
+	d.byIP[ip] = drv
+	d.mu.Unlock()
+}
+
+// knownDriver returns the driver that last answered for ip, if any.
+func (d *Discovery) knownDriver(ip string) Driver {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.byIP[ip]
+}
+
+// driverFor returns the driver for ip, probing the device if it has not
+// been seen yet.
+func (d *Discovery) driverFor(ip string) (Driver, error) {
+	if drv := d.knownDriver(ip); drv != nil {
+		return drv, nil
+	}
+	if _, err := d.ProbeHost(ip); err != nil {
+		return nil, fmt.Errorf("no driver recognises %s: %w", ip, err)
+	}
+	return d.knownDriver(ip), nil
+}
+
+// MiningSwitch is implemented by drivers that can stop and resume hashing
+// through the device API.
+type MiningSwitch interface {
+	SetMining(ip string, enabled bool) error
+}
+
+// SetMining stops or resumes hashing on a device. Drivers without a
+// MiningSwitch return an error.
+func (d *Discovery) SetMining(ip string, enabled bool) error {
+	drv, err := d.driverFor(ip)
+	if err != nil {
+		return err
+	}
+	sw, ok := drv.(MiningSwitch)
+	if !ok {
+		return fmt.Errorf("%s devices cannot be stopped through their API", drv.Name())
+	}
+	return sw.SetMining(ip, enabled)
+}
@@ -0,0 +1,159 @@
+package miner
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Group rule types.
+const (
+	RuleWorker    = "worker"     // glob on worker name or identity key
+	RuleUserAgent = "user_agent" // glob on the miner's user agent
+	RuleSubnet    = "subnet"     // CIDR containing the miner's IP
+	RuleTag       = "tag"        // tag on the worker, its device or its IP
+	RuleModel     = "model"      // glob on the discovered device model
+)
+
+// Tag kinds: what a free-form tag is attached to.
+const (
+	TagWorker = "worker" // worker identity key
+	TagDevice = "device" // device MAC address
+	TagIP     = "ip"     // IP address
+)
+
+// GroupRule adds every subject it matches to its group.
+type GroupRule struct {
+	Type    string `json:"type"`
+	Pattern string `json:"pattern"`
+}
+
+// Group is a named set of workers defined by rules. A worker belongs to
+// a group if any of its rules match.
+type Group struct {
+	ID    int64       `json:"id"`
+	Name  string      `json:"name"`
+	Rules []GroupRule `json:"rules"`
+}
+
+// GroupSubject is what group rules are matched against.
+type GroupSubject struct {
+	WorkerID   string
+	WorkerName string
+	UserAgent  string
+	IP         string
+	Model      string
+	Tags       []string
+}
+
+// Validate checks the rule's type and pattern.
+func (r GroupRule) Validate() error {
+	if strings.TrimSpace(r.Pattern) == "" {
+		return fmt.Errorf("%s rule needs a pattern", r.Type)
+	}
+	switch r.Type {
+	case RuleWorker, RuleUserAgent, RuleModel, RuleTag:
+	case RuleSubnet:
+		if _, _, err := net.ParseCIDR(r.Pattern); err != nil {
+			return fmt.Errorf("invalid subnet %q", r.Pattern)
+		}
+	default:
+		return fmt.Errorf("unknown rule type %q", r.Type)
+	}
+	return nil
+}
+
+// Matches reports whether the rule matches a subject. Globs and tags are
+// case-insensitive.
+func (r GroupRule) Matches(s GroupSubject) bool {
+	switch r.Type {
+	case RuleWorker:
+		return globMatch(r.Pattern, s.WorkerName) || globMatch(r.Pattern, s.WorkerID)
+	case RuleUserAgent:
+		return globMatch(r.Pattern, s.UserAgent)
+	case RuleModel:
+		return globMatch(r.Pattern, s.Model)
+	case RuleSubnet:
+		_, ipnet, err := net.ParseCIDR(r.Pattern)
+		ip := net.ParseIP(s.IP)
+		return err == nil && ip != nil && ipnet.Contains(ip)
+	case RuleTag:
+		for _, t := range s.Tags {
+			if strings.EqualFold(t, r.Pattern) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Validate checks the group's name and rules.
+func (g Group) Validate() error {
+	if strings.TrimSpace(g.Name) == "" {
+		return fmt.Errorf("group name is required")
+	}
+	for _, r := range g.Rules {
+		if err := r.Validate(); err != nil {
+			return fmt.Errorf("group %q: %w", g.Name, err)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether any of the group's rules match a subject.
+func (g Group) Matches(s GroupSubject) bool {
+	for _, r := range g.Rules {
+		if r.Matches(s) {
+			return true
+		}
+	}
+	return false
+}
+
+// NormalizeTags trims, de-duplicates (case-insensitively) and drops empty
+// tags, keeping the first spelling of each.
+func NormalizeTags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	result := make([]string, 0, len(tags))
+	for _, t := range tags {
+		t = strings.TrimSpace(t)
+		key := strings.ToLower(t)
+		if t == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		result = append(result, t)
+	}
+	return result
+}
+
+// globMatch matches s against a pattern where * matches any run of
+// characters (including "/") and ? matches one character.
+func globMatch(pattern, s string) bool {
+	if s == "" {
+		return false
+	}
+	p, str := []rune(strings.ToLower(pattern)), []rune(strings.ToLower(s))
+	pi, si := 0, 0
+	star, mark := -1, 0
+	for si < len(str) {
+		switch {
+		case pi < len(p) && (p[pi] == '?' || p[pi] == str[si]):
+			pi++
+			si++
+		case pi < len(p) && p[pi] == '*':
+			star, mark = pi, si
+			pi++
+		case star >= 0:
+			pi = star + 1
+			mark++
+			si = mark
+		default:
+			return false
+		}
+	}
+	for pi < len(p) && p[pi] == '*' {
+		pi++
+	}
+	return pi == len(p)
+}
@@ -0,0 +1,114 @@
+package miner
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestGlobMatch(t *testing.T) {
+	cases := []struct {
+		pattern, s string
+		want       bool
+	}{
+		{"rig*", "rig12", true},
+		{"RIG*", "rig12", true},
+		{"rig?", "rig1", true},
+		{"rig?", "rig12", false},
+		{"*@10.1.*", "rig1@10.1.0.7", true},
+		{"bitaxe/*", "bitaxe/2.4.0", true},
+		{"*axe*", "bitaxe/2.4.0", true},
+		{"a*b*c", "aXXbYYc", true},
+		{"a*b*c", "aXXbYY", false},
+		{"*", "anything", true},
+		{"*", "", false},
+		{"rig1", "rig10", false},
+		{"bm13??", "BM1366", true},
+	}
+	for _, c := range cases {
+		if got := globMatch(c.pattern, c.s); got != c.want {
+			t.Errorf("globMatch(%q, %q) = %v, want %v", c.pattern, c.s, got, c.want)
+		}
+	}
+}
+
+func TestGroupRuleValidate(t *testing.T) {
+	cases := []struct {
+		rule GroupRule
+		ok   bool
+	}{
+		{GroupRule{RuleWorker, "rig*"}, true},
+		{GroupRule{RuleUserAgent, "cgminer*"}, true},
+		{GroupRule{RuleModel, "BM1366"}, true},
+		{GroupRule{RuleTag, "garage"}, true},
+		{GroupRule{RuleSubnet, "10.1.0.0/16"}, true},
+		{GroupRule{RuleSubnet, "10.1.0.0"}, false},
+		{GroupRule{RuleWorker, "  "}, false},
+		{GroupRule{"hostname", "x"}, false},
+	}
+	for _, c := range cases {
+		if err := c.rule.Validate(); (err == nil) != c.ok {
+			t.Errorf("%+v: Validate = %v, want ok %v", c.rule, err, c.ok)
+		}
+	}
+	g := Group{Name: "racks", Rules: []GroupRule{{RuleWorker, "rig*"}, {RuleSubnet, "bad"}}}
+	if err := g.Validate(); err == nil || !strings.Contains(err.Error(), "racks") {
+		t.Errorf("Validate = %v, want the bad rule reported with the group name", err)
+	}
+	if err := (Group{Name: " "}).Validate(); err == nil {
+		t.Error("group without a name accepted")
+	}
+}
+
+func TestGroupMatches(t *testing.T) {
+	subject := GroupSubject{
+		WorkerID:   "rig1@aa:bb:cc:dd:ee:ff",
+		WorkerName: "rig1",
+		UserAgent:  "bitaxe/BM1366/v2.4.0",
+		IP:         "10.1.2.3",
+		Model:      "BM1366",
+		Tags:       []string{"Garage", "shelf-2"},
+	}
+	cases := []struct {
+		rule GroupRule
+		want bool
+	}{
+		{GroupRule{RuleWorker, "rig1"}, true},
+		{GroupRule{RuleWorker, "*@aa:bb:*"}, true},
+		{GroupRule{RuleWorker, "rig2"}, false},
+		{GroupRule{RuleUserAgent, "bitaxe/*"}, true},
+		{GroupRule{RuleUserAgent, "cgminer*"}, false},
+		{GroupRule{RuleModel, "bm1366"}, true},
+		{GroupRule{RuleModel, "BM1370"}, false},
+		{GroupRule{RuleSubnet, "10.1.0.0/16"}, true},
+		{GroupRule{RuleSubnet, "10.2.0.0/16"}, false},
+		{GroupRule{RuleSubnet, "not a cidr"}, false},
+		{GroupRule{RuleTag, "garage"}, true},
+		{GroupRule{RuleTag, "shelf"}, false},
+	}
+	for _, c := range cases {
+		if got := c.rule.Matches(subject); got != c.want {
+			t.Errorf("%+v: Matches = %v, want %v", c.rule, got, c.want)
+		}
+	}
+
+	if (GroupRule{RuleSubnet, "10.0.0.0/8"}).Matches(GroupSubject{WorkerName: "rig1"}) {
+		t.Error("subnet rule matched a subject without an IP")
+	}
+	either := Group{Name: "either", Rules: []GroupRule{{RuleModel, "BM1370"}, {RuleTag, "SHELF-2"}}}
+	if !either.Matches(subject) {
+		t.Error("group did not match when one of its rules does")
+	}
+	if (Group{Name: "empty"}).Matches(subject) {
+		t.Error("group without rules matched")
+	}
+}
+
+func TestNormalizeTags(t *testing.T) {
+	got := NormalizeTags([]string{" Garage ", "garage", "", "shelf-2", "  ", "GARAGE", "Shelf-2"})
+	if strings.Join(got, ",") != "Garage,shelf-2" {
+		t.Errorf("NormalizeTags = %q, want the first spelling of each tag", got)
+	}
+	if got := NormalizeTags(nil); got == nil || len(got) != 0 {
+		t.Errorf("NormalizeTags(nil) = %#v, want an empty slice", got)
+	}
+}
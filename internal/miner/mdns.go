@@ -0,0 +1,161 @@
+package miner
+
+import (
+	"context"
+	"net"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+// mdnsReprobe is how long a host is left alone after an mDNS-triggered probe.
+const mdnsReprobe = 10 * time.Minute
+
+// ListenMDNS watches mDNS responses and announcements on the local network
+// and probes hosts that advertise one of mdnsServices. It never sends
+// queries. It
+// returns when ctx is cancelled, or with an error if the socket cannot be
+// opened.
+func (d *Discovery) ListenMDNS(ctx context.Context) error {
+	group := &net.UDPAddr{IP: net.IPv4(224, 0, 0, 251), Port: 5353}
+	conn, err := net.ListenMulticastUDP("udp4", nil, group)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		conn.Close()
+	}()
+
+	var mu sync.Mutex
+	probed := make(map[string]time.Time)
+	sem := make(chan struct{}, 4)
+	buf := make([]byte, 9000)
+
+	for {
+		n, src, err := conn.ReadFromUDP(buf)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+		hostname, ok := parseMDNSResponse(buf[:n])
+		if !ok {
+			continue
+		}
+
+		ip := src.IP.String()
+		mu.Lock()
+		recent := time.Since(probed[ip]) < mdnsReprobe
+		if !recent {
+			probed[ip] = time.Now()
+		}
+		mu.Unlock()
+		if recent {
+			continue
+		}
+
+		select {
+		case sem <- struct{}{}:
+		default:
+			continue // busy; the host will announce again
+		}
+		go func(ip, hostname string) {
+			defer func() { <-sem }()
+			m, err := d.ProbeHost(ip)
+			if err != nil {
+				return
+			}
+			if m.Hostname == "" {
+				m.Hostname = hostname
+			}
+			d.found(*m, "mdns")
+		}(ip, hostname)
+	}
+}
+
+// mdnsServices are the DNS-SD service types that make a host worth
+// probing: miner firmware advertises its web interface. Hosts announcing
+// only other services (AirPlay, printers, casting) are left alone.
+var mdnsServices = []string{"_http._tcp.local."}
+
+// parseMDNSResponse reports whether a packet is an mDNS response with a
+// PTR or SRV record for one of mdnsServices, and returns the host name
+// the service runs on (the SRV target or the first A/AAAA record).
+func parseMDNSResponse(packet []byte) (string, bool) {
+	var p dnsmessage.Parser
+	header, err := p.Start(packet)
+	if err != nil || !header.Response {
+		return "", false
+	}
+	if err := p.SkipAllQuestions(); err != nil {
+		return "", false
+	}
+
+	advertised := false
+	srvHost, addrHost := "", ""
+	// record notes one resource and moves past it with skip unless it
+	// parsed the body.
+	record := func(h dnsmessage.ResourceHeader, skip func() error) error {
+		switch h.Type {
+		case dnsmessage.TypePTR, dnsmessage.TypeSRV:
+			if !isMDNSService(h.Name.String()) {
+				break
+			}
+			advertised = true
+			if h.Type == dnsmessage.TypeSRV {
+				srv, err := p.SRVResource()
+				if err != nil {
+					return err
+				}
+				if srvHost == "" {
+					srvHost = srv.Target.String()
+				}
+				return nil
+			}
+		case dnsmessage.TypeA, dnsmessage.TypeAAAA:
+			if addrHost == "" {
+				addrHost = h.Name.String()
+			}
+		}
+		return skip()
+	}
+
+	// Announcements carry the PTR as an answer; SRV and address records
+	// often come as additional records.
+	for {
+		h, err := p.AnswerHeader()
+		if err != nil || record(h, p.SkipAnswer) != nil {
+			break
+		}
+	}
+	if p.SkipAllAnswers() == nil && p.SkipAllAuthorities() == nil {
+		for {
+			h, err := p.AdditionalHeader()
+			if err != nil || record(h, p.SkipAdditional) != nil {
+				break
+			}
+		}
+	}
+
+	hostname := srvHost
+	if hostname == "" {
+		hostname = addrHost
+	}
+	return strings.TrimSuffix(strings.TrimSuffix(hostname, "."), ".local"), advertised
+}
+
+// isMDNSService reports whether a PTR or SRV owner name belongs to one of
+// mdnsServices: the service type itself or an instance of it.
+func isMDNSService(name string) bool {
+	name = strings.ToLower(name)
+	for _, svc := range mdnsServices {
+		if name == svc || strings.HasSuffix(name, "."+svc) {
+			return true
+		}
+	}
+	return false
+}
@@ -9,6 +9,7 @@ import (
 type MinerInfo struct {
 	ID             string    `json:"id"`
 	WorkerName     string    `json:"workerName"`
+	WorkerID       string    `json:"workerId"` // persistent worker identity
 	UserAgent      string    `json:"userAgent"`
 	IPAddress      string    `json:"ipAddress"`
 	ConnectedAt    time.Time `json:"connectedAt"`
@@ -18,6 +19,27 @@ type MinerInfo struct {
 	SharesRejected uint64    `json:"sharesRejected"`
 	LastShareTime  time.Time `json:"lastShareTime"`
 	BestDifficulty float64   `json:"bestDifficulty"`
+
+	// Best share from this worker since the current round started.
+	RoundBestDifficulty float64 `json:"roundBestDifficulty"`
+
+	// Job delivery from the stratum server's send queue
+	JobLatencyMs    float64 `json:"jobLatencyMs"`
+	JobLatencyMaxMs float64 `json:"jobLatencyMaxMs"`
+	QueueDepth      int     `json:"queueDepth"`
+	Dropped         uint64  `json:"dropped"`
+
+	// Shares on a replaced chain tip, and how long after the tip changed
+	// they arrived
+	SharesStaleBlock uint64  `json:"sharesStaleBlock"`
+	StaleTipAgeMs    float64 `json:"staleTipAgeMs"`
+	StaleTipAgeMaxMs float64 `json:"staleTipAgeMaxMs"`
+
+	// Shares failing consensus checks, by reason
+	RejectedNTime       uint64 `json:"rejectedNTime"`
+	RejectedVersionMask uint64 `json:"rejectedVersionMask"`
+	RejectedExtranonce2 uint64 `json:"rejectedExtranonce2"`
+	RejectedNonce       uint64 `json:"rejectedNonce"`
 }
 
 // Registry manages connected miners.
@@ -107,6 +129,19 @@ func (r *Registry) UpdateDifficulty(id string, diff float64) {
 	}
 }
 
+// SetWorkerID re-keys a connected miner to another worker identity. It
+// reports whether the miner is still connected.
+func (r *Registry) SetWorkerID(id, workerID string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	m, ok := r.miners[id]
+	if ok {
+		m.WorkerID = workerID
+	}
+	return ok
+}
+
 func (r *Registry) UpdateHashrate(id string, hashrate float64) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
@@ -0,0 +1,230 @@
+package miner
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+)
+
+// RosterEntry is a worker that is expected to be mining.
+type RosterEntry struct {
+	WorkerID        string  `json:"workerId"`
+	Label           string  `json:"label"`
+	NominalHashrate float64 `json:"nominalHashrate"` // H/s, 0 = no underperformance check
+}
+
+// RosterThresholds control when roster workers are flagged.
+type RosterThresholds struct {
+	Grace           time.Duration // offline longer than this = missing
+	UnderperformPct float64       // flag below this percent of nominal...
+	UnderperformFor time.Duration // ...sustained for this long
+	RejectSpikePct  float64       // flag when rejected+stale exceed this percent...
+	RejectWindow    time.Duration // ...of shares in this window
+	MinShares       int           // minimum shares in the window to judge
+}
+
+// WorkerObservation is the live state of a worker at evaluation time.
+type WorkerObservation struct {
+	Online   bool
+	LastSeen time.Time
+	Hashrate float64
+}
+
+// RosterStatus is the current state of one roster worker.
+type RosterStatus struct {
+	RosterEntry
+	Online          bool    `json:"online"`
+	LastSeen        int64   `json:"lastSeen"`
+	Hashrate        float64 `json:"hashrate"`
+	HashratePct     float64 `json:"hashratePct"` // percent of nominal
+	RejectRate      float64 `json:"rejectRate"`  // percent, rejected excluding stale
+	StaleRate       float64 `json:"staleRate"`   // percent
+	Missing         bool    `json:"missing"`
+	Underperforming bool    `json:"underperforming"`
+	RejectSpike     bool    `json:"rejectSpike"`
+}
+
+// RosterEvent is a change in a roster worker's condition.
+type RosterEvent struct {
+	Timestamp int64  `json:"timestamp"`
+	WorkerID  string `json:"workerId"`
+	Kind      string `json:"kind"` // see RosterEvent* constants
+	Detail    string `json:"detail"`
+}
+
+// Roster event kinds.
+const (
+	RosterEventMissing         = "missing"
+	RosterEventReturned        = "returned"
+	RosterEventUnderperforming = "underperforming"
+	RosterEventRecovered       = "recovered"
+	RosterEventRejectSpike     = "reject_spike"
+	RosterEventRejectsNormal   = "rejects_normal"
+)
+
+type rosterShare struct {
+	at       time.Time
+	accepted bool
+	stale    bool
+}
+
+type rosterState struct {
+	status     RosterStatus
+	addedAt    time.Time
+	belowSince time.Time // zero while at or above the threshold
+	shares     []rosterShare
+}
+
+// RosterMonitor tracks expected workers and flags the ones that go missing,
+// underperform, or start rejecting shares.
+type RosterMonitor struct {
+	workers map[string]*rosterState
+	mu      sync.Mutex
+}
+
+func NewRosterMonitor() *RosterMonitor {
+	return &RosterMonitor{workers: make(map[string]*rosterState)}
+}
+
+// SetEntries replaces the roster. State is kept for workers that remain.
+func (r *RosterMonitor) SetEntries(entries []RosterEntry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	next := make(map[string]*rosterState, len(entries))
+	for _, e := range entries {
+		st, ok := r.workers[e.WorkerID]
+		if !ok {
+			st = &rosterState{addedAt: time.Now()}
+		}
+		st.status.RosterEntry = e
+		next[e.WorkerID] = st
+	}
+	r.workers = next
+}
+
+// RecordShare notes a share outcome for a roster worker. Shares from
+// workers not on the roster are ignored.
+func (r *RosterMonitor) RecordShare(workerID string, accepted, stale bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if st, ok := r.workers[workerID]; ok {
+		st.shares = append(st.shares, rosterShare{at: time.Now(), accepted: accepted, stale: stale})
+	}
+}
+
+// Evaluate updates every roster worker from its observation and returns
+// the condition changes since the last evaluation.
+func (r *RosterMonitor) Evaluate(now time.Time, th RosterThresholds, obs map[string]WorkerObservation) []RosterEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var events []RosterEvent
+	emit := func(id, kind, detail string) {
+		events = append(events, RosterEvent{Timestamp: now.Unix(), WorkerID: id, Kind: kind, Detail: detail})
+	}
+
+	for id, st := range r.workers {
+		o := obs[id]
+		s := &st.status
+		s.Online = o.Online
+		s.Hashrate = o.Hashrate
+		if !o.LastSeen.IsZero() {
+			s.LastSeen = o.LastSeen.Unix()
+		}
+
+		// Missing: offline for longer than the grace period. Workers never
+		// seen count from when they were added to the roster.
+		gone := st.addedAt
+		if !o.LastSeen.IsZero() && o.LastSeen.After(gone) {
+			gone = o.LastSeen
+		}
+		missing := !o.Online && now.Sub(gone) > th.Grace
+		if missing && !s.Missing {
+			emit(id, RosterEventMissing, fmt.Sprintf("offline for %s", now.Sub(gone).Round(time.Minute)))
+		} else if !missing && s.Missing {
+			emit(id, RosterEventReturned, "seen again")
+		}
+		s.Missing = missing
+
+		// Underperforming: below the nominal threshold for long enough.
+		s.HashratePct = 0
+		if s.NominalHashrate > 0 {
+			s.HashratePct = o.Hashrate / s.NominalHashrate * 100
+		}
+		below := o.Online && s.NominalHashrate > 0 && s.HashratePct < th.UnderperformPct
+		if !below {
+			st.belowSince = time.Time{}
+		} else if st.belowSince.IsZero() {
+			st.belowSince = now
+		}
+		// Offline workers keep their last verdict; they are covered by Missing.
+		if o.Online {
+			under := below && now.Sub(st.belowSince) >= th.UnderperformFor
+			if under && !s.Underperforming {
+				emit(id, RosterEventUnderperforming, fmt.Sprintf("%.0f%% of nominal for %s",
+					s.HashratePct, now.Sub(st.belowSince).Round(time.Minute)))
+			} else if !under && s.Underperforming {
+				emit(id, RosterEventRecovered, fmt.Sprintf("%.0f%% of nominal", s.HashratePct))
+			}
+			s.Underperforming = under
+		}
+
+		// Reject spike: rejected plus stale share of the recent window.
+		cutoff := now.Add(-th.RejectWindow)
+		i := 0
+		for i < len(st.shares) && st.shares[i].at.Before(cutoff) {
+			i++
+		}
+		st.shares = st.shares[i:]
+		var rejected, stale int
+		for _, sh := range st.shares {
+			switch {
+			case sh.stale:
+				stale++
+			case !sh.accepted:
+				rejected++
+			}
+		}
+		total := len(st.shares)
+		s.RejectRate, s.StaleRate = 0, 0
+		if total > 0 {
+			s.RejectRate = float64(rejected) / float64(total) * 100
+			s.StaleRate = float64(stale) / float64(total) * 100
+		}
+		if total >= th.MinShares {
+			spike := s.RejectRate+s.StaleRate >= th.RejectSpikePct
+			if spike && !s.RejectSpike {
+				emit(id, RosterEventRejectSpike, fmt.Sprintf("%.1f%% rejected, %.1f%% stale over %d shares",
+					s.RejectRate, s.StaleRate, total))
+			} else if !spike && s.RejectSpike {
+				emit(id, RosterEventRejectsNormal, fmt.Sprintf("%.1f%% rejected, %.1f%% stale",
+					s.RejectRate, s.StaleRate))
+			}
+			s.RejectSpike = spike
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool { return events[i].WorkerID < events[j].WorkerID })
+	return events
+}
+
+// Status returns the current state of all roster workers, sorted by label.
+func (r *RosterMonitor) Status() []RosterStatus {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	result := make([]RosterStatus, 0, len(r.workers))
+	for _, st := range r.workers {
+		result = append(result, st.status)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Label != result[j].Label {
+			return result[i].Label < result[j].Label
+		}
+		return result[i].WorkerID < result[j].WorkerID
+	})
+	return result
+}
@@ -0,0 +1,156 @@
+package miner
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+var testThresholds = RosterThresholds{
+	Grace:           5 * time.Minute,
+	UnderperformPct: 80,
+	UnderperformFor: 10 * time.Minute,
+	RejectSpikePct:  20,
+	RejectWindow:    15 * time.Minute,
+	MinShares:       10,
+}
+
+// kinds returns the event kinds, in order, as one string.
+func kinds(events []RosterEvent) string {
+	var k []string
+	for _, ev := range events {
+		k = append(k, ev.WorkerID+":"+ev.Kind)
+	}
+	return strings.Join(k, " ")
+}
+
+func TestRosterMissing(t *testing.T) {
+	r := NewRosterMonitor()
+	r.SetEntries([]RosterEntry{{WorkerID: "rig1"}, {WorkerID: "rig2"}})
+	now := time.Now()
+	seen := now.Add(time.Minute)
+
+	online := map[string]WorkerObservation{"rig2": {Online: true, LastSeen: seen}}
+	offline := map[string]WorkerObservation{"rig2": {LastSeen: seen}}
+
+	steps := []struct {
+		at   time.Duration
+		obs  map[string]WorkerObservation
+		want string
+	}{
+		{time.Minute, online, ""},
+		{6 * time.Minute, online, "rig1:missing"}, // never seen: counts from being added
+		{5 * time.Minute, offline, ""},            // within grace of when rig2 was last seen
+		{6 * time.Minute, offline, ""},            // no repeat for rig1
+		{7 * time.Minute, offline, "rig2:missing"},
+		{8 * time.Minute, map[string]WorkerObservation{
+			"rig1": {Online: true, LastSeen: now.Add(8 * time.Minute)},
+			"rig2": {LastSeen: seen},
+		}, "rig1:returned"},
+	}
+	for i, s := range steps {
+		if got := kinds(r.Evaluate(now.Add(s.at), testThresholds, s.obs)); got != s.want {
+			t.Errorf("step %d: events %q, want %q", i, got, s.want)
+		}
+	}
+	status := r.Status()
+	if status[0].Missing || !status[1].Missing || status[1].LastSeen != seen.Unix() {
+		t.Errorf("status %+v", status)
+	}
+}
+
+func TestRosterUnderperforming(t *testing.T) {
+	r := NewRosterMonitor()
+	r.SetEntries([]RosterEntry{{WorkerID: "rig1", NominalHashrate: 1e12}})
+	now := time.Now()
+	at := func(hashrate float64, online bool) map[string]WorkerObservation {
+		return map[string]WorkerObservation{"rig1": {Online: online, LastSeen: now, Hashrate: hashrate}}
+	}
+
+	steps := []struct {
+		at   time.Duration
+		obs  map[string]WorkerObservation
+		want string
+	}{
+		{0, at(0.5e12, true), ""},
+		{9 * time.Minute, at(0.5e12, true), ""},
+		{10 * time.Minute, at(0.5e12, true), "rig1:underperforming"},
+		{11 * time.Minute, at(0.5e12, true), ""},
+		{12 * time.Minute, at(0, false), ""}, // offline keeps the verdict
+		{13 * time.Minute, at(0.9e12, true), "rig1:recovered"},
+		{14 * time.Minute, at(0.7e12, true), ""}, // dip restarts the clock
+		{15 * time.Minute, at(0.9e12, true), ""},
+		{20 * time.Minute, at(0.7e12, true), ""},
+		{29 * time.Minute, at(0.7e12, true), ""},
+		{30 * time.Minute, at(0.7e12, true), "rig1:underperforming"},
+	}
+	th := testThresholds
+	th.Grace = time.Hour // not testing missing here
+	for i, s := range steps {
+		if got := kinds(r.Evaluate(now.Add(s.at), th, s.obs)); got != s.want {
+			t.Errorf("step %d: events %q, want %q", i, got, s.want)
+		}
+	}
+	if st := r.Status()[0]; st.HashratePct != 70 || !st.Underperforming {
+		t.Errorf("status %+v", st)
+	}
+}
+
+func TestRosterRejectSpike(t *testing.T) {
+	r := NewRosterMonitor()
+	r.SetEntries([]RosterEntry{{WorkerID: "rig1"}})
+	online := map[string]WorkerObservation{"rig1": {Online: true, LastSeen: time.Now()}}
+	record := func(accepted, rejected, stale int) {
+		for i := 0; i < accepted; i++ {
+			r.RecordShare("rig1", true, false)
+		}
+		for i := 0; i < rejected; i++ {
+			r.RecordShare("rig1", false, false)
+		}
+		for i := 0; i < stale; i++ {
+			r.RecordShare("rig1", false, true)
+		}
+	}
+	r.RecordShare("other", false, false) // not on the roster
+
+	record(6, 1, 1)
+	if got := kinds(r.Evaluate(time.Now(), testThresholds, online)); got != "" {
+		t.Errorf("below MinShares: events %q", got)
+	}
+	record(0, 1, 1)
+	if got := kinds(r.Evaluate(time.Now(), testThresholds, online)); got != "rig1:reject_spike" {
+		t.Errorf("40%% rejected and stale: events %q", got)
+	}
+	st := r.Status()[0]
+	if st.RejectRate != 20 || st.StaleRate != 20 {
+		t.Errorf("reject rate %v%%, stale rate %v%%; want 20 and 20", st.RejectRate, st.StaleRate)
+	}
+
+	// Once the window has passed with too few shares to judge, the spike
+	// stands until enough clean shares come in.
+	later := time.Now().Add(testThresholds.RejectWindow + time.Second)
+	if got := kinds(r.Evaluate(later, testThresholds, online)); got != "" {
+		t.Errorf("empty window: events %q", got)
+	}
+	record(10, 0, 0)
+	if got := kinds(r.Evaluate(time.Now(), testThresholds, online)); got != "rig1:rejects_normal" {
+		t.Errorf("clean shares: events %q", got)
+	}
+}
+
+func TestRosterSetEntriesKeepsState(t *testing.T) {
+	r := NewRosterMonitor()
+	r.SetEntries([]RosterEntry{{WorkerID: "rig1", Label: "b"}})
+	now := time.Now()
+	r.Evaluate(now.Add(6*time.Minute), testThresholds, nil)
+
+	r.SetEntries([]RosterEntry{{WorkerID: "rig1", Label: "b2"}, {WorkerID: "rig0", Label: "a"}})
+	status := r.Status()
+	if len(status) != 2 || status[0].WorkerID != "rig0" || status[1].Label != "b2" || !status[1].Missing {
+		t.Errorf("status %+v, want rig0 first and rig1 still missing", status)
+	}
+	rig0 := map[string]WorkerObservation{"rig0": {Online: true, LastSeen: now}}
+	if got := kinds(r.Evaluate(now.Add(7*time.Minute), testThresholds, rig0)); got != "" {
+		t.Errorf("events %q after re-labelling, want none", got)
+	}
+}
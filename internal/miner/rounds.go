@@ -0,0 +1,185 @@
+package miner
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// RoundStats is a snapshot of the current mining round.
+type RoundStats struct {
+	ID           int64              `json:"id"`
+	StartedAt    int64              `json:"startedAt"`
+	DurationSec  int64              `json:"durationSec"`
+	Work         float64            `json:"work"`         // sum of difficulty-weighted shares
+	ExpectedWork float64            `json:"expectedWork"` // current network difficulty
+	Effort       float64            `json:"effort"`       // percent of expected work done
+	Shares       uint64             `json:"shares"`
+	BestShare    float64            `json:"bestShare"`
+	BestWorker   string             `json:"bestWorker"`
+	Workers      []WorkerRoundStats `json:"workers"`
+}
+
+// WorkerRoundStats holds one worker's contribution to the current round.
+type WorkerRoundStats struct {
+	Worker    string  `json:"worker"`
+	Work      float64 `json:"work"`
+	Shares    uint64  `json:"shares"`
+	BestShare float64 `json:"bestShare"`
+}
+
+// RoundTracker accumulates work for the current round. A round ends when a
+// block is found or, if configured, when the round epoch elapses.
+type RoundTracker struct {
+	id          int64
+	startedAt   time.Time
+	work        float64
+	effort      float64 // fraction of expected work, accumulated per share
+	shares      uint64
+	networkDiff float64
+	bestShare   float64
+	bestWorker  string
+	workers     map[string]*WorkerRoundStats
+
+	mu sync.RWMutex
+}
+
+func NewRoundTracker() *RoundTracker {
+	return &RoundTracker{
+		startedAt: time.Now(),
+		workers:   make(map[string]*WorkerRoundStats),
+	}
+}
+
+// Rotate ends the current round and starts a new one with the given ID.
+// It returns the final totals of the round that just ended. The network
+// difficulty carries over so effort accrues from the first share.
+func (r *RoundTracker) Rotate(id int64, startedAt time.Time) RoundStats {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	prev := r.snapshotLocked()
+
+	r.id = id
+	r.startedAt = startedAt
+	r.work = 0
+	r.effort = 0
+	r.shares = 0
+	r.bestShare = 0
+	r.bestWorker = ""
+	r.workers = make(map[string]*WorkerRoundStats)
+
+	return prev
+}
+
+// Restore resumes a round that was persisted before a restart.
+// effortPct is the effort in percent, as reported by Snapshot.
+func (r *RoundTracker) Restore(id int64, startedAt time.Time, work, effortPct, networkDiff float64, shares uint64,
+	bestShare float64, bestWorker string, workers []WorkerRoundStats) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.id = id
+	r.startedAt = startedAt
+	r.work = work
+	r.effort = effortPct / 100
+	r.networkDiff = networkDiff
+	r.shares = shares
+	r.bestShare = bestShare
+	r.bestWorker = bestWorker
+	r.workers = make(map[string]*WorkerRoundStats, len(workers))
+	for _, w := range workers {
+		w := w
+		r.workers[w.Worker] = &w
+	}
+}
+
+// RecordShare adds an accepted share to the round.
+// work is the session difficulty credited for the share (0 for sub-target
+// shares), actualDiff is the share's hash difficulty, and networkDiff is the
+// network difficulty at the time of the share. Effort is accumulated per
+// share so rounds spanning a difficulty adjustment are measured correctly.
+func (r *RoundTracker) RecordShare(worker string, work, actualDiff, networkDiff float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if networkDiff > 0 {
+		r.networkDiff = networkDiff
+	}
+
+	w, ok := r.workers[worker]
+	if !ok {
+		w = &WorkerRoundStats{Worker: worker}
+		r.workers[worker] = w
+	}
+
+	if work > 0 {
+		r.work += work
+		r.shares++
+		w.Work += work
+		w.Shares++
+		if r.networkDiff > 0 {
+			r.effort += work / r.networkDiff
+		}
+	}
+
+	if actualDiff > w.BestShare {
+		w.BestShare = actualDiff
+	}
+	if actualDiff > r.bestShare {
+		r.bestShare = actualDiff
+		r.bestWorker = worker
+	}
+}
+
+// BestShare returns the best share difficulty since the round started,
+// overall and for the given worker.
+func (r *RoundTracker) BestShare(worker string) (overall, forWorker float64) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if w, ok := r.workers[worker]; ok {
+		forWorker = w.BestShare
+	}
+	return r.bestShare, forWorker
+}
+
+// Snapshot returns the current round totals.
+func (r *RoundTracker) Snapshot() RoundStats {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.snapshotLocked()
+}
+
+func (r *RoundTracker) snapshotLocked() RoundStats {
+	stats := RoundStats{
+		ID:           r.id,
+		StartedAt:    r.startedAt.Unix(),
+		DurationSec:  int64(time.Since(r.startedAt).Seconds()),
+		Work:         r.work,
+		ExpectedWork: r.networkDiff,
+		Effort:       r.effort * 100,
+		Shares:       r.shares,
+		BestShare:    r.bestShare,
+		BestWorker:   r.bestWorker,
+		Workers:      make([]WorkerRoundStats, 0, len(r.workers)),
+	}
+	for _, w := range r.workers {
+		stats.Workers = append(stats.Workers, *w)
+	}
+	sort.Slice(stats.Workers, func(i, j int) bool {
+		return stats.Workers[i].Work > stats.Workers[j].Work
+	})
+	return stats
+}
+
+// EpochDue reports whether the round has lasted longer than epoch.
+// An epoch of zero means rounds only end when a block is found.
+func (r *RoundTracker) EpochDue(epoch time.Duration) bool {
+	if epoch <= 0 {
+		return false
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return time.Since(r.startedAt) >= epoch
+}
@@ -0,0 +1,76 @@
+package miner
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestRoundEffortAcrossDifficultyChange(t *testing.T) {
+	r := NewRoundTracker()
+	r.RecordShare("rig1", 500, 800, 1000)
+	r.RecordShare("rig2", 1000, 3000, 0) // no new network difficulty: keeps 1000
+	r.RecordShare("rig1", 1000, 1500, 2000)
+	r.RecordShare("rig2", 0, 5000, 2000) // below the session target: no work
+
+	s := r.Snapshot()
+	if s.Work != 2500 || s.Shares != 3 {
+		t.Errorf("work %v over %d shares, want 2500 over 3", s.Work, s.Shares)
+	}
+	// 500/1000 + 1000/1000 + 1000/2000
+	if math.Abs(s.Effort-200) > 1e-9 {
+		t.Errorf("effort %v%%, want 200%%", s.Effort)
+	}
+	if s.ExpectedWork != 2000 {
+		t.Errorf("expected work %v, want the latest network difficulty", s.ExpectedWork)
+	}
+	if s.BestShare != 5000 || s.BestWorker != "rig2" {
+		t.Errorf("best share %v by %s, want 5000 by rig2", s.BestShare, s.BestWorker)
+	}
+	if len(s.Workers) != 2 || s.Workers[0].Worker != "rig1" || s.Workers[0].Work != 1500 || s.Workers[1].Shares != 1 {
+		t.Errorf("workers %+v, want rig1 first with 1500 work", s.Workers)
+	}
+	if overall, rig1 := r.BestShare("rig1"); overall != 5000 || rig1 != 1500 {
+		t.Errorf("BestShare = %v, %v; want 5000, 1500", overall, rig1)
+	}
+}
+
+func TestRoundRotate(t *testing.T) {
+	r := NewRoundTracker()
+	r.RecordShare("rig1", 500, 800, 1000)
+
+	prev := r.Rotate(7, time.Now())
+	if prev.Work != 500 || prev.Effort != 50 || prev.BestWorker != "rig1" {
+		t.Errorf("ended round %+v", prev)
+	}
+	s := r.Snapshot()
+	if s.ID != 7 || s.Work != 0 || s.Shares != 0 || s.BestShare != 0 || len(s.Workers) != 0 {
+		t.Errorf("new round not empty: %+v", s)
+	}
+	// The network difficulty carries over, so the first share counts.
+	r.RecordShare("rig1", 250, 300, 0)
+	if s := r.Snapshot(); s.Effort != 25 {
+		t.Errorf("effort %v%% after rotating, want 25%%", s.Effort)
+	}
+}
+
+func TestRoundRestore(t *testing.T) {
+	r := NewRoundTracker()
+	started := time.Now().Add(-time.Hour)
+	r.Restore(3, started, 900, 90, 1000, 2, 700, "rig1", []WorkerRoundStats{
+		{Worker: "rig1", Work: 600, Shares: 1, BestShare: 700},
+		{Worker: "rig2", Work: 300, Shares: 1, BestShare: 400},
+	})
+	r.RecordShare("rig2", 100, 200, 0)
+
+	s := r.Snapshot()
+	if s.ID != 3 || s.StartedAt != started.Unix() || s.Work != 1000 || s.Shares != 3 || math.Abs(s.Effort-100) > 1e-9 {
+		t.Errorf("restored round %+v", s)
+	}
+	if _, rig2 := r.BestShare("rig2"); rig2 != 400 {
+		t.Errorf("rig2 best share %v, want 400 from before the restart", rig2)
+	}
+	if !r.EpochDue(time.Hour) || r.EpochDue(2*time.Hour) || r.EpochDue(0) {
+		t.Error("EpochDue wrong for a round an hour old")
+	}
+}
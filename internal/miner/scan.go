@@ -0,0 +1,229 @@
+package miner
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ScanOptions bounds a network scan.
+type ScanOptions struct {
+	Targets     []string // CIDRs or interface names; empty = local /24
+	Concurrency int
+	Timeout     time.Duration // per-host connect timeout
+	Budget      time.Duration // time limit for the whole scan
+	MaxHosts    int
+}
+
+// ScanProgress reports how far a scan has got.
+type ScanProgress struct {
+	Scanned   int    `json:"scanned"`
+	Total     int    `json:"total"`
+	Found     int    `json:"found"`
+	Done      bool   `json:"done"`
+	TimedOut  bool   `json:"timedOut"`  // stopped by the time budget
+	Cancelled bool   `json:"cancelled"` // stopped by the caller
+	Error     string `json:"error,omitempty"`
+}
+
+// Scan probes every host in the scan targets with each driver. It stops
+// early when ctx is cancelled or the time budget runs out, returning what
+// was found so far. progress, if set, is called after each host.
+func (d *Discovery) Scan(ctx context.Context, opts ScanOptions, progress func(ScanProgress)) ([]DiscoveredMiner, error) {
+	hosts, err := ExpandTargets(opts.Targets, opts.MaxHosts)
+	if err != nil {
+		return nil, err
+	}
+	if opts.Concurrency < 1 {
+		opts.Concurrency = 50
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = time.Second
+	}
+
+	scanCtx := ctx
+	if opts.Budget > 0 {
+		var cancel context.CancelFunc
+		scanCtx, cancel = context.WithTimeout(ctx, opts.Budget)
+		defer cancel()
+	}
+
+	var results []DiscoveredMiner
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	p := ScanProgress{Total: len(hosts)}
+	sem := make(chan struct{}, opts.Concurrency)
+
+dispatch:
+	for _, ip := range hosts {
+		select {
+		case <-scanCtx.Done():
+			break dispatch
+		case sem <- struct{}{}:
+		}
+
+		wg.Add(1)
+		go func(ip string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			m := d.scanHost(scanCtx, ip, opts.Timeout)
+
+			if m != nil {
+				d.found(*m, "scan")
+			}
+
+			// Report under the lock so progress never goes backwards.
+			mu.Lock()
+			defer mu.Unlock()
+			p.Scanned++
+			if m != nil {
+				results = append(results, *m)
+				p.Found++
+			}
+			if progress != nil {
+				progress(p)
+			}
+		}(ip)
+	}
+
+	wg.Wait()
+
+	p.Done = true
+	p.Cancelled = ctx.Err() != nil
+	p.TimedOut = !p.Cancelled && errors.Is(scanCtx.Err(), context.DeadlineExceeded)
+	if progress != nil {
+		progress(p)
+	}
+	return results, nil
+}
+
+// scanHost checks each driver's port with a bounded connect before
+// running the (slower) driver probe.
+func (d *Discovery) scanHost(ctx context.Context, ip string, timeout time.Duration) *DiscoveredMiner {
+	dialer := net.Dialer{Timeout: timeout}
+	for _, drv := range d.drivers {
+		conn, err := dialer.DialContext(ctx, "tcp", net.JoinHostPort(ip, strconv.Itoa(drv.Port())))
+		if err != nil {
+			continue
+		}
+		conn.Close()
+
+		m, err := drv.Probe(ip)
+		if err != nil {
+			continue
+		}
+		m.Driver = drv.Name()
+		d.remember(ip, drv)
+		return m
+	}
+	return nil
+}
+
+// ExpandTargets turns CIDRs and interface names into the IPv4 host
+// addresses to scan, skipping network, broadcast and local addresses. With
+// no targets it uses the /24 of the first non-loopback IPv4 address.
+func ExpandTargets(targets []string, maxHosts int) ([]string, error) {
+	var nets []*net.IPNet
+	if len(targets) == 0 {
+		localIP := net.ParseIP(getLocalIP())
+		if localIP == nil {
+			return nil, fmt.Errorf("no local IPv4 address found")
+		}
+		nets = append(nets, &net.IPNet{IP: localIP.Mask(net.CIDRMask(24, 32)), Mask: net.CIDRMask(24, 32)})
+	}
+	for _, t := range targets {
+		t = strings.TrimSpace(t)
+		if strings.Contains(t, "/") {
+			_, ipnet, err := net.ParseCIDR(t)
+			if err != nil {
+				return nil, fmt.Errorf("invalid target %q: %w", t, err)
+			}
+			if ipnet.IP.To4() == nil {
+				return nil, fmt.Errorf("target %q: only IPv4 ranges can be scanned", t)
+			}
+			nets = append(nets, ipnet)
+			continue
+		}
+		ifNets, err := interfaceNets(t)
+		if err != nil {
+			return nil, err
+		}
+		nets = append(nets, ifNets...)
+	}
+
+	local := make(map[string]bool)
+	if addrs, err := net.InterfaceAddrs(); err == nil {
+		for _, addr := range addrs {
+			if ipnet, ok := addr.(*net.IPNet); ok {
+				local[ipnet.IP.String()] = true
+			}
+		}
+	}
+
+	seen := make(map[uint32]bool)
+	var hosts []string
+	for _, ipnet := range nets {
+		ones, _ := ipnet.Mask.Size()
+		size := uint64(1) << (32 - ones)
+		if maxHosts > 0 && uint64(len(hosts))+size > uint64(maxHosts) {
+			return nil, fmt.Errorf("targets exceed the limit of %d hosts", maxHosts)
+		}
+		first := binary.BigEndian.Uint32(ipnet.IP.To4())
+		last := first + uint32(size-1)
+		if ones < 31 {
+			first++ // network address
+			last--  // broadcast address
+		}
+		for n := uint64(first); n <= uint64(last); n++ {
+			v := uint32(n)
+			if seen[v] {
+				continue
+			}
+			seen[v] = true
+			ip := make(net.IP, 4)
+			binary.BigEndian.PutUint32(ip, v)
+			if !local[ip.String()] {
+				hosts = append(hosts, ip.String())
+			}
+		}
+	}
+	return hosts, nil
+}
+
+// interfaceNets returns the IPv4 networks configured on an interface.
+func interfaceNets(name string) ([]*net.IPNet, error) {
+	iface, err := net.InterfaceByName(name)
+	if err != nil {
+		return nil, fmt.Errorf("target %q: %w", name, err)
+	}
+	addrs, err := iface.Addrs()
+	if err != nil {
+		return nil, fmt.Errorf("target %q: %w", name, err)
+	}
+	var nets []*net.IPNet
+	for _, addr := range addrs {
+		if ipnet, ok := addr.(*net.IPNet); ok && ipnet.IP.To4() != nil {
+			nets = append(nets, &net.IPNet{IP: ipnet.IP.Mask(ipnet.Mask), Mask: ipnet.Mask})
+		}
+	}
+	if len(nets) == 0 {
+		return nil, fmt.Errorf("interface %q has no IPv4 address", name)
+	}
+	return nets, nil
+}
+
+// ipLess orders IPv4 addresses numerically, others as strings.
+func ipLess(a, b string) bool {
+	ia, ib := net.ParseIP(a).To4(), net.ParseIP(b).To4()
+	if ia == nil || ib == nil {
+		return a < b
+	}
+	return binary.BigEndian.Uint32(ia) < binary.BigEndian.Uint32(ib)
+}
@@ -0,0 +1,189 @@
+package miner
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+func TestExpandTargets(t *testing.T) {
+	cases := []struct {
+		name     string
+		targets  []string
+		maxHosts int
+		want     string // first and last host, and the count
+		wantErr  bool
+	}{
+		{"/24 skips network and broadcast", []string{"198.51.100.0/24"}, 0, "198.51.100.1 198.51.100.254 254", false},
+		{"address inside the range", []string{" 198.51.100.77/28 "}, 0, "198.51.100.65 198.51.100.78 14", false},
+		{"/31 has no network address", []string{"198.51.100.4/31"}, 0, "198.51.100.4 198.51.100.5 2", false},
+		{"/32", []string{"198.51.100.9/32"}, 0, "198.51.100.9 198.51.100.9 1", false},
+		{"overlaps counted once", []string{"198.51.100.0/25", "198.51.100.0/26"}, 0, "198.51.100.1 198.51.100.126 126", false},
+		{"local address skipped", []string{"127.0.0.0/30"}, 0, "127.0.0.2 127.0.0.2 1", false},
+		{"at the limit", []string{"198.51.100.0/24"}, 256, "198.51.100.1 198.51.100.254 254", false},
+		{"over the limit", []string{"198.51.100.0/24"}, 255, "", true},
+		{"limit across targets", []string{"198.51.100.0/25", "203.0.113.0/25"}, 200, "", true},
+		{"bad CIDR", []string{"198.51.100.0/33"}, 0, "", true},
+		{"IPv6", []string{"2001:db8::/120"}, 0, "", true},
+		{"unknown interface", []string{"no-such-if0"}, 0, "", true},
+	}
+	for _, c := range cases {
+		hosts, err := ExpandTargets(c.targets, c.maxHosts)
+		if c.wantErr {
+			if err == nil {
+				t.Errorf("%s: expanded to %d hosts, want an error", c.name, len(hosts))
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: %v", c.name, err)
+			continue
+		}
+		got := strings.Join([]string{hosts[0], hosts[len(hosts)-1]}, " ") + " " + strconv.Itoa(len(hosts))
+		if got != c.want {
+			t.Errorf("%s: %s, want %s", c.name, got, c.want)
+		}
+	}
+}
+
+func TestIPLess(t *testing.T) {
+	ips := []string{"192.168.1.10", "192.168.1.9", "10.0.0.1", "192.168.1.100", "host-b", "host-a"}
+	sort.Slice(ips, func(i, j int) bool { return ipLess(ips[i], ips[j]) })
+	want := "10.0.0.1 192.168.1.9 192.168.1.10 192.168.1.100"
+	if got := strings.Join(ips[:4], " "); got != want {
+		t.Errorf("sorted %s, want %s", got, want)
+	}
+}
+
+// Hosts on loopback other than 127.0.0.1 refuse connections, so a scan
+// finds nothing but still reports every host.
+func TestScanProgress(t *testing.T) {
+	d := NewDiscovery()
+	var reports []ScanProgress
+	found, err := d.Scan(context.Background(), ScanOptions{
+		Targets: []string{"127.0.0.8/29"}, Concurrency: 2, Timeout: 200 * time.Millisecond,
+	}, func(p ScanProgress) { reports = append(reports, p) })
+	if err != nil || len(found) != 0 {
+		t.Fatalf("Scan = %v, %v; want nothing found", found, err)
+	}
+	last := reports[len(reports)-1]
+	if len(reports) != 7 || !last.Done || last.Scanned != 6 || last.Total != 6 || last.TimedOut || last.Cancelled {
+		t.Fatalf("%d reports, last %+v", len(reports), last)
+	}
+	for i := 1; i < len(reports); i++ {
+		if reports[i].Scanned < reports[i-1].Scanned {
+			t.Errorf("progress went backwards: %+v", reports)
+		}
+	}
+}
+
+func TestScanCancelled(t *testing.T) {
+	d := NewDiscovery()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	var last ScanProgress
+	if _, err := d.Scan(ctx, ScanOptions{Targets: []string{"127.0.1.0/24"}}, func(p ScanProgress) { last = p }); err != nil {
+		t.Fatal(err)
+	}
+	if !last.Done || !last.Cancelled || last.TimedOut || last.Scanned == last.Total {
+		t.Errorf("progress %+v, want a cancelled scan stopped early", last)
+	}
+}
+
+// mdnsPacket builds an mDNS message from the given records.
+func mdnsPacket(t *testing.T, response bool, answers, additionals []dnsmessage.Resource) []byte {
+	b := dnsmessage.NewBuilder(nil, dnsmessage.Header{Response: response, Authoritative: response})
+	b.EnableCompression()
+	if err := b.StartAnswers(); err != nil {
+		t.Fatal(err)
+	}
+	add := func(r dnsmessage.Resource) {
+		var err error
+		switch body := r.Body.(type) {
+		case *dnsmessage.PTRResource:
+			err = b.PTRResource(r.Header, *body)
+		case *dnsmessage.SRVResource:
+			err = b.SRVResource(r.Header, *body)
+		case *dnsmessage.AResource:
+			err = b.AResource(r.Header, *body)
+		case *dnsmessage.TXTResource:
+			err = b.TXTResource(r.Header, *body)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	for _, r := range answers {
+		add(r)
+	}
+	if err := b.StartAdditionals(); err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range additionals {
+		add(r)
+	}
+	msg, err := b.Finish()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return msg
+}
+
+func rr(name string, typ dnsmessage.Type, body dnsmessage.ResourceBody) dnsmessage.Resource {
+	return dnsmessage.Resource{
+		Header: dnsmessage.ResourceHeader{Name: dnsmessage.MustNewName(name), Type: typ, Class: dnsmessage.ClassINET, TTL: 120},
+		Body:   body,
+	}
+}
+
+func TestParseMDNSResponse(t *testing.T) {
+	ptr := rr("_http._tcp.local.", dnsmessage.TypePTR,
+		&dnsmessage.PTRResource{PTR: dnsmessage.MustNewName("bitaxe._http._tcp.local.")})
+	srv := rr("bitaxe._http._tcp.local.", dnsmessage.TypeSRV,
+		&dnsmessage.SRVResource{Port: 80, Target: dnsmessage.MustNewName("bitaxe-gamma.local.")})
+	addr := rr("bitaxe-a.local.", dnsmessage.TypeA, &dnsmessage.AResource{A: [4]byte{192, 0, 2, 10}})
+	airplay := rr("_airplay._tcp.local.", dnsmessage.TypePTR,
+		&dnsmessage.PTRResource{PTR: dnsmessage.MustNewName("tv._airplay._tcp.local.")})
+	txt := rr("bitaxe._http._tcp.local.", dnsmessage.TypeTXT, &dnsmessage.TXTResource{TXT: []string{"path=/"}})
+
+	cases := []struct {
+		name        string
+		packet      []byte
+		wantHost    string
+		wantProbing bool
+	}{
+		{"PTR with SRV as additional", mdnsPacket(t, true, []dnsmessage.Resource{ptr}, []dnsmessage.Resource{txt, srv, addr}), "bitaxe-gamma", true},
+		{"PTR with only an address", mdnsPacket(t, true, []dnsmessage.Resource{ptr}, []dnsmessage.Resource{addr}), "bitaxe-a", true},
+		{"SRV answer", mdnsPacket(t, true, []dnsmessage.Resource{srv}, nil), "bitaxe-gamma", true},
+		{"other service", mdnsPacket(t, true, []dnsmessage.Resource{airplay}, []dnsmessage.Resource{addr}), "bitaxe-a", false},
+		{"query", mdnsPacket(t, false, []dnsmessage.Resource{ptr}, nil), "", false},
+		{"garbage", []byte{1, 2, 3}, "", false},
+	}
+	for _, c := range cases {
+		host, ok := parseMDNSResponse(c.packet)
+		if ok != c.wantProbing || (ok && host != c.wantHost) {
+			t.Errorf("%s: %q, %v; want %q, %v", c.name, host, ok, c.wantHost, c.wantProbing)
+		}
+	}
+}
+
+func TestIsMDNSService(t *testing.T) {
+	cases := map[string]bool{
+		"_http._tcp.local.":              true,
+		"Bitaxe._HTTP._tcp.local.":       true,
+		"bitaxe._http._tcp.local.":       true,
+		"_https._tcp.local.":             false,
+		"my_http._tcp.local.":            false,
+		"bitaxe._http._tcp.local.extra.": false,
+	}
+	for name, want := range cases {
+		if got := isMDNSService(name); got != want {
+			t.Errorf("isMDNSService(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -34,6 +34,10 @@ type DashboardStats struct {
 	ProxySharesFwd      uint64  `json:"proxySharesFwd"`
 	ProxySharesAccepted uint64  `json:"proxySharesAccepted"`
 	ProxySharesRejected uint64  `json:"proxySharesRejected"`
+
+	// Current round
+	RoundEffort    float64 `json:"roundEffort"`
+	RoundBestShare float64 `json:"roundBestShare"`
 }
 
 // StatsAggregator collects and aggregates mining statistics.
@@ -57,6 +61,7 @@ type StatsAggregator struct {
 type shareRecord struct {
 	timestamp  time.Time
 	minerID    string
+	workerID   string
 	difficulty float64
 }
 
@@ -114,7 +119,9 @@ func (s *StatsAggregator) ClearShareRecords() {
 // RecordShare records a share for statistics.
 // difficulty is the session difficulty for qualifying shares (>= pool diff),
 // or 0 for sub-target shares. Only qualifying shares contribute to hashrate.
-func (s *StatsAggregator) RecordShare(minerID string, difficulty float64, accepted bool) {
+// workerID is the persistent worker the session belongs to, so hashrate can
+// also be estimated per worker across reconnects.
+func (s *StatsAggregator) RecordShare(minerID, workerID string, difficulty float64, accepted bool) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -125,6 +132,7 @@ func (s *StatsAggregator) RecordShare(minerID string, difficulty float64, accept
 			s.shareRecords = append(s.shareRecords, shareRecord{
 				timestamp:  time.Now(),
 				minerID:    minerID,
+				workerID:   workerID,
 				difficulty: difficulty,
 			})
 			if len(s.shareRecords) > s.maxRecords {
@@ -190,12 +198,34 @@ func (s *StatsAggregator) EstimateMinerHashrate(minerID string) float64 {
 	return s.estimateHashrateAdaptive(hashrateWindow, minerID)
 }
 
+// EstimateWorkerHashrate estimates hashrate for a persistent worker,
+// including shares from its previous sessions within the window.
+func (s *StatsAggregator) EstimateWorkerHashrate(workerID string) float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.estimateHashrate(hashrateWindow, func(r shareRecord) bool {
+		return r.workerID == workerID
+	})
+}
+
 // estimateHashrateAdaptive uses an adaptive window: during ramp-up (when the
 // miner has been active less than the full window), it uses the actual time
 // since the first share rather than the full window duration. This prevents
 // wild readings when the window is mostly empty. If minerID is empty, all
 // miners are included.
 func (s *StatsAggregator) estimateHashrateAdaptive(maxWindow time.Duration, minerID string) float64 {
+	if minerID == "" {
+		return s.estimateHashrate(maxWindow, nil)
+	}
+	return s.estimateHashrate(maxWindow, func(r shareRecord) bool {
+		return r.minerID == minerID
+	})
+}
+
+// estimateHashrate applies the adaptive window to share records accepted by
+// match (all records if match is nil).
+func (s *StatsAggregator) estimateHashrate(maxWindow time.Duration, match func(shareRecord) bool) float64 {
 	now := time.Now()
 	cutoff := now.Add(-maxWindow)
 
@@ -207,7 +237,7 @@ func (s *StatsAggregator) estimateHashrateAdaptive(maxWindow time.Duration, mine
 		if r.timestamp.Before(cutoff) {
 			break
 		}
-		if minerID != "" && r.minerID != minerID {
+		if match != nil && !match(r) {
 			continue
 		}
 		totalDiff += r.difficulty
@@ -0,0 +1,140 @@
+package miner
+
+import (
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DeviceTelemetry is one reading of a device's sensors and hashrate.
+type DeviceTelemetry struct {
+	Timestamp    int64   `json:"timestamp"`
+	IP           string  `json:"ip"`
+	Hostname     string  `json:"hostname"`
+	Model        string  `json:"model"`
+	Temp         float64 `json:"temp"`   // ASIC temperature, °C
+	VRTemp       float64 `json:"vrTemp"` // voltage regulator temperature, °C
+	Power        float64 `json:"power"`  // W
+	Voltage      float64 `json:"voltage"`
+	Current      float64 `json:"current"`
+	Frequency    float64 `json:"frequency"` // MHz
+	FanSpeed     float64 `json:"fanSpeed"`  // percent
+	FanRPM       float64 `json:"fanRpm"`
+	Hashrate     float64 `json:"hashrate"`     // device-reported, H/s
+	PoolHashrate float64 `json:"poolHashrate"` // estimated from shares, H/s
+	BestDiff     float64 `json:"bestDiff"`
+	FreeHeap     int     `json:"freeHeap"`
+}
+
+// QueryTelemetry reads the current telemetry of a device through its driver.
+func (d *Discovery) QueryTelemetry(ip string) (*DeviceTelemetry, error) {
+	drv, err := d.driverFor(ip)
+	if err != nil {
+		return nil, err
+	}
+	return drv.Telemetry(ip)
+}
+
+// CollectTelemetry queries a list of IPs concurrently. Devices that do not
+// answer are skipped.
+func (d *Discovery) CollectTelemetry(ips []string) []DeviceTelemetry {
+	var results []DeviceTelemetry
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, 16)
+
+	for _, ip := range ips {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(ip string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			t, err := d.QueryTelemetry(ip)
+			if err != nil {
+				return
+			}
+			mu.Lock()
+			results = append(results, *t)
+			mu.Unlock()
+		}(ip)
+	}
+
+	wg.Wait()
+	return results
+}
+
+// parseDiff reads a difficulty reported either as a number or as a string
+// with an SI suffix ("4.29G").
+func parseDiff(v interface{}) float64 {
+	switch x := v.(type) {
+	case float64:
+		return x
+	case string:
+		s := strings.TrimSpace(x)
+		mult := 1.0
+		if n := len(s); n > 0 {
+			switch s[n-1] {
+			case 'k', 'K':
+				mult = 1e3
+			case 'M':
+				mult = 1e6
+			case 'G':
+				mult = 1e9
+			case 'T':
+				mult = 1e12
+			case 'P':
+				mult = 1e15
+			}
+			if mult != 1 {
+				s = strings.TrimSpace(s[:n-1])
+			}
+		}
+		f, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0
+		}
+		return f * mult
+	default:
+		return 0
+	}
+}
+
+// ThresholdMonitor tracks threshold breaches per key and reports when an
+// alert should be raised (after a sustained breach) or cleared.
+type ThresholdMonitor struct {
+	breaches map[string]int
+	active   map[string]bool
+	mu       sync.Mutex
+}
+
+func NewThresholdMonitor() *ThresholdMonitor {
+	return &ThresholdMonitor{
+		breaches: make(map[string]int),
+		active:   make(map[string]bool),
+	}
+}
+
+// Check records one observation for key. An alert is raised once the
+// breach has been seen sustain times in a row, and cleared on the first
+// observation without a breach.
+func (m *ThresholdMonitor) Check(key string, breached bool, sustain int) (raised, cleared bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if !breached {
+		m.breaches[key] = 0
+		if m.active[key] {
+			delete(m.active, key)
+			return false, true
+		}
+		return false, false
+	}
+
+	m.breaches[key]++
+	if !m.active[key] && m.breaches[key] >= sustain {
+		m.active[key] = true
+		return true, false
+	}
+	return false, false
+}
@@ -0,0 +1,102 @@
+package miner
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// fakeAxeOS serves /api/system/info with the given JSON body.
+func fakeAxeOS(t *testing.T, body string) (ip string, drv *axeOSDriver) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/system/info" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(body))
+	}))
+	t.Cleanup(srv.Close)
+	return strings.TrimPrefix(srv.URL, "http://"), &axeOSDriver{client: srv.Client()}
+}
+
+func TestAxeOSTelemetry(t *testing.T) {
+	ip, drv := fakeAxeOS(t, `{"power":14.2,"voltage":5100,"current":2800,"temp":58.5,"vrTemp":47,
+		"hashRate":1050.5,"bestDiff":"4.29G","freeHeap":150000,"hostname":"bitaxe1","ASICModel":"BM1366",
+		"frequency":485,"fanspeed":60,"fanrpm":4200}`)
+	tel, err := drv.Telemetry(ip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := DeviceTelemetry{
+		Timestamp: tel.Timestamp, IP: ip, Hostname: "bitaxe1", Model: "BM1366",
+		Temp: 58.5, VRTemp: 47, Power: 14.2, Voltage: 5100, Current: 2800,
+		Frequency: 485, FanSpeed: 60, FanRPM: 4200,
+		Hashrate: 1050.5e9, BestDiff: 4.29e9, FreeHeap: 150000,
+	}
+	if *tel != want {
+		t.Errorf("telemetry %+v\nwant      %+v", *tel, want)
+	}
+}
+
+func TestAxeOSTelemetryPowerFallback(t *testing.T) {
+	ip, drv := fakeAxeOS(t, `{"voltage":5000,"current":3000,"hashRate":500,"bestDiff":123456}`)
+	tel, err := drv.Telemetry(ip)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tel.Power != 15 || tel.BestDiff != 123456 {
+		t.Errorf("power %v W, best diff %v; want 15 W from 5 V × 3 A, and 123456", tel.Power, tel.BestDiff)
+	}
+}
+
+func TestParseDiff(t *testing.T) {
+	cases := []struct {
+		in   interface{}
+		want float64
+	}{
+		{float64(512), 512},
+		{"512", 512},
+		{"1.5k", 1500},
+		{"2K", 2000},
+		{"3.2M", 3.2e6},
+		{"4.29G", 4.29e9},
+		{" 1 T ", 1e12},
+		{"2P", 2e15},
+		{"", 0},
+		{"lots", 0},
+		{nil, 0},
+		{true, 0},
+	}
+	for _, c := range cases {
+		if got := parseDiff(c.in); got != c.want {
+			t.Errorf("parseDiff(%#v) = %v, want %v", c.in, got, c.want)
+		}
+	}
+}
+
+func TestThresholdMonitor(t *testing.T) {
+	m := NewThresholdMonitor()
+	steps := []struct {
+		breached        bool
+		raised, cleared bool
+	}{
+		{true, false, false},
+		{false, false, false}, // a single reading below resets the count
+		{true, false, false},
+		{true, false, false},
+		{true, true, false}, // third in a row
+		{true, false, false},
+		{false, false, true},
+		{false, false, false},
+	}
+	for i, s := range steps {
+		raised, cleared := m.Check("10.0.0.2/temp", s.breached, 3)
+		if raised != s.raised || cleared != s.cleared {
+			t.Errorf("step %d: raised %v cleared %v, want %v %v", i, raised, cleared, s.raised, s.cleared)
+		}
+	}
+	if raised, _ := m.Check("10.0.0.3/temp", true, 1); !raised {
+		t.Error("keys are not independent")
+	}
+}
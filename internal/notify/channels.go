@@ -0,0 +1,226 @@
+package notify
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	neturl "net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"govault/internal/config"
+)
+
+const defaultTelegramAPI = "https://api.telegram.org"
+
+// newChannel builds the sender for a configured channel.
+func newChannel(cfg config.NotifyChannel, client *http.Client) (Channel, error) {
+	switch cfg.Type {
+	case "webhook":
+		return &webhookChannel{url: cfg.URL, client: client}, nil
+	case "discord":
+		return &discordChannel{url: cfg.URL, client: client}, nil
+	case "telegram":
+		base := cfg.URL
+		if base == "" {
+			base = defaultTelegramAPI
+		}
+		return &telegramChannel{base: strings.TrimRight(base, "/"), token: cfg.BotToken, chatID: cfg.ChatID, client: client}, nil
+	case "email":
+		return &emailChannel{cfg: cfg}, nil
+	default:
+		return nil, fmt.Errorf("unknown channel type: %s", cfg.Type)
+	}
+}
+
+// webhookChannel POSTs the event as JSON.
+type webhookChannel struct {
+	url    string
+	client *http.Client
+}
+
+func (c *webhookChannel) Send(ev Event) error {
+	return postJSON(c.client, c.url, ev)
+}
+
+// discordChannel posts a Discord-compatible webhook message with an embed.
+type discordChannel struct {
+	url    string
+	client *http.Client
+}
+
+type discordEmbedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+type discordEmbed struct {
+	Title       string              `json:"title"`
+	Description string              `json:"description"`
+	Color       int                 `json:"color"`
+	Timestamp   string              `json:"timestamp"`
+	Fields      []discordEmbedField `json:"fields,omitempty"`
+}
+
+func (c *discordChannel) Send(ev Event) error {
+	embed := discordEmbed{
+		Title:       ev.Title,
+		Description: ev.Message,
+		Color:       eventColor(ev.Type),
+		Timestamp:   ev.Time.UTC().Format(time.RFC3339),
+	}
+	for _, f := range ev.Fields {
+		embed.Fields = append(embed.Fields, discordEmbedField{Name: f.Name, Value: f.Value, Inline: true})
+	}
+	return postJSON(c.client, c.url, map[string]interface{}{
+		"username": "GoVault",
+		"embeds":   []discordEmbed{embed},
+	})
+}
+
+// telegramChannel sends a message through the Telegram Bot API (or any
+// server implementing its sendMessage method).
+type telegramChannel struct {
+	base   string
+	token  string
+	chatID string
+	client *http.Client
+}
+
+func (c *telegramChannel) Send(ev Event) error {
+	return postJSON(c.client, c.base+"/bot"+c.token+"/sendMessage", map[string]string{
+		"chat_id": c.chatID,
+		"text":    plainText(ev),
+	})
+}
+
+// emailChannel sends a plain-text email over SMTP, upgrading to TLS when
+// the server offers STARTTLS.
+type emailChannel struct {
+	cfg config.NotifyChannel
+}
+
+func (c *emailChannel) Send(ev Event) error {
+	addr := net.JoinHostPort(c.cfg.SMTPHost, strconv.Itoa(c.cfg.SMTPPort))
+	conn, err := net.DialTimeout("tcp", addr, 10*time.Second)
+	if err != nil {
+		return err
+	}
+	conn.SetDeadline(time.Now().Add(30 * time.Second))
+
+	client, err := smtp.NewClient(conn, c.cfg.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: c.cfg.SMTPHost}); err != nil {
+			return fmt.Errorf("starttls: %w", err)
+		}
+	}
+	if c.cfg.Username != "" {
+		auth := smtp.PlainAuth("", c.cfg.Username, c.cfg.Password, c.cfg.SMTPHost)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("auth: %w", err)
+		}
+	}
+	if err := client.Mail(c.cfg.From); err != nil {
+		return err
+	}
+	for _, to := range c.cfg.To {
+		if err := client.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", c.cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(c.cfg.To, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", headerText("[GoVault] "+ev.Title))
+	fmt.Fprintf(&msg, "Date: %s\r\n", ev.Time.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(plainText(ev), "\n", "\r\n"))
+	msg.WriteString("\r\n")
+	if _, err := w.Write(msg.Bytes()); err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// headerText makes s safe for a mail header: titles can carry worker
+// names, which miners choose, so line breaks must not start new headers.
+// Non-ASCII text is Q-encoded.
+func headerText(s string) string {
+	s = strings.NewReplacer("\r\n", " ", "\r", " ", "\n", " ").Replace(s)
+	return mime.QEncoding.Encode("utf-8", s)
+}
+
+func postJSON(client *http.Client, url string, body interface{}) error {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+	resp, err := client.Post(url, "application/json", bytes.NewReader(data))
+	if err != nil {
+		// Drop the URL: Telegram's carries the bot token, and errors end
+		// up in the log and the notification history.
+		var urlErr *neturl.Error
+		if errors.As(err, &urlErr) {
+			return fmt.Errorf("%s: %w", urlErr.Op, urlErr.Err)
+		}
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 256))
+		return fmt.Errorf("HTTP %d: %s", resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	return nil
+}
+
+// plainText renders an event for text-only channels.
+func plainText(ev Event) string {
+	var b strings.Builder
+	b.WriteString(ev.Title)
+	if ev.Message != "" {
+		b.WriteString("\n")
+		b.WriteString(ev.Message)
+	}
+	for _, f := range ev.Fields {
+		fmt.Fprintf(&b, "\n%s: %s", f.Name, f.Value)
+	}
+	return b.String()
+}
+
+// eventColor picks the Discord embed color for an event type.
+func eventColor(event string) int {
+	switch event {
+	case EventBlockFound:
+		return 0x2ecc71 // green
+	case EventMinerDisconnected, EventUpstreamDown, EventNodeOutOfSync,
+		EventWorkerMissing, EventWorkerUnderperforming, EventWorkerRejectSpike, EventDeviceAlert:
+		return 0xe74c3c // red
+	default:
+		return 0x3498db // blue
+	}
+}
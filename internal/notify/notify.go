@@ -0,0 +1,280 @@
+// Package notify delivers GoVault events (block found, miner offline, node
+// or upstream trouble) to external channels such as webhooks, Discord,
+// Telegram and email.
+package notify
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"govault/internal/config"
+	"govault/internal/logger"
+)
+
+// Event types that channels can subscribe to.
+const (
+	EventBlockFound        = "block_found"
+	EventMinerDisconnected = "miner_disconnected"
+	EventNodeOutOfSync     = "node_out_of_sync"
+	EventUpstreamDown      = "upstream_down"
+	EventTest              = "test"
+
+	// Roster events; the matching recovery is sent under the same type.
+	EventWorkerMissing         = "worker_missing"
+	EventWorkerUnderperforming = "worker_underperforming"
+	EventWorkerRejectSpike     = "worker_reject_spike"
+
+	// Device telemetry threshold raised or cleared.
+	EventDeviceAlert = "device_alert"
+
+	// Stratum server banned an IP for misbehaving.
+	EventIPBanned = "ip_banned"
+)
+
+// EventTypes lists the event types available for channel rules.
+func EventTypes() []string {
+	return []string{EventBlockFound, EventMinerDisconnected, EventNodeOutOfSync, EventUpstreamDown,
+		EventWorkerMissing, EventWorkerUnderperforming, EventWorkerRejectSpike, EventDeviceAlert, EventIPBanned}
+}
+
+// Field is a labelled detail attached to an event.
+type Field struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Event is something worth telling the user about.
+type Event struct {
+	Type    string    `json:"event"`
+	Title   string    `json:"title"`
+	Message string    `json:"message"`
+	Time    time.Time `json:"time"`
+	Fields  []Field   `json:"fields,omitempty"`
+
+	// Subject is what the event is about (worker ID, device IP), and
+	// Recovery marks the all-clear for an earlier alert of the same type.
+	// Rate limits apply per subject and direction, so one worker's alert
+	// does not hide another's, or its own recovery.
+	Subject  string `json:"subject,omitempty"`
+	Recovery bool   `json:"recovery,omitempty"`
+}
+
+// Delivery records the outcome of sending one event to one channel.
+type Delivery struct {
+	Timestamp   int64  `json:"timestamp"`
+	ChannelID   string `json:"channelId"`
+	ChannelName string `json:"channelName"`
+	Event       string `json:"event"`
+	Title       string `json:"title"`
+	Status      string `json:"status"` // "sent", "failed" or "suppressed"
+	Attempts    int    `json:"attempts"`
+	Error       string `json:"error"`
+}
+
+// Channel sends events to one destination.
+type Channel interface {
+	Send(ev Event) error
+}
+
+// retryDelays are the waits between delivery attempts.
+var retryDelays = []time.Duration{5 * time.Second, 30 * time.Second}
+
+// Notifier applies each channel's rules, rate limits and quiet hours, and
+// delivers events in the background with retries.
+type Notifier struct {
+	cfg    *config.NotificationsConfig
+	log    *logger.Logger
+	client *http.Client
+
+	// OnDelivery is called once per channel after delivery succeeds or
+	// all retries are exhausted.
+	OnDelivery func(Delivery)
+
+	lastSent map[string]time.Time // channelID + "/" + rate key
+	mu       sync.Mutex
+}
+
+func NewNotifier(cfg *config.NotificationsConfig, log *logger.Logger) *Notifier {
+	return &Notifier{
+		cfg:      cfg,
+		log:      log,
+		client:   &http.Client{Timeout: 10 * time.Second},
+		lastSent: make(map[string]time.Time),
+	}
+}
+
+// Notify delivers an event to every enabled channel whose rules allow it.
+// It never blocks on the network.
+func (n *Notifier) Notify(ev Event) {
+	if ev.Time.IsZero() {
+		ev.Time = time.Now()
+	}
+	for _, ch := range n.cfg.Channels {
+		if !ch.Enabled {
+			continue
+		}
+		rule, ok := findRule(ch, ev.Type)
+		if !ok || !rule.Enabled {
+			continue
+		}
+		if !rule.IgnoreQuietHours && inQuietHours(ch.QuietStart, ch.QuietEnd, ev.Time) {
+			n.suppressed(ch, ev, "quiet hours")
+			continue
+		}
+		if !n.allow(ch.ID, rateKey(ev), rule.MinIntervalSec, ev.Time) {
+			n.suppressed(ch, ev, "rate limited")
+			continue
+		}
+		channel, err := newChannel(ch, n.client)
+		if err != nil {
+			n.log.Errorf("notify", "channel %s: %v", ch.Name, err)
+			continue
+		}
+		go n.deliver(ch, channel, ev)
+	}
+}
+
+// SendTest sends a test event to a channel, ignoring its rules, and waits
+// for the result.
+func (n *Notifier) SendTest(channelID string) error {
+	for _, ch := range n.cfg.Channels {
+		if ch.ID != channelID {
+			continue
+		}
+		channel, err := newChannel(ch, n.client)
+		if err != nil {
+			return err
+		}
+		ev := Event{
+			Type:    EventTest,
+			Title:   "GoVault test notification",
+			Message: fmt.Sprintf("Notifications from GoVault are reaching %s.", ch.Name),
+			Time:    time.Now(),
+		}
+		err = channel.Send(ev)
+		n.record(ch, ev, 1, err)
+		return err
+	}
+	return fmt.Errorf("unknown notification channel: %s", channelID)
+}
+
+func (n *Notifier) deliver(ch config.NotifyChannel, channel Channel, ev Event) {
+	var err error
+	attempts := 0
+	for {
+		attempts++
+		if err = channel.Send(ev); err == nil || attempts > len(retryDelays) {
+			break
+		}
+		n.log.Warnf("notify", "%s to %s failed (attempt %d): %v", ev.Type, ch.Name, attempts, err)
+		time.Sleep(retryDelays[attempts-1])
+	}
+	if err != nil {
+		n.log.Errorf("notify", "%s to %s failed after %d attempts: %v", ev.Type, ch.Name, attempts, err)
+	} else {
+		n.log.Debugf("notify", "%s sent to %s", ev.Type, ch.Name)
+	}
+	n.record(ch, ev, attempts, err)
+}
+
+func (n *Notifier) record(ch config.NotifyChannel, ev Event, attempts int, err error) {
+	if n.OnDelivery == nil {
+		return
+	}
+	d := Delivery{
+		Timestamp:   time.Now().Unix(),
+		ChannelID:   ch.ID,
+		ChannelName: ch.Name,
+		Event:       ev.Type,
+		Title:       ev.Title,
+		Status:      "sent",
+		Attempts:    attempts,
+	}
+	if err != nil {
+		d.Status = "failed"
+		d.Error = err.Error()
+	}
+	n.OnDelivery(d)
+}
+
+// suppressed records an event a channel's rules held back.
+func (n *Notifier) suppressed(ch config.NotifyChannel, ev Event, reason string) {
+	n.log.Debugf("notify", "%s to %s suppressed: %s", ev.Type, ch.Name, reason)
+	if n.OnDelivery == nil {
+		return
+	}
+	n.OnDelivery(Delivery{
+		Timestamp:   time.Now().Unix(),
+		ChannelID:   ch.ID,
+		ChannelName: ch.Name,
+		Event:       ev.Type,
+		Title:       ev.Title,
+		Status:      "suppressed",
+		Error:       reason,
+	})
+}
+
+// rateKey identifies the events that share a rate limit.
+func rateKey(ev Event) string {
+	key := ev.Type + "/" + ev.Subject
+	if ev.Recovery {
+		key += "/recovery"
+	}
+	return key
+}
+
+// allow enforces the per-channel minimum interval between events with
+// the same rate key.
+func (n *Notifier) allow(channelID, rateKey string, minIntervalSec int, now time.Time) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	key := channelID + "/" + rateKey
+	if last, ok := n.lastSent[key]; ok && now.Sub(last) < time.Duration(minIntervalSec)*time.Second {
+		return false
+	}
+	n.lastSent[key] = now
+	return true
+}
+
+func findRule(ch config.NotifyChannel, event string) (config.NotifyRule, bool) {
+	for _, r := range ch.Rules {
+		if r.Event == event {
+			return r, true
+		}
+	}
+	return config.NotifyRule{}, false
+}
+
+// inQuietHours reports whether t falls between start and end ("HH:MM",
+// local time). Windows may wrap past midnight. Empty bounds disable it.
+func inQuietHours(start, end string, t time.Time) bool {
+	s, ok1 := parseClock(start)
+	e, ok2 := parseClock(end)
+	if !ok1 || !ok2 || s == e {
+		return false
+	}
+	m := t.Hour()*60 + t.Minute()
+	if s < e {
+		return m >= s && m < e
+	}
+	return m >= s || m < e
+}
+
+// parseClock parses "HH:MM" into minutes after midnight.
+func parseClock(s string) (int, bool) {
+	h, m, ok := strings.Cut(s, ":")
+	if !ok {
+		return 0, false
+	}
+	hh, err1 := strconv.Atoi(h)
+	mm, err2 := strconv.Atoi(m)
+	if err1 != nil || err2 != nil || hh < 0 || hh > 23 || mm < 0 || mm > 59 {
+		return 0, false
+	}
+	return hh*60 + mm, true
+}
@@ -0,0 +1,389 @@
+package notify
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"govault/internal/config"
+	"govault/internal/logger"
+)
+
+func newTestNotifier(t *testing.T, channels ...config.NotifyChannel) (*Notifier, chan Delivery) {
+	t.Helper()
+	log, err := logger.New(t.TempDir(), "debug")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(log.Close)
+
+	n := NewNotifier(&config.NotificationsConfig{Channels: channels}, log)
+	deliveries := make(chan Delivery, 16)
+	n.OnDelivery = func(d Delivery) { deliveries <- d }
+	return n, deliveries
+}
+
+func testWebhook(url string, rules ...config.NotifyRule) config.NotifyChannel {
+	return config.NotifyChannel{ID: "ch1", Name: "hook", Type: "webhook", Enabled: true, URL: url, Rules: rules}
+}
+
+func nextDelivery(t *testing.T, deliveries chan Delivery) Delivery {
+	t.Helper()
+	select {
+	case d := <-deliveries:
+		return d
+	case <-time.After(5 * time.Second):
+		t.Fatal("no delivery recorded")
+		return Delivery{}
+	}
+}
+
+func shortRetries(t *testing.T) {
+	saved := retryDelays
+	retryDelays = []time.Duration{time.Millisecond, time.Millisecond}
+	t.Cleanup(func() { retryDelays = saved })
+}
+
+func TestDeliverRetriesUntilSent(t *testing.T) {
+	shortRetries(t)
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			http.Error(w, "try later", http.StatusServiceUnavailable)
+		}
+	}))
+	defer srv.Close()
+
+	n, deliveries := newTestNotifier(t, testWebhook(srv.URL, config.NotifyRule{Event: EventBlockFound, Enabled: true}))
+	n.Notify(Event{Type: EventBlockFound, Title: "Block found"})
+
+	d := nextDelivery(t, deliveries)
+	if d.Status != "sent" || d.Attempts != 3 {
+		t.Errorf("got %s after %d attempts, want sent after 3", d.Status, d.Attempts)
+	}
+}
+
+func TestDeliverGivesUpAfterRetries(t *testing.T) {
+	shortRetries(t)
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "broken", http.StatusInternalServerError)
+	}))
+	defer srv.Close()
+
+	n, deliveries := newTestNotifier(t, testWebhook(srv.URL, config.NotifyRule{Event: EventBlockFound, Enabled: true}))
+	n.Notify(Event{Type: EventBlockFound, Title: "Block found"})
+
+	d := nextDelivery(t, deliveries)
+	if d.Status != "failed" || d.Attempts != 3 || !strings.Contains(d.Error, "HTTP 500") {
+		t.Errorf("got %+v, want failed after 3 attempts with HTTP 500", d)
+	}
+	if c := calls.Load(); c != 3 {
+		t.Errorf("server saw %d requests, want 3", c)
+	}
+}
+
+func TestRateLimitPerSubject(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	n, deliveries := newTestNotifier(t, testWebhook(srv.URL,
+		config.NotifyRule{Event: EventWorkerMissing, Enabled: true, MinIntervalSec: 3600}))
+
+	now := time.Now()
+	events := []struct {
+		ev   Event
+		want string
+	}{
+		{Event{Type: EventWorkerMissing, Subject: "rig1", Title: "missing rig1"}, "sent"},
+		{Event{Type: EventWorkerMissing, Subject: "rig2", Title: "missing rig2"}, "sent"},
+		{Event{Type: EventWorkerMissing, Subject: "rig1", Recovery: true, Title: "back rig1"}, "sent"},
+		{Event{Type: EventWorkerMissing, Subject: "rig1", Title: "missing rig1 again"}, "suppressed"},
+	}
+	for _, e := range events {
+		e.ev.Time = now
+		n.Notify(e.ev)
+		if d := nextDelivery(t, deliveries); d.Title != e.ev.Title || d.Status != e.want {
+			t.Errorf("%s: got %s (%s), want %s", e.ev.Title, d.Status, d.Title, e.want)
+		}
+	}
+
+	// The interval runs from the last event sent.
+	if !n.allow("ch1", rateKey(events[0].ev), 3600, now.Add(time.Hour)) {
+		t.Error("event still limited after the interval")
+	}
+}
+
+func TestQuietHoursSuppress(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	ch := testWebhook(srv.URL,
+		config.NotifyRule{Event: EventWorkerMissing, Enabled: true},
+		config.NotifyRule{Event: EventBlockFound, Enabled: true, IgnoreQuietHours: true})
+	ch.QuietStart, ch.QuietEnd = "22:00", "06:00"
+	n, deliveries := newTestNotifier(t, ch)
+
+	night := time.Date(2024, 1, 1, 23, 30, 0, 0, time.Local)
+	n.Notify(Event{Type: EventWorkerMissing, Title: "missing", Time: night})
+	if d := nextDelivery(t, deliveries); d.Status != "suppressed" || d.Error != "quiet hours" {
+		t.Errorf("got %s (%s), want suppressed for quiet hours", d.Status, d.Error)
+	}
+	n.Notify(Event{Type: EventBlockFound, Title: "block", Time: night})
+	if d := nextDelivery(t, deliveries); d.Status != "sent" {
+		t.Errorf("block found during quiet hours: got %s, want sent", d.Status)
+	}
+}
+
+func TestInQuietHours(t *testing.T) {
+	at := func(h, m int) time.Time { return time.Date(2024, 1, 1, h, m, 0, 0, time.Local) }
+	cases := []struct {
+		start, end string
+		t          time.Time
+		want       bool
+	}{
+		{"22:00", "06:00", at(23, 0), true},
+		{"22:00", "06:00", at(0, 0), true},
+		{"22:00", "06:00", at(5, 59), true},
+		{"22:00", "06:00", at(6, 0), false},
+		{"22:00", "06:00", at(12, 0), false},
+		{"22:00", "06:00", at(22, 0), true},
+		{"09:00", "17:00", at(12, 0), true},
+		{"09:00", "17:00", at(17, 0), false},
+		{"09:00", "17:00", at(3, 0), false},
+		{"", "", at(12, 0), false},
+		{"08:00", "08:00", at(8, 0), false},
+		{"25:00", "06:00", at(1, 0), false},
+	}
+	for _, c := range cases {
+		if got := inQuietHours(c.start, c.end, c.t); got != c.want {
+			t.Errorf("inQuietHours(%s, %s, %s) = %v, want %v", c.start, c.end, c.t.Format("15:04"), got, c.want)
+		}
+	}
+}
+
+// captureServer records the path and decoded JSON body of the last request.
+func captureServer(t *testing.T) (*httptest.Server, *string, *map[string]interface{}) {
+	var path string
+	var body map[string]interface{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path = r.URL.Path
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &path, &body
+}
+
+func TestDiscordPayload(t *testing.T) {
+	srv, _, body := captureServer(t)
+	ch, err := newChannel(config.NotifyChannel{Type: "discord", URL: srv.URL}, http.DefaultClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ch.Send(Event{
+		Type:    EventBlockFound,
+		Title:   "Block found at height 100",
+		Message: "Accepted",
+		Time:    time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC),
+		Fields:  []Field{{Name: "Hash", Value: "00ab"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if (*body)["username"] != "GoVault" {
+		t.Errorf("username = %v", (*body)["username"])
+	}
+	embeds, _ := (*body)["embeds"].([]interface{})
+	if len(embeds) != 1 {
+		t.Fatalf("embeds = %v", (*body)["embeds"])
+	}
+	embed := embeds[0].(map[string]interface{})
+	if embed["title"] != "Block found at height 100" || embed["description"] != "Accepted" ||
+		embed["timestamp"] != "2024-01-01T12:00:00Z" || embed["color"] != float64(0x2ecc71) {
+		t.Errorf("embed = %v", embed)
+	}
+	fields, _ := embed["fields"].([]interface{})
+	if len(fields) != 1 {
+		t.Fatalf("fields = %v", embed["fields"])
+	}
+	if f := fields[0].(map[string]interface{}); f["name"] != "Hash" || f["value"] != "00ab" || f["inline"] != true {
+		t.Errorf("field = %v", f)
+	}
+}
+
+func TestTelegramPayload(t *testing.T) {
+	srv, path, body := captureServer(t)
+	ch, err := newChannel(config.NotifyChannel{Type: "telegram", URL: srv.URL + "/", BotToken: "123:abc", ChatID: "42"}, http.DefaultClient)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ch.Send(Event{Title: "Worker missing: rig1", Message: "no shares for 10m", Fields: []Field{{Name: "Worker", Value: "rig1"}}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *path != "/bot123:abc/sendMessage" {
+		t.Errorf("path = %s", *path)
+	}
+	if (*body)["chat_id"] != "42" || (*body)["text"] != "Worker missing: rig1\nno shares for 10m\nWorker: rig1" {
+		t.Errorf("body = %v", *body)
+	}
+}
+
+func TestTelegramErrorHidesToken(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	base := "http://" + l.Addr().String()
+	l.Close() // nothing listening: the request fails in transport
+
+	ch, _ := newChannel(config.NotifyChannel{Type: "telegram", URL: base, BotToken: "123:secret", ChatID: "42"}, http.DefaultClient)
+	err = ch.Send(Event{Title: "test"})
+	if err == nil {
+		t.Fatal("send to a closed port succeeded")
+	}
+	if strings.Contains(err.Error(), "secret") {
+		t.Errorf("error leaks the bot token: %v", err)
+	}
+}
+
+// sentMail is a message captured by smtpSink.
+type sentMail struct {
+	from, data string
+	rcpt       []string
+}
+
+// smtpSink accepts one SMTP session (no STARTTLS, no AUTH) and returns an
+// email channel pointed at it and the message it receives.
+func smtpSink(t *testing.T) (Channel, chan sentMail) {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	got := make(chan sentMail, 1)
+	go func() {
+		conn, err := l.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		reply := func(s string) { io.WriteString(conn, s+"\r\n") }
+		var m sentMail
+		reply("220 sink ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.ToUpper(strings.TrimSpace(line))
+			switch {
+			case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+				reply("250 sink")
+			case strings.HasPrefix(cmd, "MAIL FROM:"):
+				m.from = strings.TrimSpace(line[len("MAIL FROM:"):])
+				reply("250 OK")
+			case strings.HasPrefix(cmd, "RCPT TO:"):
+				m.rcpt = append(m.rcpt, strings.TrimSpace(line[len("RCPT TO:"):]))
+				reply("250 OK")
+			case cmd == "DATA":
+				reply("354 go ahead")
+				var data strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				m.data = data.String()
+				reply("250 queued")
+			case cmd == "QUIT":
+				reply("221 bye")
+				got <- m
+				return
+			default:
+				reply("502 unsupported")
+			}
+		}
+	}()
+
+	host, port, _ := net.SplitHostPort(l.Addr().String())
+	portNum, _ := strconv.Atoi(port)
+	ch, err := newChannel(config.NotifyChannel{
+		Type: "email", SMTPHost: host, SMTPPort: portNum,
+		From: "govault@example.com", To: []string{"ops@example.com", "me@example.com"},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ch, got
+}
+
+func receiveMail(t *testing.T, got chan sentMail) sentMail {
+	t.Helper()
+	select {
+	case m := <-got:
+		return m
+	case <-time.After(5 * time.Second):
+		t.Fatal("no mail received")
+		return sentMail{}
+	}
+}
+
+func TestEmailDelivery(t *testing.T) {
+	ch, got := smtpSink(t)
+	if err := ch.Send(Event{Title: "Node out of sync", Message: "behind by 3 blocks", Time: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	m := receiveMail(t, got)
+	if m.from != "<govault@example.com>" || len(m.rcpt) != 2 {
+		t.Errorf("envelope from %s to %v", m.from, m.rcpt)
+	}
+	if !strings.Contains(m.data, "Subject: [GoVault] Node out of sync\r\n") ||
+		!strings.Contains(m.data, "\r\n\r\nNode out of sync\r\nbehind by 3 blocks\r\n") {
+		t.Errorf("message:\n%s", m.data)
+	}
+}
+
+func TestEmailSubjectInjection(t *testing.T) {
+	ch, got := smtpSink(t)
+	title := "Miner disconnected: rig\r\nBcc: victim@example.com\r\n\r\nforged body"
+	if err := ch.Send(Event{Title: title, Time: time.Now()}); err != nil {
+		t.Fatal(err)
+	}
+
+	m := receiveMail(t, got)
+	header, _, _ := strings.Cut(m.data, "\r\n\r\n")
+	var names []string
+	for _, line := range strings.Split(header, "\r\n") {
+		name, _, _ := strings.Cut(line, ":")
+		names = append(names, name)
+	}
+	if got := strings.Join(names, ","); got != "From,To,Subject,Date,MIME-Version,Content-Type" {
+		t.Errorf("header fields %s; header:\n%s", got, header)
+	}
+	if !strings.Contains(header, "Subject: [GoVault] Miner disconnected: rig Bcc: victim@example.com  forged body\r\n") {
+		t.Errorf("header:\n%s", header)
+	}
+}
@@ -0,0 +1,112 @@
+package stratum
+
+import (
+	"crypto/hmac"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"net"
+	"path"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"govault/internal/config"
+)
+
+// maxWorkerNameLen bounds worker names, which end up in logs, the
+// database and notifications.
+const maxWorkerNameLen = 128
+
+// pbkdf2Iterations is the work factor for new worker password hashes.
+const pbkdf2Iterations = 100_000
+
+// HashPassword returns a salted PBKDF2-SHA256 hash of a worker password in
+// the form "pbkdf2-sha256$<iterations>$<salt>$<key>".
+func HashPassword(password string) (string, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	key, err := pbkdf2.Key(sha256.New, password, salt, pbkdf2Iterations, 32)
+	if err != nil {
+		return "", err
+	}
+	enc := base64.RawStdEncoding
+	return fmt.Sprintf("pbkdf2-sha256$%d$%s$%s", pbkdf2Iterations, enc.EncodeToString(salt), enc.EncodeToString(key)), nil
+}
+
+// checkPassword reports whether password matches a HashPassword hash.
+func checkPassword(hash, password string) bool {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 4 || parts[0] != "pbkdf2-sha256" {
+		return false
+	}
+	iter, err := strconv.Atoi(parts[1])
+	if err != nil || iter < 1 {
+		return false
+	}
+	enc := base64.RawStdEncoding
+	salt, err1 := enc.DecodeString(parts[2])
+	want, err2 := enc.DecodeString(parts[3])
+	if err1 != nil || err2 != nil {
+		return false
+	}
+	got, err := pbkdf2.Key(sha256.New, password, salt, iter, len(want))
+	return err == nil && hmac.Equal(got, want)
+}
+
+// validWorkerName reports whether a mining.authorize name is usable: not
+// empty or overlong, valid UTF-8, and free of control characters.
+func validWorkerName(name string) bool {
+	if name == "" || len(name) > maxWorkerNameLen || !utf8.ValidString(name) {
+		return false
+	}
+	for _, r := range name {
+		if unicode.IsControl(r) {
+			return false
+		}
+	}
+	return true
+}
+
+// authorizeWorker checks a mining.authorize against the access rules. The
+// worker is admitted by the first rule whose pattern, networks and password
+// all match; the error describes why the closest rule refused it.
+func authorizeWorker(cfg *config.AuthConfig, worker, password, ip string) error {
+	if cfg.Mode != "restricted" {
+		return nil
+	}
+	addr := net.ParseIP(ip)
+	reason := "worker not allowed"
+	for _, r := range cfg.Rules {
+		if ok, _ := path.Match(r.Pattern, worker); !ok {
+			continue
+		}
+		if len(r.CIDRs) > 0 && !ipInCIDRs(addr, r.CIDRs) {
+			reason = "worker not allowed from " + ip
+			continue
+		}
+		if r.PasswordHash != "" && !checkPassword(r.PasswordHash, password) {
+			reason = "wrong password"
+			continue
+		}
+		return nil
+	}
+	return fmt.Errorf("%s", reason)
+}
+
+func ipInCIDRs(ip net.IP, cidrs []string) bool {
+	if ip == nil {
+		return false
+	}
+	for _, c := range cidrs {
+		if _, ipnet, err := net.ParseCIDR(c); err == nil && ipnet.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
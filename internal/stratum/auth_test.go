@@ -0,0 +1,111 @@
+package stratum
+
+import (
+	"strings"
+	"testing"
+
+	"govault/internal/config"
+)
+
+func TestValidWorkerName(t *testing.T) {
+	cases := []struct {
+		name string
+		want bool
+	}{
+		{"bc1qaddr.rig1", true},
+		{"rig-ü", true},
+		{"", false},
+		{"rig\r\nBcc: x@example.com", false},
+		{"rig\n", false},
+		{"rig\x00", false},
+		{"rig\x7f", false},
+		{"\xff\xfe", false},
+		{strings.Repeat("a", maxWorkerNameLen), true},
+		{strings.Repeat("a", maxWorkerNameLen+1), false},
+	}
+	for _, c := range cases {
+		if got := validWorkerName(c.name); got != c.want {
+			t.Errorf("validWorkerName(%q) = %v, want %v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestPasswordHash(t *testing.T) {
+	hash, err := HashPassword("s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(hash, "pbkdf2-sha256$100000$") {
+		t.Errorf("hash %q not in the stored format", hash)
+	}
+	if !checkPassword(hash, "s3cret") {
+		t.Error("hash does not match its own password")
+	}
+	if checkPassword(hash, "s3cret ") || checkPassword(hash, "") {
+		t.Error("hash matches a different password")
+	}
+	if other, _ := HashPassword("s3cret"); other == hash {
+		t.Error("two hashes of one password share a salt")
+	}
+
+	parts := strings.Split(hash, "$")
+	for _, bad := range []string{
+		"",
+		"s3cret",
+		"sha1$100000$" + parts[2] + "$" + parts[3],
+		"pbkdf2-sha256$0$" + parts[2] + "$" + parts[3],
+		"pbkdf2-sha256$many$" + parts[2] + "$" + parts[3],
+		"pbkdf2-sha256$100000$!!$" + parts[3],
+		"pbkdf2-sha256$100000$" + parts[2] + "$!!",
+		"pbkdf2-sha256$100000$" + parts[2],
+		hash + "$extra",
+	} {
+		if checkPassword(bad, "s3cret") {
+			t.Errorf("malformed hash %q accepted", bad)
+		}
+	}
+}
+
+func TestAuthorizeWorker(t *testing.T) {
+	hash, err := HashPassword("s3cret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := &config.AuthConfig{
+		Mode: "restricted",
+		Rules: []config.AuthRule{
+			{Pattern: "rig-*", CIDRs: []string{"10.0.0.0/24"}},
+			{Pattern: "vip", PasswordHash: hash},
+			{Pattern: "lab-*", PasswordHash: hash, CIDRs: []string{"192.168.1.0/24"}},
+		},
+	}
+	cases := []struct {
+		worker, password, ip string
+		want                 string // error, "" to admit
+	}{
+		{"rig-1", "", "10.0.0.5", ""},
+		{"rig-1", "", "10.0.1.5", "worker not allowed from 10.0.1.5"},
+		{"rig-1", "", "not-an-ip", "worker not allowed from not-an-ip"},
+		{"vip", "s3cret", "203.0.113.1", ""},
+		{"vip", "wrong", "203.0.113.1", "wrong password"},
+		{"lab-a", "s3cret", "192.168.1.9", ""},
+		{"lab-a", "wrong", "192.168.1.9", "wrong password"},
+		{"lab-a", "s3cret", "10.0.0.5", "worker not allowed from 10.0.0.5"},
+		{"other", "s3cret", "10.0.0.5", "worker not allowed"},
+	}
+	for _, c := range cases {
+		err := authorizeWorker(cfg, c.worker, c.password, c.ip)
+		got := ""
+		if err != nil {
+			got = err.Error()
+		}
+		if got != c.want {
+			t.Errorf("authorize %s/%s from %s: %q, want %q", c.worker, c.password, c.ip, got, c.want)
+		}
+	}
+
+	cfg.Mode = "open"
+	if err := authorizeWorker(cfg, "other", "", "10.0.1.5"); err != nil {
+		t.Errorf("open mode refused a worker: %v", err)
+	}
+}
@@ -0,0 +1,168 @@
+package stratum
+
+import (
+	"math"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"govault/internal/config"
+)
+
+// Ban is an IP the server refuses connections from.
+type Ban struct {
+	IP     string    `json:"ip"`
+	Reason string    `json:"reason"` // last offence before the ban
+	Score  int       `json:"score"`
+	Since  time.Time `json:"since"`
+	Until  time.Time `json:"until"`
+}
+
+// banList scores offences per IP and bans IPs whose score reaches the
+// configured threshold. Scores drain linearly over time.
+type banList struct {
+	mu     sync.Mutex
+	cfg    *config.BanConfig
+	scores map[string]*banScore
+	bans   map[string]Ban
+}
+
+type banScore struct {
+	points  float64
+	updated time.Time
+}
+
+func newBanList(cfg *config.BanConfig) *banList {
+	return &banList{
+		cfg:    cfg,
+		scores: make(map[string]*banScore),
+		bans:   make(map[string]Ban),
+	}
+}
+
+// allowed reports whether ip is on the allow-list.
+func (b *banList) allowed(ip string) bool {
+	return ipListed(ip, b.cfg.AllowList)
+}
+
+// ipListed reports whether ip equals or falls within one of the IP or
+// CIDR entries.
+func ipListed(ip string, entries []string) bool {
+	addr := net.ParseIP(ip)
+	if addr == nil {
+		return false
+	}
+	for _, entry := range entries {
+		if allowIP := net.ParseIP(entry); allowIP != nil {
+			if allowIP.Equal(addr) {
+				return true
+			}
+			continue
+		}
+		if _, ipnet, err := net.ParseCIDR(entry); err == nil && ipnet.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// banned reports whether ip is banned, dropping the ban if it expired.
+func (b *banList) banned(ip string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	ban, ok := b.bans[ip]
+	if ok && time.Now().After(ban.Until) {
+		delete(b.bans, ip)
+		return false
+	}
+	return ok
+}
+
+// score adds points for an offence and bans the IP if its score reaches
+// the threshold. It returns the new ban, if any.
+func (b *banList) score(ip string, points int, reason string) (Ban, bool) {
+	if !b.cfg.Enabled || points <= 0 || b.allowed(ip) {
+		return Ban{}, false
+	}
+	now := time.Now()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.bans[ip]; ok {
+		return Ban{}, false
+	}
+	sc := b.scores[ip]
+	if sc == nil {
+		sc = &banScore{}
+		b.scores[ip] = sc
+	}
+	sc.points = b.decayed(sc, now) + float64(points)
+	sc.updated = now
+
+	// Round up so decay since the previous offence does not leave the
+	// score a fraction short of the threshold.
+	score := int(math.Ceil(sc.points))
+	if score < b.cfg.Threshold {
+		if len(b.scores) > 4096 {
+			b.prune(now)
+		}
+		return Ban{}, false
+	}
+	ban := Ban{
+		IP:     ip,
+		Reason: reason,
+		Score:  score,
+		Since:  now,
+		Until:  now.Add(time.Duration(b.cfg.DurationMin) * time.Minute),
+	}
+	b.bans[ip] = ban
+	delete(b.scores, ip)
+	return ban, true
+}
+
+// list returns the active bans, soonest to expire first.
+func (b *banList) list() []Ban {
+	now := time.Now()
+	b.mu.Lock()
+	result := make([]Ban, 0, len(b.bans))
+	for ip, ban := range b.bans {
+		if now.After(ban.Until) {
+			delete(b.bans, ip)
+			continue
+		}
+		result = append(result, ban)
+	}
+	b.mu.Unlock()
+	sort.Slice(result, func(i, j int) bool { return result[i].Until.Before(result[j].Until) })
+	return result
+}
+
+// lift removes a ban and the IP's score. It reports whether a ban existed.
+func (b *banList) lift(ip string) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	_, ok := b.bans[ip]
+	delete(b.bans, ip)
+	delete(b.scores, ip)
+	return ok
+}
+
+// decayed returns a score after draining it for the time since its last
+// update. Caller holds mu.
+func (b *banList) decayed(sc *banScore, now time.Time) float64 {
+	points := sc.points - now.Sub(sc.updated).Minutes()*float64(b.cfg.DecayPerMin)
+	if points < 0 {
+		return 0
+	}
+	return points
+}
+
+// prune drops scores that have fully drained. Caller holds mu.
+func (b *banList) prune(now time.Time) {
+	for ip, sc := range b.scores {
+		if b.decayed(sc, now) == 0 {
+			delete(b.scores, ip)
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package stratum
+
+import (
+	"testing"
+	"time"
+
+	"govault/internal/config"
+)
+
+func testBanList() *banList {
+	return newBanList(&config.BanConfig{
+		Enabled:     true,
+		Threshold:   100,
+		DurationMin: 30,
+		DecayPerMin: 10,
+		AllowList:   []string{"127.0.0.1", "10.1.0.0/16"},
+	})
+}
+
+func TestBanScoring(t *testing.T) {
+	b := testBanList()
+	for i := 0; i < 4; i++ {
+		if _, banned := b.score("192.0.2.1", 20, "malformed"); banned {
+			t.Fatalf("banned after %d points", (i+1)*20)
+		}
+	}
+	ban, banned := b.score("192.0.2.1", 20, "invalid share")
+	if !banned {
+		t.Fatal("not banned at the threshold")
+	}
+	if ban.Reason != "invalid share" || ban.Score != 100 {
+		t.Errorf("ban %+v, want the last reason and score 100", ban)
+	}
+	if got := ban.Until.Sub(ban.Since); got != 30*time.Minute {
+		t.Errorf("ban lasts %v, want 30m", got)
+	}
+	if !b.banned("192.0.2.1") || b.banned("192.0.2.2") {
+		t.Error("banned() does not match the ban list")
+	}
+	if _, again := b.score("192.0.2.1", 100, "malformed"); again {
+		t.Error("a banned IP was banned again")
+	}
+}
+
+func TestBanDecay(t *testing.T) {
+	b := testBanList()
+	b.score("192.0.2.1", 90, "malformed")
+	b.scores["192.0.2.1"].updated = time.Now().Add(-2 * time.Minute) // drained by 20
+
+	if _, banned := b.score("192.0.2.1", 20, "malformed"); banned {
+		t.Error("banned although the score had decayed below the threshold")
+	}
+	// 70 + 20 = 90, then 30 more crosses the threshold.
+	if _, banned := b.score("192.0.2.1", 30, "malformed"); !banned {
+		t.Error("not banned once the decayed score reached the threshold")
+	}
+
+	b.score("192.0.2.2", 50, "malformed")
+	b.scores["192.0.2.2"].updated = time.Now().Add(-time.Hour)
+	b.prune(time.Now())
+	if _, ok := b.scores["192.0.2.2"]; ok {
+		t.Error("prune kept a fully drained score")
+	}
+}
+
+func TestBanAllowList(t *testing.T) {
+	b := testBanList()
+	for _, ip := range []string{"127.0.0.1", "10.1.2.3"} {
+		if _, banned := b.score(ip, 1000, "malformed"); banned || b.banned(ip) {
+			t.Errorf("allow-listed %s was banned", ip)
+		}
+	}
+	if _, banned := b.score("10.2.0.1", 1000, "malformed"); !banned {
+		t.Error("an IP outside the allow-listed CIDR was not banned")
+	}
+
+	b.cfg.Enabled = false
+	if _, banned := b.score("192.0.2.1", 1000, "malformed"); banned {
+		t.Error("banned with bans disabled")
+	}
+}
+
+func TestBanLiftAndExpiry(t *testing.T) {
+	b := testBanList()
+	b.score("192.0.2.1", 100, "malformed")
+	if !b.lift("192.0.2.1") {
+		t.Error("lift reported no ban")
+	}
+	if b.banned("192.0.2.1") || len(b.list()) != 0 {
+		t.Error("ban still listed after lift")
+	}
+	if b.lift("192.0.2.1") {
+		t.Error("lift reported a ban that was already lifted")
+	}
+	if _, banned := b.score("192.0.2.1", 50, "malformed"); banned {
+		t.Error("lift kept the old score")
+	}
+
+	b.score("192.0.2.2", 100, "malformed")
+	ban := b.bans["192.0.2.2"]
+	ban.Until = time.Now().Add(-time.Second)
+	b.bans["192.0.2.2"] = ban
+	if b.banned("192.0.2.2") {
+		t.Error("expired ban still in force")
+	}
+	if _, ok := b.bans["192.0.2.2"]; ok {
+		t.Error("expired ban not dropped")
+	}
+}
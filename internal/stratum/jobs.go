@@ -5,9 +5,11 @@ import (
 	"encoding/hex"
 	"fmt"
 	"govault/internal/coin"
+	"govault/internal/config"
 	"govault/internal/node"
 	"sync"
 	"sync/atomic"
+	"time"
 )
 
 // Job represents a mining job sent to miners via mining.notify.
@@ -20,18 +22,31 @@ type Job struct {
 	Version        string   // 4 bytes little-endian hex
 	NBits          string   // compact target
 	NTime          string   // 4 bytes hex
+	Height         int64    // block height being mined; 0 if unknown
+	Created        time.Time
 
 	// Internal data for block reconstruction
 	Template *node.BlockTemplate
 	SegWit   bool // whether this coin uses SegWit (for block serialization)
 }
 
+// jobSeqBits is the width of the per-run job counter. Local job IDs are
+// the start time shifted above it plus the counter, so they stay unique
+// and increasing across restarts and still fit a JSON number.
+const jobSeqBits = 20
+
 // JobManager creates and tracks mining jobs from block templates.
 type JobManager struct {
-	jobs    map[string]*Job
-	mu      sync.RWMutex
-	nextID  atomic.Uint64
-	maxJobs int
+	jobs      map[string]*Job
+	order     []*Job // oldest first
+	mu        sync.RWMutex
+	nextID    atomic.Uint64
+	retention *config.JobRetentionConfig
+
+	// The chain tip is the prevhash of the newest job; tipChanged is when
+	// a job first built on it.
+	tipPrevHash string
+	tipChanged  time.Time
 
 	payoutAddress   string
 	coinbaseTag     string
@@ -39,15 +54,17 @@ type JobManager struct {
 	coinDef         *coin.CoinDef
 }
 
-func NewJobManager(payoutAddress, coinbaseTag string, extranonce2Size int, coinDef *coin.CoinDef) *JobManager {
-	return &JobManager{
+func NewJobManager(payoutAddress, coinbaseTag string, extranonce2Size int, coinDef *coin.CoinDef, retention *config.JobRetentionConfig) *JobManager {
+	jm := &JobManager{
 		jobs:            make(map[string]*Job),
-		maxJobs:         10,
+		retention:       retention,
 		payoutAddress:   payoutAddress,
 		coinbaseTag:     coinbaseTag,
 		extranonce2Size: extranonce2Size,
 		coinDef:         coinDef,
 	}
+	jm.nextID.Store(uint64(time.Now().Unix()) << jobSeqBits)
+	return jm
 }
 
 func (jm *JobManager) SetPayoutAddress(addr string) {
@@ -107,26 +124,14 @@ func (jm *JobManager) CreateJob(tmpl *node.BlockTemplate, extranonce1Size int) (
 		Version:        version,
 		NBits:          tmpl.Bits,
 		NTime:          ntime,
+		Height:         tmpl.Height,
+		Created:        time.Now(),
 		Template:       tmpl,
 		SegWit:         jm.coinDef.SegWit,
 	}
 
 	jm.mu.Lock()
-	jm.jobs[jobID] = job
-	// Trim old jobs
-	if len(jm.jobs) > jm.maxJobs {
-		var oldest string
-		var oldestID uint64 = ^uint64(0)
-		for id := range jm.jobs {
-			var idNum uint64
-			fmt.Sscanf(id, "%x", &idNum)
-			if idNum < oldestID {
-				oldestID = idNum
-				oldest = id
-			}
-		}
-		delete(jm.jobs, oldest)
-	}
+	jm.add(job)
 	jm.mu.Unlock()
 
 	return job, nil
@@ -154,6 +159,8 @@ func (jm *JobManager) RegisterUpstreamJob(
 		Version:        version,
 		NBits:          nbits,
 		NTime:          ntime,
+		Height:         coinbaseHeight(coinbase1),
+		Created:        time.Now(),
 		Template:       nil, // proxy mode: no local template
 	}
 
@@ -161,23 +168,7 @@ func (jm *JobManager) RegisterUpstreamJob(
 	// Note: do NOT wipe jobs on cleanJobs — the flag tells miners to abandon
 	// old work, but we must keep old jobs so in-flight ASIC shares (pipeline
 	// delay) still find their job data and get validated for hashrate tracking.
-	jm.jobs[jobID] = job
-	// Trim old jobs (same as CreateJob)
-	if len(jm.jobs) > jm.maxJobs {
-		var oldest string
-		var oldestID uint64 = ^uint64(0)
-		for id := range jm.jobs {
-			var idNum uint64
-			fmt.Sscanf(id, "%x", &idNum)
-			if idNum < oldestID {
-				oldestID = idNum
-				oldest = id
-			}
-		}
-		if oldest != "" && oldest != jobID {
-			delete(jm.jobs, oldest)
-		}
-	}
+	jm.add(job)
 	jm.mu.Unlock()
 
 	return job
@@ -189,6 +180,54 @@ func (jm *JobManager) GetJob(id string) *Job {
 	return jm.jobs[id]
 }
 
+// add stores a new job and evicts jobs past retention. jm.mu must be held.
+func (jm *JobManager) add(job *Job) {
+	jm.jobs[job.ID] = job
+	jm.order = append(jm.order, job)
+	jm.setTip(job)
+	jm.evict(job.Created)
+}
+
+// evict drops the oldest jobs while they are past retention: older than
+// MaxAgeSec, on a tip replaced more than StaleGraceSec ago, or beyond
+// MaxJobs. Jobs are issued in order, so the expired ones are always at the
+// front. The newest job is always kept. jm.mu must be held.
+func (jm *JobManager) evict(now time.Time) {
+	maxAge := time.Duration(jm.retention.MaxAgeSec) * time.Second
+	grace := time.Duration(jm.retention.StaleGraceSec) * time.Second
+	n := 0
+	for ; n < len(jm.order)-1; n++ {
+		job := jm.order[n]
+		expired := now.Sub(job.Created) > maxAge ||
+			len(jm.order)-n > jm.retention.MaxJobs ||
+			(job.PrevHash != jm.tipPrevHash && now.Sub(jm.tipChanged) > grace)
+		if !expired {
+			break
+		}
+		// An upstream pool may reuse a job ID; keep the newer job.
+		if jm.jobs[job.ID] == job {
+			delete(jm.jobs, job.ID)
+		}
+		jm.order[n] = nil
+	}
+	jm.order = jm.order[n:]
+}
+
+// setTip records a job's prevhash as the chain tip. jm.mu must be held.
+func (jm *JobManager) setTip(job *Job) {
+	if job.PrevHash != jm.tipPrevHash {
+		jm.tipPrevHash = job.PrevHash
+		jm.tipChanged = time.Now()
+	}
+}
+
+// Tip returns the prevhash new jobs build on and when it last changed.
+func (jm *JobManager) Tip() (prevHash string, changed time.Time) {
+	jm.mu.RLock()
+	defer jm.mu.RUnlock()
+	return jm.tipPrevHash, jm.tipChanged
+}
+
 func (jm *JobManager) ActiveJobIDs() map[string]bool {
 	jm.mu.RLock()
 	defer jm.mu.RUnlock()
@@ -202,6 +241,7 @@ func (jm *JobManager) ActiveJobIDs() map[string]bool {
 func (jm *JobManager) CleanJobs() {
 	jm.mu.Lock()
 	jm.jobs = make(map[string]*Job)
+	jm.order = nil
 	jm.mu.Unlock()
 }
 
@@ -394,6 +434,35 @@ func encodeHeight(height int64) []byte {
 	return result
 }
 
+// coinbaseHeight reads the BIP34 block height from the start of the
+// coinbase scriptSig in coinbase1. It returns 0 if there is none.
+func coinbaseHeight(coinbase1 string) int64 {
+	tx, err := hex.DecodeString(coinbase1)
+	// version (4) + input count (1) + prevout (36) + scriptSig length (1)
+	if err != nil || len(tx) < 43 || tx[4] != 0x01 {
+		return 0
+	}
+	script := tx[42:]
+	if tx[41] == 0xfd {
+		if len(tx) < 45 {
+			return 0
+		}
+		script = tx[44:]
+	}
+	n := int(script[0])
+	switch {
+	case n >= 0x51 && n <= 0x60: // OP_1 through OP_16
+		return int64(n - 0x50)
+	case n < 1 || n > 8 || len(script) < 1+n:
+		return 0
+	}
+	var height int64
+	for i := n; i >= 1; i-- {
+		height = height<<8 | int64(script[i])
+	}
+	return height
+}
+
 // appendVarBytes appends a variable-length byte slice with its compact size prefix.
 func appendVarBytes(buf []byte, data []byte) []byte {
 	buf = appendCompactSize(buf, uint64(len(data)))
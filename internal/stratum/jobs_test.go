@@ -0,0 +1,119 @@
+package stratum
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"govault/internal/config"
+)
+
+// addTestJob registers an upstream job on prevHash created at created.
+func addTestJob(jm *JobManager, id, prevHash string, created time.Time) *Job {
+	job := jm.RegisterUpstreamJob(id, prevHash, "", "", nil, "20000000", "1d00ffff", "00000000", false)
+	jm.mu.Lock()
+	job.Created = created
+	jm.mu.Unlock()
+	return job
+}
+
+func jobIDs(jm *JobManager) string {
+	jm.mu.RLock()
+	defer jm.mu.RUnlock()
+	ids := make([]string, len(jm.order))
+	for i, job := range jm.order {
+		ids[i] = job.ID
+	}
+	return strings.Join(ids, " ")
+}
+
+func TestJobEviction(t *testing.T) {
+	now := time.Now()
+	cases := []struct {
+		name string
+		ret  config.JobRetentionConfig
+		add  func(jm *JobManager)
+		want string
+	}{
+		{"by age", config.JobRetentionConfig{MaxAgeSec: 600, StaleGraceSec: 60, MaxJobs: 100}, func(jm *JobManager) {
+			addTestJob(jm, "a", "tip", now.Add(-11*time.Minute))
+			addTestJob(jm, "b", "tip", now.Add(-9*time.Minute))
+			addTestJob(jm, "c", "tip", now)
+		}, "b c"},
+		{"newest kept however old", config.JobRetentionConfig{MaxAgeSec: 600, StaleGraceSec: 60, MaxJobs: 100}, func(jm *JobManager) {
+			addTestJob(jm, "a", "tip", now.Add(-time.Hour))
+			jm.mu.Lock()
+			jm.evict(now)
+			jm.mu.Unlock()
+		}, "a"},
+		{"by count", config.JobRetentionConfig{MaxAgeSec: 600, StaleGraceSec: 60, MaxJobs: 3}, func(jm *JobManager) {
+			for i := 0; i < 5; i++ {
+				addTestJob(jm, fmt.Sprint(i), "tip", now)
+			}
+		}, "2 3 4"},
+		{"old tip within grace", config.JobRetentionConfig{MaxAgeSec: 600, StaleGraceSec: 60, MaxJobs: 100}, func(jm *JobManager) {
+			addTestJob(jm, "a", "old", now)
+			addTestJob(jm, "b", "new", now)
+		}, "a b"},
+		{"old tip past grace", config.JobRetentionConfig{MaxAgeSec: 600, StaleGraceSec: 60, MaxJobs: 100}, func(jm *JobManager) {
+			addTestJob(jm, "a", "old", now)
+			addTestJob(jm, "b", "new", now)
+			jm.mu.Lock()
+			jm.tipChanged = now.Add(-61 * time.Second)
+			jm.mu.Unlock()
+			addTestJob(jm, "c", "new", now)
+		}, "b c"},
+		{"reused id keeps the newer job", config.JobRetentionConfig{MaxAgeSec: 600, StaleGraceSec: 60, MaxJobs: 2}, func(jm *JobManager) {
+			addTestJob(jm, "a", "tip", now)
+			addTestJob(jm, "b", "tip", now)
+			addTestJob(jm, "a", "tip", now)
+		}, "b a"},
+	}
+	for _, c := range cases {
+		jm := NewJobManager("", "", 4, nil, &c.ret)
+		c.add(jm)
+		if got := jobIDs(jm); got != c.want {
+			t.Errorf("%s: kept %q, want %q", c.name, got, c.want)
+		}
+		for _, id := range strings.Fields(c.want) {
+			if jm.GetJob(id) == nil {
+				t.Errorf("%s: job %s kept in order but not by ID", c.name, id)
+			}
+		}
+		if n := len(jm.ActiveJobIDs()); n != len(strings.Fields(c.want)) {
+			t.Errorf("%s: %d jobs by ID, want %d", c.name, n, len(strings.Fields(c.want)))
+		}
+	}
+}
+
+func TestCoinbaseHeight(t *testing.T) {
+	// version, one input, null prevout, then the scriptSig
+	prefix := "02000000" + "01" + strings.Repeat("00", 32) + "ffffffff"
+	cases := []struct {
+		name      string
+		coinbase1 string
+		want      int64
+	}{
+		{"1-byte push", prefix + "0d" + "0164", 100},
+		{"2-byte push", prefix + "0d" + "02e803", 1000},
+		{"3-byte push", prefix + "0d" + "03a08601", 100000},
+		{"3-byte push, mainnet", prefix + "0d" + "03d3c90d", 903635},
+		{"OP_1", prefix + "0d" + "51", 1},
+		{"OP_16", prefix + "0d" + "60", 16},
+		{"long script length", prefix + "fd0001" + "03a08601", 100000},
+		{"not hex", prefix + "0d" + "03a0860", 0},
+		{"too short", prefix[:20], 0},
+		{"no script", prefix + "0d", 0},
+		{"two inputs", "02000000" + "02" + prefix[10:] + "0d" + "0164", 0},
+		{"push past the data", prefix + "0d" + "03a086", 0},
+		{"oversized push", prefix + "0d" + "09" + strings.Repeat("01", 9), 0},
+		{"OP_0", prefix + "0d" + "00", 0},
+		{"truncated long length", prefix + "fd00", 0},
+	}
+	for _, c := range cases {
+		if got := coinbaseHeight(c.coinbase1); got != c.want {
+			t.Errorf("%s: coinbaseHeight = %d, want %d", c.name, got, c.want)
+		}
+	}
+}
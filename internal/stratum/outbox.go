@@ -0,0 +1,178 @@
+package stratum
+
+import (
+	"sync"
+	"time"
+)
+
+const (
+	outboxSize        = 64               // queued messages before a session counts as backed up
+	outboxMax         = 1024             // queued messages that disconnect at once
+	slowConsumerAfter = 30 * time.Second // backed up this long → disconnect
+	writeTimeout      = 10 * time.Second
+)
+
+// outMsg is a queued message. Notifies are encoded when written, so one
+// that replaces older queued notifies can carry their clean flag.
+type outMsg struct {
+	data   []byte
+	job    *Job
+	clean  bool
+	queued time.Time
+}
+
+// outbox is a session's outbound queue, drained by writeLoop so a slow
+// miner never blocks the goroutine sending to it.
+type outbox struct {
+	mu        sync.Mutex
+	items     []outMsg
+	wake      chan struct{}
+	closed    bool
+	fullSince time.Time // zero while fewer than outboxSize are queued
+	dropped   uint64    // notifies replaced before they were written
+	worker    string    // session's worker name, for log lines
+
+	// Enqueue-to-write time of notifies, in milliseconds
+	latencyAvg float64 // moving average
+	latencyMax float64
+}
+
+func newOutbox() outbox {
+	return outbox{wake: make(chan struct{}, 1)}
+}
+
+// enqueue queues a message without blocking. A notify replaces any
+// notifies still queued: the miner only needs the newest job. Nothing else
+// is ever dropped, since the miner waits on every response. A miner that
+// stays backed up for slowConsumerAfter, or lets outboxMax messages pile
+// up, is disconnected instead.
+func (s *Session) enqueue(m outMsg) {
+	o := &s.out
+	m.queued = time.Now()
+
+	o.mu.Lock()
+	if o.closed {
+		o.mu.Unlock()
+		return
+	}
+	if m.job != nil {
+		kept := o.items[:0]
+		for _, it := range o.items {
+			if it.job != nil {
+				m.clean = m.clean || it.clean
+				o.dropped++
+				continue
+			}
+			kept = append(kept, it)
+		}
+		o.items = kept
+	}
+	o.items = append(o.items, m)
+	depth := len(o.items)
+	var filled, slow bool
+	if depth >= outboxSize {
+		filled = o.fullSince.IsZero()
+		if filled {
+			o.fullSince = m.queued
+		}
+		slow = depth >= outboxMax || m.queued.Sub(o.fullSince) >= slowConsumerAfter
+	} else {
+		o.fullSince = time.Time{}
+	}
+	worker := o.worker
+	o.mu.Unlock()
+
+	switch {
+	case slow:
+		s.server.log.Warnf("stratum", "disconnecting slow miner %s (%s): %d messages unsent", worker, s.ip, depth)
+		s.conn.Close()
+		return
+	case filled:
+		s.server.log.Warnf("stratum", "send queue backed up for miner %s (%s)", worker, s.ip)
+	}
+
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+// writeLoop writes queued messages until the outbox is closed or a write
+// fails, which closes the connection.
+func (s *Session) writeLoop() {
+	o := &s.out
+	for {
+		o.mu.Lock()
+		for len(o.items) == 0 && !o.closed {
+			o.mu.Unlock()
+			<-o.wake
+			o.mu.Lock()
+		}
+		if o.closed {
+			o.mu.Unlock()
+			return
+		}
+		batch := o.items
+		o.items = nil
+		o.mu.Unlock()
+
+		for _, m := range batch {
+			data := m.data
+			if m.job != nil {
+				data = encodeNotify(m.job, m.clean)
+			}
+			s.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+			if _, err := s.conn.Write(data); err != nil {
+				s.server.log.Debugf("stratum", "session %s write failed: %v", s.ID, err)
+				s.conn.Close()
+				return
+			}
+			if m.job != nil {
+				s.recordLatency(time.Since(m.queued))
+			}
+		}
+	}
+}
+
+// setOutboxWorker names the session's worker in outbox log lines.
+func (s *Session) setOutboxWorker(name string) {
+	s.out.mu.Lock()
+	s.out.worker = name
+	s.out.mu.Unlock()
+}
+
+// closeOutbox stops writeLoop and discards anything still queued.
+func (s *Session) closeOutbox() {
+	o := &s.out
+	o.mu.Lock()
+	o.closed = true
+	o.items = nil
+	o.mu.Unlock()
+	select {
+	case o.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Session) recordLatency(d time.Duration) {
+	ms := float64(d) / float64(time.Millisecond)
+	o := &s.out
+	o.mu.Lock()
+	if o.latencyAvg == 0 {
+		o.latencyAvg = ms
+	} else {
+		o.latencyAvg = 0.8*o.latencyAvg + 0.2*ms
+	}
+	if ms > o.latencyMax {
+		o.latencyMax = ms
+	}
+	o.mu.Unlock()
+}
+
+// deliveryStats returns the job latency, queue depth and replaced notifies.
+func (s *Session) deliveryStats() (avg, max float64, depth int, dropped uint64) {
+	o := &s.out
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.latencyAvg, o.latencyMax, len(o.items), o.dropped
+}
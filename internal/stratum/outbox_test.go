@@ -0,0 +1,116 @@
+package stratum
+
+import (
+	"fmt"
+	"net"
+	"testing"
+	"time"
+
+	"govault/internal/logger"
+)
+
+// newQueueSession returns a session whose outbox is not being drained, and
+// the far end of its connection.
+func newQueueSession(t *testing.T) (*Session, net.Conn) {
+	t.Helper()
+	log, err := logger.New(t.TempDir(), "error")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(log.Close)
+	local, remote := net.Pipe()
+	t.Cleanup(func() { local.Close(); remote.Close() })
+	return &Session{server: &Server{log: log}, conn: local, ip: "192.0.2.1", out: newOutbox()}, remote
+}
+
+func TestOutboxCoalescesNotifies(t *testing.T) {
+	s, _ := newQueueSession(t)
+	s.sendNotify(&Job{ID: "1"}, true)
+	s.send([]byte("response\n"))
+	s.sendNotify(&Job{ID: "2"}, false)
+	s.sendNotify(&Job{ID: "3"}, false)
+
+	items := s.out.items
+	if len(items) != 2 || items[0].job != nil || items[1].job.ID != "3" {
+		t.Fatalf("queue %+v, want the response then job 3", items)
+	}
+	if !items[1].clean {
+		t.Error("replacing a clean notify lost its clean flag")
+	}
+	if _, _, depth, dropped := s.deliveryStats(); depth != 2 || dropped != 2 {
+		t.Errorf("depth %d, dropped %d; want 2 and 2", depth, dropped)
+	}
+}
+
+func TestOutboxKeepsResponsesWhenBackedUp(t *testing.T) {
+	s, _ := newQueueSession(t)
+	s.sendNotify(&Job{ID: "1"}, true)
+	for i := 0; i < outboxSize+10; i++ {
+		s.send([]byte(fmt.Sprintf("response %d\n", i)))
+	}
+	s.sendNotify(&Job{ID: "2"}, false)
+
+	responses, jobs := 0, 0
+	for _, it := range s.out.items {
+		if it.job != nil {
+			jobs++
+		} else {
+			responses++
+		}
+	}
+	if responses != outboxSize+10 || jobs != 1 {
+		t.Errorf("queued %d responses and %d jobs, want %d and 1", responses, jobs, outboxSize+10)
+	}
+	if s.out.fullSince.IsZero() {
+		t.Error("backed-up queue not marked full")
+	}
+}
+
+func TestOutboxDisconnectsSlowConsumer(t *testing.T) {
+	cases := []struct {
+		name  string
+		setup func(s *Session)
+	}{
+		{"backed up too long", func(s *Session) {
+			for i := 0; i < outboxSize; i++ {
+				s.send([]byte("x\n"))
+			}
+			s.out.fullSince = time.Now().Add(-slowConsumerAfter)
+		}},
+		{"too many queued", func(s *Session) {
+			for i := 0; i < outboxMax-1; i++ {
+				s.send([]byte("x\n"))
+			}
+		}},
+	}
+	for _, c := range cases {
+		s, remote := newQueueSession(t)
+		c.setup(s)
+		s.send([]byte("last\n"))
+
+		remote.SetReadDeadline(time.Now().Add(time.Second))
+		if _, err := remote.Read(make([]byte, 1)); err == nil || isTimeout(err) {
+			t.Errorf("%s: connection still open (%v)", c.name, err)
+		}
+	}
+}
+
+func TestOutboxRecoversAfterDrain(t *testing.T) {
+	s, _ := newQueueSession(t)
+	for i := 0; i < outboxSize; i++ {
+		s.send([]byte("x\n"))
+	}
+	if s.out.fullSince.IsZero() {
+		t.Fatal("queue not marked full")
+	}
+	s.out.items = nil // written out
+	s.send([]byte("y\n"))
+	if !s.out.fullSince.IsZero() {
+		t.Error("queue still marked full after draining")
+	}
+}
+
+func isTimeout(err error) bool {
+	ne, ok := err.(net.Error)
+	return ok && ne.Timeout()
+}
@@ -15,6 +15,16 @@ const (
 	ErrNotSubscribed = 25
 )
 
+// Share rejects from consensus checks: a block built from such a share
+// would be refused by the node. These extend the V1 set; miners treat
+// unknown codes like ErrOther.
+const (
+	ErrNTimeRange  = 30 // ntime before the template's mintime or too far ahead
+	ErrVersionMask = 31 // rolled version bits outside the negotiated mask
+	ErrExtranonce2 = 32 // extranonce2 not hex or the wrong length
+	ErrNonceLength = 33 // nonce not 4 bytes of hex
+)
+
 // Request is a JSON-RPC request from a miner.
 type Request struct {
 	ID     interface{}       `json:"id"`
@@ -0,0 +1,124 @@
+package stratum
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"net"
+	"strings"
+	"testing"
+
+	"govault/internal/config"
+)
+
+// proxyV2 builds a v2 header with the given command, family and body.
+func proxyV2(cmd, family byte, body []byte) string {
+	var b bytes.Buffer
+	b.Write(proxyV2Sig)
+	b.WriteByte(0x20 | cmd)
+	b.WriteByte(family)
+	binary.Write(&b, binary.BigEndian, uint16(len(body)))
+	b.Write(body)
+	return b.String()
+}
+
+// proxyV2Addrs returns a v2 address block: source, destination, ports.
+func proxyV2Addrs(src, dst net.IP, sport, dport uint16) []byte {
+	var b bytes.Buffer
+	b.Write(src)
+	b.Write(dst)
+	binary.Write(&b, binary.BigEndian, sport)
+	binary.Write(&b, binary.BigEndian, dport)
+	return b.Bytes()
+}
+
+func TestReadProxyHeader(t *testing.T) {
+	v4 := proxyV2Addrs(net.ParseIP("203.0.113.7").To4(), net.ParseIP("192.0.2.1").To4(), 4321, 3333)
+	v6 := proxyV2Addrs(net.ParseIP("2001:db8::7"), net.ParseIP("2001:db8::1"), 4321, 3333)
+	withTLV := append(append([]byte{}, v4...), 0x04, 0x00, 0x02, 'h', 'i')
+
+	cases := []struct {
+		name    string
+		input   string
+		want    string // source address, "" for none
+		wantErr bool
+		rest    string // what must be left for the session to read
+	}{
+		{name: "v1 tcp4", input: "PROXY TCP4 203.0.113.7 192.0.2.1 4321 3333\r\n{}", want: "203.0.113.7:4321", rest: "{}"},
+		{name: "v1 tcp6", input: "PROXY TCP6 2001:db8::7 2001:db8::1 4321 3333\r\n{}", want: "[2001:db8::7]:4321", rest: "{}"},
+		{name: "v1 unknown", input: "PROXY UNKNOWN\r\n{}", rest: "{}"},
+		{name: "v1 unknown with addresses", input: "PROXY UNKNOWN ffff::1 ffff::2 1 2\r\n{}", rest: "{}"},
+		{name: "v1 family mismatch", input: "PROXY TCP4 2001:db8::7 2001:db8::1 4321 3333\r\n", wantErr: true},
+		{name: "v1 bad port", input: "PROXY TCP4 203.0.113.7 192.0.2.1 70000 3333\r\n", wantErr: true},
+		{name: "v1 missing fields", input: "PROXY TCP4 203.0.113.7\r\n", wantErr: true},
+		{name: "v1 bare newline", input: "PROXY TCP4 203.0.113.7 192.0.2.1 4321 3333\n", wantErr: true},
+		{name: "v1 truncated", input: "PROXY TCP4 203.0.113.7", wantErr: true},
+		{name: "v1 oversized", input: "PROXY TCP4 " + strings.Repeat("1", proxyV1MaxLen) + "\r\n", wantErr: true},
+		{name: "v1 wrong signature", input: "PROXZ TCP4 203.0.113.7 192.0.2.1 4321 3333\r\n", wantErr: true},
+		{name: "v2 proxy tcp4", input: proxyV2(0x1, 0x11, v4) + "{}", want: "203.0.113.7:4321", rest: "{}"},
+		{name: "v2 proxy tcp6", input: proxyV2(0x1, 0x21, v6) + "{}", want: "[2001:db8::7]:4321", rest: "{}"},
+		{name: "v2 with tlvs", input: proxyV2(0x1, 0x11, withTLV) + "{}", want: "203.0.113.7:4321", rest: "{}"},
+		{name: "v2 local", input: proxyV2(0x0, 0x00, nil) + "{}", rest: "{}"},
+		{name: "v2 udp", input: proxyV2(0x1, 0x12, v4) + "{}", rest: "{}"},
+		{name: "v2 unknown command", input: proxyV2(0x2, 0x11, v4), wantErr: true},
+		{name: "v2 short address block", input: proxyV2(0x1, 0x11, v4[:8]), wantErr: true},
+		{name: "v2 truncated body", input: proxyV2(0x1, 0x11, v4)[:20], wantErr: true},
+		{name: "v2 truncated signature", input: string(proxyV2Sig[:6]), wantErr: true},
+		{name: "v2 wrong signature", input: "\r\n\r\n\x00\r\nQUIZ\n\x21\x11\x00\x00", wantErr: true},
+		{name: "v2 wrong version", input: "\r\n\r\n\x00\r\nQUIT\n\x11\x11\x00\x00", wantErr: true},
+		{name: "no header", input: `{"id":1}`, rest: `{"id":1}`},
+	}
+	for _, c := range cases {
+		r := bufio.NewReaderSize(strings.NewReader(c.input), 4096)
+		addr, err := readProxyHeader(r)
+		if (err != nil) != c.wantErr {
+			t.Errorf("%s: error %v, want error %v", c.name, err, c.wantErr)
+			continue
+		}
+		if c.wantErr {
+			continue
+		}
+		got := ""
+		if addr != nil {
+			got = addr.String()
+		}
+		if got != c.want {
+			t.Errorf("%s: source %q, want %q", c.name, got, c.want)
+		}
+		var rest bytes.Buffer
+		rest.ReadFrom(r)
+		if rest.String() != c.rest {
+			t.Errorf("%s: left %q, want %q", c.name, rest.String(), c.rest)
+		}
+	}
+}
+
+// A PROXY header is honoured from a trusted relay only. From anyone else
+// it is just a malformed line, and the peer address stands.
+func TestProxyHeaderTrust(t *testing.T) {
+	cases := []struct {
+		name    string
+		trusted string
+		want    string
+	}{
+		{"trusted relay", "127.0.0.0/8", "203.0.113.7"},
+		{"untrusted source", "192.0.2.1", "127.0.0.1"},
+	}
+	for _, c := range cases {
+		s := newTestServer(t, func(cfg *config.Config) {
+			cfg.Stratum.ProxyProtocol = config.ProxyProtocolConfig{Enabled: true, Trusted: []string{c.trusted}}
+		})
+		startTestServer(t, s)
+
+		m := dialTest(t, s)
+		m.conn.Write([]byte("PROXY TCP4 203.0.113.7 192.0.2.1 4321 3333\r\n"))
+		m.login("rig")
+		sessions := s.GetSessions()
+		if len(sessions) != 1 {
+			t.Fatalf("%s: %d sessions, want 1", c.name, len(sessions))
+		}
+		if host, _, _ := net.SplitHostPort(sessions[0].IPAddress); host != c.want {
+			t.Errorf("%s: session from %s, want %s", c.name, sessions[0].IPAddress, c.want)
+		}
+	}
+}
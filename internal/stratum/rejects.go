@@ -0,0 +1,60 @@
+package stratum
+
+import "time"
+
+// Reject codes classify rejected shares for storage and analytics.
+const (
+	RejectStaleJob     = "stale-job"       // job unknown or already evicted
+	RejectStaleBlock   = "stale-block"     // job built on a replaced tip
+	RejectDuplicate    = "duplicate"       // same share submitted twice
+	RejectLowDiff      = "low-difficulty"  // below the session difficulty
+	RejectMalformed    = "malformed"       // failed parsing or consensus checks
+	RejectUnauthorized = "unauthorized"    // submitted before authorizing
+	RejectUpstream     = "upstream-reject" // refused by the upstream pool
+)
+
+// ShareAge is how old the work behind a share was when it arrived.
+type ShareAge struct {
+	Job   time.Duration // since the job was issued; 0 if the job is unknown
+	Clean time.Duration // since the last clean job; 0 before the first one
+}
+
+// ShareReject describes a rejected share.
+type ShareReject struct {
+	Code   string // one of the Reject* codes
+	Detail string // the job ID, failed check or pool error ("<code> <message>")
+	Age    ShareAge
+	TipAge time.Duration // stale-block only: since the chain tip changed
+}
+
+// IsStale reports whether the share was for outdated work rather than bad.
+func (r ShareReject) IsStale() bool {
+	return r.Code == RejectStaleJob || r.Code == RejectStaleBlock
+}
+
+// rejectCode classifies a Stratum error returned for a share.
+func rejectCode(e *StratumError) string {
+	switch e.Code {
+	case ErrStaleJob:
+		return RejectStaleJob
+	case ErrDuplicate:
+		return RejectDuplicate
+	case ErrLowDifficulty:
+		return RejectLowDiff
+	case ErrUnauthorized, ErrNotSubscribed:
+		return RejectUnauthorized
+	}
+	return RejectMalformed
+}
+
+// shareAge returns the age of a job and of the last clean job at t.
+func (s *Server) shareAge(jobID string, t time.Time) ShareAge {
+	var age ShareAge
+	if job := s.jobManager.GetJob(jobID); job != nil {
+		age.Job = t.Sub(job.Created)
+	}
+	if clean := s.lastClean.Load(); clean != 0 {
+		age.Clean = t.Sub(time.Unix(0, clean))
+	}
+	return age
+}
@@ -0,0 +1,59 @@
+package stratum
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRejectCode(t *testing.T) {
+	cases := []struct {
+		code int
+		want string
+	}{
+		{ErrStaleJob, RejectStaleJob},
+		{ErrDuplicate, RejectDuplicate},
+		{ErrLowDifficulty, RejectLowDiff},
+		{ErrUnauthorized, RejectUnauthorized},
+		{ErrNotSubscribed, RejectUnauthorized},
+		{ErrNTimeRange, RejectMalformed},
+		{ErrExtranonce2, RejectMalformed},
+		{ErrOther, RejectMalformed},
+	}
+	for _, c := range cases {
+		if got := rejectCode(NewError(c.code, "x")); got != c.want {
+			t.Errorf("rejectCode(%d) = %s, want %s", c.code, got, c.want)
+		}
+	}
+}
+
+func TestSubmitRejectCodes(t *testing.T) {
+	s := newTestServer(t, nil)
+	s.SetProxyMode("aabbccdd", 4, 2, 0)
+	rejects := make(chan ShareReject, 4)
+	s.OnShareRejected = func(minerID string, r ShareReject) { rejects <- r }
+	startTestServer(t, s)
+	s.SetUpstreamDifficulty(1e6) // far above any share submitted here
+	s.BroadcastUpstreamJob(testUpstreamJob("j1"))
+
+	early := dialTest(t, s)
+	early.call("mining.subscribe", "test/1.0")
+	if m := early.call("mining.submit", "rig", "j1", "00000000", "00000000", "00000000"); string(m.Result) != "false" {
+		t.Errorf("submit before authorize: result %s", m.Result)
+	}
+	if r := <-rejects; r.Code != RejectUnauthorized {
+		t.Errorf("submit before authorize recorded as %s", r.Code)
+	}
+
+	c := dialTest(t, s)
+	c.login("rig")
+	ntime := fmt.Sprintf("%08x", time.Now().Unix())
+	m := c.call("mining.submit", "rig", "j1", "00000000", ntime, "00000001")
+	if string(m.Result) != "false" || !strings.Contains(string(m.Error), "23") {
+		t.Errorf("low-difficulty share: result %s, error %s", m.Result, m.Error)
+	}
+	if r := <-rejects; r.Code != RejectLowDiff {
+		t.Errorf("low-difficulty share recorded as %s (%s)", r.Code, r.Detail)
+	}
+}
@@ -0,0 +1,145 @@
+package stratum
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// maxResumable caps the resumption cache.
+const maxResumable = 10000
+
+// resumeState is what a reconnecting miner gets back when it presents its
+// previous subscription ID in mining.subscribe.
+type resumeState struct {
+	ip          string
+	expires     time.Time
+	extranonce1 string
+	userAgent   string
+
+	currentDiff     float64
+	oldDiff         float64
+	diffChangeJobID string
+	suggestedDiff   float64
+	vardiffState    *VardiffState
+
+	versionRolling bool
+	versionMask    uint32
+
+	sharesAccepted uint64
+	sharesRejected uint64
+	sharesDuped    uint64
+	bestDifficulty float64
+}
+
+// resumeCache holds recently disconnected sessions by subscription ID.
+type resumeCache struct {
+	mu      sync.Mutex
+	entries map[string]*resumeState
+}
+
+func newResumeCache() *resumeCache {
+	return &resumeCache{entries: make(map[string]*resumeState)}
+}
+
+// put stores a session's state under its ID, dropping expired entries.
+func (c *resumeCache) put(id string, st *resumeState) {
+	now := time.Now()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for k, e := range c.entries {
+		if now.After(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+	if len(c.entries) >= maxResumable {
+		return
+	}
+	c.entries[id] = st
+}
+
+// take removes and returns the state saved under id if it has not expired
+// and the miner reconnects from the same IP.
+func (c *resumeCache) take(id, ip string) *resumeState {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	st, ok := c.entries[id]
+	if !ok || st.ip != ip {
+		return nil
+	}
+	delete(c.entries, id)
+	if time.Now().After(st.expires) {
+		return nil
+	}
+	return st
+}
+
+// saveForResume keeps a disconnecting session's state for ResumeTTLSec.
+func (s *Server) saveForResume(session *Session) {
+	ttl := time.Duration(s.config.ResumeTTLSec) * time.Second
+	if ttl <= 0 || !session.authorized || s.bans.banned(session.ip) {
+		return
+	}
+	session.diffMu.Lock()
+	st := &resumeState{
+		ip:              session.ip,
+		expires:         time.Now().Add(ttl),
+		extranonce1:     session.extranonce1,
+		userAgent:       session.userAgent,
+		currentDiff:     session.currentDiff,
+		oldDiff:         session.oldDiff,
+		diffChangeJobID: session.diffChangeJobID,
+		suggestedDiff:   session.suggestedDiff,
+		vardiffState:    session.vardiffState,
+		versionRolling:  session.versionRolling,
+		versionMask:     session.versionMask,
+		sharesAccepted:  session.sharesAccepted,
+		sharesRejected:  session.sharesRejected,
+		sharesDuped:     session.sharesDuped,
+		bestDifficulty:  session.bestDifficulty,
+	}
+	session.diffMu.Unlock()
+	s.resumable.put(session.ID, st)
+}
+
+// resume restores a previous session's state into a new one. In proxy mode
+// the saved extranonce1 is only reused if it still carries the current
+// upstream extranonce1. A version mask negotiated on the new connection
+// takes precedence over the saved one.
+func (session *Session) resume(prevID string) bool {
+	s := session.server
+	st := s.resumable.take(prevID, session.ip)
+	if st == nil {
+		return false
+	}
+	if s.proxyMode && !strings.HasPrefix(st.extranonce1, s.extranonce.Load().upstreamEN1) {
+		return false
+	}
+
+	session.extranonce1 = st.extranonce1
+	if session.userAgent == "" {
+		session.userAgent = st.userAgent
+	}
+	session.suggestedDiff = st.suggestedDiff
+	if st.vardiffState != nil {
+		session.vardiffState = st.vardiffState
+	}
+	if !session.versionRolling {
+		session.versionRolling = st.versionRolling
+		session.versionMask = st.versionMask
+	}
+
+	session.diffMu.Lock()
+	session.currentDiff = st.currentDiff
+	session.oldDiff = st.oldDiff
+	session.diffChangeJobID = st.diffChangeJobID
+	session.sharesAccepted = st.sharesAccepted
+	session.sharesRejected = st.sharesRejected
+	session.sharesDuped = st.sharesDuped
+	session.bestDifficulty = st.bestDifficulty
+	session.diffMu.Unlock()
+
+	s.log.Infof("stratum", "session %s resumed %s from %s (extranonce1=%s diff=%.6f)",
+		session.ID, prevID, session.ip, session.extranonce1, st.currentDiff)
+	return true
+}
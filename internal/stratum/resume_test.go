@@ -0,0 +1,115 @@
+package stratum
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestResumeCache(t *testing.T) {
+	c := newResumeCache()
+	c.put("s1", &resumeState{ip: "192.0.2.1", expires: time.Now().Add(time.Minute), extranonce1: "aa"})
+	c.put("s2", &resumeState{ip: "192.0.2.1", expires: time.Now().Add(-time.Second)})
+
+	if st := c.take("s1", "192.0.2.2"); st != nil {
+		t.Error("resumed from another IP")
+	}
+	if st := c.take("s1", "192.0.2.1"); st == nil || st.extranonce1 != "aa" {
+		t.Errorf("take from the same IP = %+v", st)
+	}
+	if st := c.take("s1", "192.0.2.1"); st != nil {
+		t.Error("a session resumed twice")
+	}
+	if st := c.take("s2", "192.0.2.1"); st != nil {
+		t.Error("resumed an expired session")
+	}
+	if st := c.take("unknown", "192.0.2.1"); st != nil {
+		t.Error("resumed an unknown session")
+	}
+
+	c.put("s3", &resumeState{ip: "192.0.2.1", expires: time.Now().Add(time.Minute)})
+	if _, ok := c.entries["s2"]; ok {
+		t.Error("put kept an expired entry")
+	}
+}
+
+// subscribe sends mining.subscribe and returns the subscription ID and
+// extranonce1.
+func (c *testClient) subscribe(params ...interface{}) (id, en1 string) {
+	c.t.Helper()
+	m := c.call("mining.subscribe", params...)
+	var result []json.RawMessage
+	if err := json.Unmarshal(m.Result, &result); err != nil || len(result) < 2 {
+		c.t.Fatalf("subscribe result %s: %v", m.Result, err)
+	}
+	var subs [][]string
+	json.Unmarshal(result[0], &subs)
+	json.Unmarshal(result[1], &en1)
+	return subs[0][1], en1
+}
+
+// disconnect closes the client and waits for the server to drop it.
+func disconnect(t *testing.T, s *Server, c *testClient) {
+	t.Helper()
+	c.conn.Close()
+	for deadline := time.Now().Add(5 * time.Second); s.SessionCount() > 0; {
+		if time.Now().After(deadline) {
+			t.Fatal("session not dropped")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSessionResume(t *testing.T) {
+	s := newTestServer(t, nil)
+	s.SetProxyMode("aabbccdd", 4, 2, 0)
+	startTestServer(t, s)
+	s.BroadcastUpstreamJob(testUpstreamJob("j1"))
+
+	first := dialTest(t, s)
+	id, en1 := first.subscribe("test/1.0")
+	first.call("mining.authorize", "rig", "x")
+	disconnect(t, s, first)
+
+	again := dialTest(t, s)
+	if _, got := again.subscribe("test/1.0", id); got != en1 {
+		t.Errorf("resumed with extranonce1 %s, want %s", got, en1)
+	}
+	again.call("mining.authorize", "rig", "x")
+	disconnect(t, s, again)
+
+	// A subscription ID is good for one resumption only.
+	third := dialTest(t, s)
+	prevEN1 := en1
+	if id, en1 = third.subscribe("test/1.0", id); en1 == prevEN1 {
+		t.Error("resumed the same subscription twice")
+	}
+	third.call("mining.authorize", "rig", "x")
+	disconnect(t, s, third)
+
+	// After the upstream extranonce1 changes the saved one is unusable.
+	s.UpdateProxyState("11223344", 4, 2, 0)
+	fourth := dialTest(t, s)
+	if _, got := fourth.subscribe("test/1.0", id); got == en1 || got[:8] != "11223344" {
+		t.Errorf("resumed with extranonce1 %s after the upstream changed", got)
+	}
+}
+
+func TestSessionResumeExpires(t *testing.T) {
+	s := newTestServer(t, nil)
+	startTestServer(t, s)
+
+	first := dialTest(t, s)
+	id, en1 := first.subscribe("test/1.0")
+	first.call("mining.authorize", "rig", "x")
+	disconnect(t, s, first)
+
+	s.resumable.mu.Lock()
+	s.resumable.entries[id].expires = time.Now().Add(-time.Second)
+	s.resumable.mu.Unlock()
+
+	again := dialTest(t, s)
+	if _, got := again.subscribe("test/1.0", id); got == en1 {
+		t.Error("resumed a session past its TTL")
+	}
+}
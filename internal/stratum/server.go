@@ -1,6 +1,7 @@
 package stratum
 
 import (
+	"bufio"
 	"fmt"
 	"govault/internal/coin"
 	"govault/internal/config"
@@ -9,6 +10,8 @@ import (
 	"govault/internal/upstream"
 	"math/rand"
 	"net"
+	"strconv"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -16,18 +19,25 @@ import (
 
 // Server is the Stratum V1 TCP server.
 type Server struct {
-	listener  net.Listener
+	listeners []net.Listener
 	sessions  map[string]*Session
 	sessionMu sync.RWMutex
 
+	// Connection accounting for the limits in StratumConfig (sessionMu)
+	ipConns map[string]int // open connections per IP
+	pending int            // connections not yet authorized
+
+	bans      *banList
+	resumable *resumeCache
+
 	jobManager     *JobManager
 	shareValidator *ShareValidator
 	vardiffMgr     *VardiffManager
 	nodeClient     *node.Client
 
-	extranonce2Size int
-	nextEN1         atomic.Uint32
-	nextSessionID   atomic.Uint32
+	extranonce    atomic.Pointer[extranonceState]
+	nextEN1       atomic.Uint32
+	nextSessionID atomic.Uint32
 
 	running atomic.Bool
 	stopCh  chan struct{}
@@ -36,36 +46,44 @@ type Server struct {
 	log    *logger.Logger
 	config *config.StratumConfig
 
-	currentJobMu sync.RWMutex
+	currentJobMu  sync.RWMutex
 	currentJobVal *Job
+	lastClean     atomic.Int64 // unix nanos of the last clean job broadcast
 
 	// Proxy mode fields
 	proxyMode        bool
-	upstreamEN1      string
-	proxyPrefixBytes int    // bytes of EN2 used for miner prefix (0-2)
 	upstreamDiff     float64
 	upstreamDiffMu   sync.RWMutex
-	proxyVersionMask uint32 // version-rolling mask from upstream (0 = no rolling)
+
+	awaitingUpstream atomic.Bool // jobs flushed on reconnect; the next one is clean
 
 	// Proxy diagnostic counters
-	proxySharesIn       atomic.Uint64 // ALL shares received from miners (proxy mode)
-	proxySharesValid    atomic.Uint64 // passed validation
-	proxySharesFwd      atomic.Uint64 // forwarded to upstream
-	proxySharesUpAccept atomic.Uint64 // accepted by upstream pool
-	proxySharesUpReject atomic.Uint64 // rejected by upstream pool
-	proxySharesBelow    atomic.Uint64 // below upstream diff, not forwarded
-	proxySharesDupe     atomic.Uint64 // duplicate shares (not counted as rejections)
-	proxySharesStale    atomic.Uint64 // stale job (job not found)
+	proxySharesIn         atomic.Uint64 // ALL shares received from miners (proxy mode)
+	proxySharesValid      atomic.Uint64 // passed validation
+	proxySharesFwd        atomic.Uint64 // forwarded to upstream
+	proxySharesUpAccept   atomic.Uint64 // accepted by upstream pool
+	proxySharesUpReject   atomic.Uint64 // rejected by upstream pool
+	proxySharesBelow      atomic.Uint64 // below upstream diff, not forwarded
+	proxySharesDupe       atomic.Uint64 // duplicate shares (not counted as rejections)
+	proxySharesStale      atomic.Uint64 // stale job (job not found)
+	proxySharesStaleBlock atomic.Uint64 // job on a replaced tip
 
 	// Event callbacks
 	OnMinerConnected    func(MinerInfo)
 	OnMinerDisconnected func(string)
-	OnShareAccepted     func(string, float64, float64) // minerID, sessionDiff, actualDiff
-	OnShareRejected     func(string, string)
+	OnShareAccepted     func(minerID string, sessionDiff, actualDiff float64, age ShareAge)
+	OnShareRejected     func(minerID string, reject ShareReject)
 	OnBlockFound        func(hash string, height int64, accepted bool)
 	LookupWorkerDiff    func(workerName string) float64
 	OnDiffChanged       func(workerName string, diff float64)
 	OnShareForward      func(workerName, jobID, fullEN2, ntime, nonce, versionBits string) (bool, string)
+
+	OnBanned            func(Ban)
+
+	// HoldWork reports whether jobs should be withheld from a miner that
+	// has just authorized (a scheduled pause). It is asked once per
+	// session; SetHeld changes the answer later.
+	HoldWork func(MinerInfo) bool
 }
 
 func NewServer(
@@ -77,17 +95,19 @@ func NewServer(
 	coinDef *coin.CoinDef,
 ) *Server {
 	extranonce2Size := 4
-	jm := NewJobManager(miningCfg.PayoutAddress, miningCfg.CoinbaseTag, extranonce2Size, coinDef)
+	jm := NewJobManager(miningCfg.PayoutAddress, miningCfg.CoinbaseTag, extranonce2Size, coinDef, &cfg.Jobs)
 	sv := NewShareValidator(jm)
 	vm := NewVardiffManager(vardiffCfg)
 
 	s := &Server{
 		sessions:        make(map[string]*Session),
+		ipConns:         make(map[string]int),
+		bans:            newBanList(&cfg.Ban),
+		resumable:       newResumeCache(),
 		jobManager:      jm,
 		shareValidator:  sv,
 		vardiffMgr:      vm,
 		nodeClient:      nodeClient,
-		extranonce2Size: extranonce2Size,
 		stopCh:          make(chan struct{}),
 		log:             log,
 		config:          cfg,
@@ -96,26 +116,51 @@ func NewServer(
 	// Seed EN1 counter with random upper 16 bits so session IDs don't
 	// recycle across stop/start cycles. Lower 16 bits = counter space
 	// for 65536 connections per server instance (more than enough).
+	s.extranonce.Store(&extranonceState{en2Size: extranonce2Size})
 	s.nextEN1.Store(rand.Uint32() & 0xFFFF0000)
 	s.nextSessionID.Store(rand.Uint32() & 0xFFFF0000)
 
 	return s
 }
 
-// Start begins listening for miner connections.
+// Start begins listening for miner connections on every bind address.
 func (s *Server) Start() error {
-	addr := fmt.Sprintf("0.0.0.0:%d", s.config.Port)
-	listener, err := net.Listen("tcp", addr)
-	if err != nil {
-		return fmt.Errorf("listen on %s: %w", addr, err)
+	hosts := s.config.BindAddrs
+	if len(hosts) == 0 {
+		hosts = []string{"0.0.0.0"}
 	}
 
-	s.listener = listener
+	listeners := make([]net.Listener, 0, len(hosts))
+	addrs := make([]string, 0, len(hosts))
+	for _, host := range hosts {
+		// tcp6 sets IPV6_V6ONLY, so "0.0.0.0" and "::" can be bound together.
+		network := "tcp6"
+		if ip := net.ParseIP(host); ip != nil && ip.To4() != nil {
+			network = "tcp4"
+		}
+		addr := net.JoinHostPort(host, strconv.Itoa(s.config.Port))
+		listener, err := net.Listen(network, addr)
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return fmt.Errorf("listen on %s: %w", addr, err)
+		}
+		listeners = append(listeners, listener)
+		addrs = append(addrs, addr)
+	}
+
+	s.listeners = listeners
 	s.running.Store(true)
-	s.log.Infof("stratum", "server started on %s", addr)
+	s.log.Infof("stratum", "server started on %s", strings.Join(addrs, ", "))
+	if s.config.ProxyProtocol.Enabled {
+		s.log.Infof("stratum", "PROXY protocol accepted from %s", strings.Join(s.config.ProxyProtocol.Trusted, ", "))
+	}
 
-	s.wg.Add(1)
-	go s.acceptLoop()
+	for _, listener := range listeners {
+		s.wg.Add(1)
+		go s.acceptLoop(listener)
+	}
 
 	return nil
 }
@@ -127,8 +172,8 @@ func (s *Server) Stop() {
 	}
 	close(s.stopCh)
 
-	if s.listener != nil {
-		s.listener.Close()
+	for _, listener := range s.listeners {
+		listener.Close()
 	}
 
 	// Tell miners to reconnect before we close their connections.
@@ -161,11 +206,11 @@ func (s *Server) IsRunning() bool {
 	return s.running.Load()
 }
 
-func (s *Server) acceptLoop() {
+func (s *Server) acceptLoop(listener net.Listener) {
 	defer s.wg.Done()
 
 	for s.running.Load() {
-		conn, err := s.listener.Accept()
+		conn, err := listener.Accept()
 		if err != nil {
 			if !s.running.Load() {
 				return // server shutting down — expected
@@ -189,31 +234,153 @@ func (s *Server) acceptLoop() {
 			tc.SetNoDelay(true)
 		}
 
-		en1 := s.generateExtranonce1()
-		sessionID := fmt.Sprintf("s_%08x", s.nextSessionID.Add(1))
-
-		session := newSession(sessionID, conn, s, en1)
-
-		s.sessionMu.Lock()
-		s.sessions[sessionID] = session
-		s.sessionMu.Unlock()
-
-		s.log.Infof("stratum", "new connection from %s (session %s)", conn.RemoteAddr(), sessionID)
-
+		// The PROXY header is read off the accept goroutine so a slow
+		// relay cannot hold up other connections.
 		s.wg.Add(1)
 		go func() {
 			defer s.wg.Done()
-			session.Handle()
+			s.serveConn(conn)
 		}()
 	}
 }
 
+// serveConn resolves the miner's address, admits the connection and runs
+// its session. Connections from trusted relays may start with a PROXY
+// protocol header naming the miner behind the relay.
+func (s *Server) serveConn(conn net.Conn) {
+	reader := bufio.NewReaderSize(conn, 4096)
+	remote := conn.RemoteAddr()
+
+	pp := &s.config.ProxyProtocol
+	if host, _, err := net.SplitHostPort(remote.String()); err == nil && pp.Enabled && ipListed(host, pp.Trusted) {
+		conn.SetReadDeadline(time.Now().Add(proxyHeaderTimeout))
+		src, err := readProxyHeader(reader)
+		if err != nil {
+			s.log.Warnf("stratum", "dropped connection from relay %s: %v", remote, err)
+			conn.Close()
+			return
+		}
+		conn.SetReadDeadline(time.Time{})
+		if src != nil {
+			remote = src
+		}
+	}
+	if !s.running.Load() {
+		conn.Close()
+		return
+	}
+
+	en1 := s.generateExtranonce1()
+	sessionID := fmt.Sprintf("s_%08x", s.nextSessionID.Add(1))
+
+	session := newSession(sessionID, conn, reader, remote, s, en1)
+
+	if reason := s.admit(session); reason != "" {
+		s.log.Debugf("stratum", "refused connection from %s: %s", remote, reason)
+		conn.Close()
+		return
+	}
+
+	if remote != conn.RemoteAddr() {
+		s.log.Infof("stratum", "new connection from %s via %s (session %s)", remote, conn.RemoteAddr(), sessionID)
+	} else {
+		s.log.Infof("stratum", "new connection from %s (session %s)", remote, sessionID)
+	}
+	session.Handle()
+}
+
+// admit checks a new connection against the bans and connection limits
+// and registers it if it is let in. It returns why it was refused, or "".
+// Allow-listed IPs are exempt from the per-IP and pending limits.
+func (s *Server) admit(session *Session) string {
+	ip := session.ip
+	if s.bans.banned(ip) {
+		return "banned"
+	}
+	allowed := s.bans.allowed(ip)
+
+	s.sessionMu.Lock()
+	defer s.sessionMu.Unlock()
+	if s.config.MaxConn > 0 && len(s.sessions) >= s.config.MaxConn {
+		return fmt.Sprintf("connection limit (%d) reached", s.config.MaxConn)
+	}
+	if !allowed && s.config.MaxConnPerIP > 0 && s.ipConns[ip] >= s.config.MaxConnPerIP {
+		return fmt.Sprintf("per-IP limit (%d) reached", s.config.MaxConnPerIP)
+	}
+	if !allowed && s.config.MaxPending > 0 && s.pending >= s.config.MaxPending {
+		return fmt.Sprintf("too many unauthorized connections (%d)", s.pending)
+	}
+	s.sessions[session.ID] = session
+	s.ipConns[ip]++
+	s.pending++
+	return ""
+}
+
+// sessionAuthorized moves a session out of the pending count.
+func (s *Server) sessionAuthorized(session *Session) {
+	s.sessionMu.Lock()
+	if !session.authorized {
+		session.authorized = true
+		s.pending--
+	}
+	s.sessionMu.Unlock()
+}
+
+// handshakeTimeout is how long a connection may stay unauthorized.
+func (s *Server) handshakeTimeout() time.Duration {
+	return time.Duration(s.config.HandshakeTimeoutSec) * time.Second
+}
+
+// penalize adds ban points for an offence from ip. If that bans the IP,
+// every session from it is disconnected.
+func (s *Server) penalize(ip string, points int, reason string) {
+	ban, banned := s.bans.score(ip, points, reason)
+	if !banned {
+		return
+	}
+	s.log.Warnf("stratum", "banned %s until %s: %s (score %d)", ip, ban.Until.Format("15:04"), reason, ban.Score)
+
+	s.sessionMu.RLock()
+	for _, session := range s.sessions {
+		if session.ip == ip {
+			session.conn.Close()
+		}
+	}
+	s.sessionMu.RUnlock()
+
+	if s.OnBanned != nil {
+		s.OnBanned(ban)
+	}
+}
+
+// Bans returns the active IP bans.
+func (s *Server) Bans() []Ban {
+	return s.bans.list()
+}
+
+// Unban lifts the ban on ip and clears its score. It reports whether the
+// IP was banned.
+func (s *Server) Unban(ip string) bool {
+	lifted := s.bans.lift(ip)
+	if lifted {
+		s.log.Infof("stratum", "ban on %s lifted", ip)
+	}
+	return lifted
+}
+
 func (s *Server) removeSession(session *Session) {
 	s.sessionMu.Lock()
 	delete(s.sessions, session.ID)
+	if s.ipConns[session.ip]--; s.ipConns[session.ip] <= 0 {
+		delete(s.ipConns, session.ip)
+	}
+	if !session.authorized {
+		s.pending--
+	}
 	s.sessionMu.Unlock()
 
 	s.log.Infof("stratum", "session %s disconnected (%s)", session.ID, session.workerName)
+	s.saveForResume(session)
 
 	if s.OnDiffChanged != nil && session.authorized && session.workerName != "" {
 		session.diffMu.Lock()
@@ -226,16 +393,27 @@ func (s *Server) removeSession(session *Session) {
 	}
 }
 
+// extranonceState is the extranonce layout handed to miners. Proxy mode
+// replaces it whole when the upstream reconnects, so readers take one
+// snapshot and never see fields from two connections.
+type extranonceState struct {
+	upstreamEN1 string // proxy mode: upstream extranonce1, which starts every miner's
+	en2Size     int    // extranonce2 bytes left to the miner
+	prefixBytes int    // bytes of EN2 used for miner prefix (0-2)
+	versionMask uint32 // version-rolling mask from upstream (0 = no rolling)
+}
+
 func (s *Server) generateExtranonce1() string {
 	if s.proxyMode {
-		if s.proxyPrefixBytes == 0 {
+		ens := s.extranonce.Load()
+		if ens.prefixBytes == 0 {
 			// No prefix — all miners share the upstream EN2 space.
-			return s.upstreamEN1
+			return ens.upstreamEN1
 		}
-		mask := uint32((1 << (8 * s.proxyPrefixBytes)) - 1)
+		mask := uint32((1 << (8 * ens.prefixBytes)) - 1)
 		counter := s.nextEN1.Add(1) & mask
-		format := fmt.Sprintf("%%0%dx", s.proxyPrefixBytes*2)
-		return s.upstreamEN1 + fmt.Sprintf(format, counter)
+		format := fmt.Sprintf("%%0%dx", ens.prefixBytes*2)
+		return ens.upstreamEN1 + fmt.Sprintf(format, counter)
 	}
 	val := s.nextEN1.Add(1)
 	return fmt.Sprintf("%08x", val)
@@ -245,10 +423,12 @@ func (s *Server) generateExtranonce1() string {
 // versionMask is the upstream pool's version-rolling mask (0 = no rolling).
 func (s *Server) SetProxyMode(upstreamEN1 string, localEN2Size, prefixBytes int, versionMask uint32) {
 	s.proxyMode = true
-	s.upstreamEN1 = upstreamEN1
-	s.extranonce2Size = localEN2Size
-	s.proxyPrefixBytes = prefixBytes
-	s.proxyVersionMask = versionMask
+	s.extranonce.Store(&extranonceState{
+		upstreamEN1: upstreamEN1,
+		en2Size:     localEN2Size,
+		prefixBytes: prefixBytes,
+		versionMask: versionMask,
+	})
 	s.shareValidator.skipDupeCheck = true // let upstream pool handle duplicates
 }
 
@@ -257,33 +437,58 @@ func (s *Server) IsProxyMode() bool {
 	return s.proxyMode
 }
 
-// UpdateProxyState updates upstream EN1 and version mask after a reconnect,
-// then kicks all miners so they reconnect and get new EN1-based sessions.
+// UpdateProxyState updates upstream EN1 and version mask after a reconnect.
+// Miners that sent mining.extranonce.subscribe get a new EN1 in place with
+// mining.set_extranonce and a clean job; the rest are kicked so they
+// reconnect and get new EN1-based sessions.
 func (s *Server) UpdateProxyState(upstreamEN1 string, localEN2Size, prefixBytes int, versionMask uint32) {
-	s.upstreamEN1 = upstreamEN1
-	s.extranonce2Size = localEN2Size
-	s.proxyPrefixBytes = prefixBytes
-	s.proxyVersionMask = versionMask
-
-	s.log.Infof("stratum", "upstream reconnected — new EN1=%s, kicking %d miners to reconnect", upstreamEN1, s.SessionCount())
-
-	// Send client.reconnect to all authorized miners, then close their connections.
-	// They'll reconnect immediately and get new sessions with the updated EN1.
+	s.extranonce.Store(&extranonceState{
+		upstreamEN1: upstreamEN1,
+		en2Size:     localEN2Size,
+		prefixBytes: prefixBytes,
+		versionMask: versionMask,
+	})
+
+	// Jobs from the old connection can't be submitted to the new one.
+	// Drop them; miners get work when the new upstream's first job
+	// arrives, which BroadcastUpstreamJob sends as clean.
+	s.awaitingUpstream.Store(true)
+	s.setCurrentJob(nil)
+	s.jobManager.CleanJobs()
+	s.shareValidator.CleanDuplicates(nil)
+
+	var kick []*Session
+	updated := 0
 	s.sessionMu.RLock()
 	for _, session := range s.sessions {
+		if !session.authorized || !session.extranonceSubscribed.Load() {
+			kick = append(kick, session)
+			continue
+		}
+		session.setExtranonce(s.generateExtranonce1(), localEN2Size)
+		updated++
+	}
+	s.sessionMu.RUnlock()
+
+	s.log.Infof("stratum", "upstream reconnected — new EN1=%s, %d miners updated in place, kicking %d to reconnect",
+		upstreamEN1, updated, len(kick))
+	if len(kick) == 0 {
+		return
+	}
+
+	// Send client.reconnect to the authorized ones, then close their
+	// connections. They'll reconnect and get sessions with the new EN1.
+	for _, session := range kick {
 		if session.authorized {
 			session.sendReconnect(3)
 		}
 	}
-	s.sessionMu.RUnlock()
 
 	time.Sleep(200 * time.Millisecond)
 
-	s.sessionMu.Lock()
-	for _, session := range s.sessions {
+	for _, session := range kick {
 		session.conn.Close()
 	}
-	s.sessionMu.Unlock()
 }
 
 // SetUpstreamDifficulty sets the current upstream pool difficulty and
@@ -325,6 +530,7 @@ type ProxyDiagnostics struct {
 	SharesBelow    uint64  `json:"sharesBelow"`    // below upstream diff
 	SharesDupe     uint64  `json:"sharesDupe"`     // duplicate (ASIC re-reads)
 	SharesStale    uint64  `json:"sharesStale"`    // stale job (job not found)
+	SharesStaleBlock uint64 `json:"sharesStaleBlock"` // job on a replaced tip
 	UpstreamDiff   float64 `json:"upstreamDiff"`   // current upstream pool diff
 	MinerDiffs     map[string]float64 `json:"minerDiffs"` // worker → session diff
 	MinerDupes     map[string]uint64  `json:"minerDupes"` // worker → duplicate count
@@ -334,18 +540,19 @@ type ProxyDiagnostics struct {
 // GetProxyDiagnostics returns current proxy pipeline counters.
 func (s *Server) GetProxyDiagnostics() ProxyDiagnostics {
 	d := ProxyDiagnostics{
-		SharesIn:       s.proxySharesIn.Load(),
-		SharesValid:    s.proxySharesValid.Load(),
-		SharesFwd:      s.proxySharesFwd.Load(),
-		SharesAccepted: s.proxySharesUpAccept.Load(),
-		SharesRejected: s.proxySharesUpReject.Load(),
-		SharesBelow:    s.proxySharesBelow.Load(),
-		SharesDupe:     s.proxySharesDupe.Load(),
-		SharesStale:    s.proxySharesStale.Load(),
-		UpstreamDiff:   s.UpstreamDifficulty(),
-		MinerDiffs:     make(map[string]float64),
-		MinerDupes:     make(map[string]uint64),
-		MinerAccepted:  make(map[string]uint64),
+		SharesIn:         s.proxySharesIn.Load(),
+		SharesValid:      s.proxySharesValid.Load(),
+		SharesFwd:        s.proxySharesFwd.Load(),
+		SharesAccepted:   s.proxySharesUpAccept.Load(),
+		SharesRejected:   s.proxySharesUpReject.Load(),
+		SharesBelow:      s.proxySharesBelow.Load(),
+		SharesDupe:       s.proxySharesDupe.Load(),
+		SharesStale:      s.proxySharesStale.Load(),
+		SharesStaleBlock: s.proxySharesStaleBlock.Load(),
+		UpstreamDiff:     s.UpstreamDifficulty(),
+		MinerDiffs:       make(map[string]float64),
+		MinerDupes:       make(map[string]uint64),
+		MinerAccepted:    make(map[string]uint64),
 	}
 
 	s.sessionMu.RLock()
@@ -383,18 +590,26 @@ func (s *Server) BroadcastUpstreamJob(params *upstream.JobParams) {
 	// Always clean duplicate tracking for trimmed-out jobs (matches solo mode)
 	s.shareValidator.CleanDuplicates(activeIDs)
 
-	s.BroadcastJob(job, params.CleanJobs)
+	clean := params.CleanJobs
+	if s.awaitingUpstream.Swap(false) {
+		clean = true
+	}
+	s.BroadcastJob(job, clean)
 }
 
-// BroadcastJob sends a new job to all connected and authorized miners.
+// BroadcastJob queues a new job for all connected and authorized miners.
+// Queuing never blocks, so a slow miner cannot delay the others.
 func (s *Server) BroadcastJob(job *Job, cleanJobs bool) {
 	s.setCurrentJob(job)
+	if cleanJobs {
+		s.lastClean.Store(time.Now().UnixNano())
+	}
 
 	s.sessionMu.RLock()
 	defer s.sessionMu.RUnlock()
 
 	for _, session := range s.sessions {
-		if session.authorized {
+		if session.authorized && !session.held.Load() {
 			session.sendNotify(job, cleanJobs)
 		}
 	}
@@ -431,6 +646,10 @@ func (s *Server) RefreshBlockTemplate(tmpl *node.BlockTemplate) {
 }
 
 func (s *Server) sendCurrentJob(session *Session) {
+	if session.held.Load() {
+		s.log.Infof("stratum", "withholding work from miner %s", session.workerName)
+		return
+	}
 	job := s.currentJob()
 	if job != nil {
 		session.sendNotify(job, true)
@@ -440,6 +659,40 @@ func (s *Server) sendCurrentJob(session *Session) {
 	}
 }
 
+// SetHeld withholds jobs from the sessions in ids and resumes all others.
+// Resumed miners are sent the current job; it returns how many there were.
+func (s *Server) SetHeld(ids map[string]bool) int {
+	s.sessionMu.RLock()
+	var released []*Session
+	for id, session := range s.sessions {
+		hold := ids[id]
+		if session.held.Swap(hold) && !hold && session.authorized {
+			released = append(released, session)
+		}
+	}
+	s.sessionMu.RUnlock()
+
+	for _, session := range released {
+		s.sendCurrentJob(session)
+	}
+	return len(released)
+}
+
+// ReconnectMiner sends client.reconnect to a session, asking the miner to
+// come back after waitSec, and closes the connection a second later for
+// firmware that ignores the request.
+func (s *Server) ReconnectMiner(id string, waitSec int) error {
+	s.sessionMu.RLock()
+	session, ok := s.sessions[id]
+	s.sessionMu.RUnlock()
+	if !ok {
+		return fmt.Errorf("session %s not found", id)
+	}
+	session.sendReconnect(waitSec)
+	time.AfterFunc(time.Second, func() { session.conn.Close() })
+	return nil
+}
+
 func (s *Server) setCurrentJob(job *Job) {
 	s.currentJobMu.Lock()
 	s.currentJobVal = job
@@ -0,0 +1,276 @@
+package stratum
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"net"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"govault/internal/coin"
+	"govault/internal/config"
+	"govault/internal/logger"
+	"govault/internal/upstream"
+)
+
+// newTestServer returns a server for a random loopback port with bans
+// off, not yet started. edit, if given, adjusts the defaults first.
+func newTestServer(t *testing.T, edit func(*config.Config)) *Server {
+	t.Helper()
+	cfg := config.Defaults()
+	cfg.Stratum.Port = 0
+	cfg.Stratum.BindAddrs = []string{"127.0.0.1"}
+	cfg.Stratum.Ban.Enabled = false
+	if edit != nil {
+		edit(cfg)
+	}
+	log, err := logger.New(t.TempDir(), "error")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(log.Close)
+
+	return NewServer(&cfg.Stratum, &cfg.Mining, &cfg.Vardiff, nil, log, coin.Get(cfg.Mining.Coin))
+}
+
+func startTestServer(t *testing.T, s *Server) {
+	t.Helper()
+	if err := s.Start(); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(s.Stop)
+}
+
+// testMessage is a line from the server: a response or a notification.
+type testMessage struct {
+	ID     *int            `json:"id"`
+	Method string          `json:"method"`
+	Params json.RawMessage `json:"params"`
+	Result json.RawMessage `json:"result"`
+	Error  json.RawMessage `json:"error"`
+}
+
+// testClient speaks line-delimited JSON to a test server.
+type testClient struct {
+	t      *testing.T
+	conn   net.Conn
+	r      *bufio.Reader
+	mu     sync.Mutex
+	nextID int
+}
+
+func dialTest(t *testing.T, s *Server) *testClient {
+	t.Helper()
+	conn, err := net.Dial("tcp", s.listeners[0].Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return &testClient{t: t, conn: conn, r: bufio.NewReader(conn)}
+}
+
+// send writes a request and returns its ID. Safe for concurrent use.
+func (c *testClient) send(method string, params ...interface{}) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.nextID++
+	if params == nil {
+		params = []interface{}{}
+	}
+	data, _ := json.Marshal(map[string]interface{}{"id": c.nextID, "method": method, "params": params})
+	if _, err := c.conn.Write(append(data, '\n')); err != nil {
+		c.t.Errorf("send %s: %v", method, err)
+	}
+	return c.nextID
+}
+
+// read returns the next message from the server.
+func (c *testClient) read() (testMessage, error) {
+	c.conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	line, err := c.r.ReadBytes('\n')
+	if err != nil {
+		return testMessage{}, err
+	}
+	var m testMessage
+	if err := json.Unmarshal(line, &m); err != nil {
+		return testMessage{}, fmt.Errorf("decode %q: %w", line, err)
+	}
+	return m, nil
+}
+
+// call sends a request and returns its response, skipping notifications.
+func (c *testClient) call(method string, params ...interface{}) testMessage {
+	c.t.Helper()
+	id := c.send(method, params...)
+	for {
+		m, err := c.read()
+		if err != nil {
+			c.t.Fatalf("%s: %v", method, err)
+		}
+		if m.ID != nil && *m.ID == id {
+			return m
+		}
+	}
+}
+
+// notifiesBefore sends a request and returns the job IDs notified before
+// its response. The outbox keeps order, so a job queued earlier arrives
+// first.
+func (c *testClient) notifiesBefore(method string, params ...interface{}) []string {
+	c.t.Helper()
+	id := c.send(method, params...)
+	var jobs []string
+	for {
+		m, err := c.read()
+		if err != nil {
+			c.t.Fatalf("%s: %v", method, err)
+		}
+		if m.ID != nil && *m.ID == id {
+			return jobs
+		}
+		if m.Method == "mining.notify" {
+			var params []json.RawMessage
+			json.Unmarshal(m.Params, &params)
+			var job string
+			json.Unmarshal(params[0], &job)
+			jobs = append(jobs, job)
+		}
+	}
+}
+
+// login subscribes and authorizes the client as worker.
+func (c *testClient) login(worker string) {
+	c.t.Helper()
+	if m := c.call("mining.subscribe", "test/1.0"); string(m.Error) != "null" && m.Error != nil {
+		c.t.Fatalf("subscribe: %s", m.Error)
+	}
+	if m := c.call("mining.authorize", worker, "x"); string(m.Result) != "true" {
+		c.t.Fatalf("authorize: %s %s", m.Result, m.Error)
+	}
+}
+
+// testUpstreamJob returns a proxy-mode job with the given ID.
+func testUpstreamJob(id string) *upstream.JobParams {
+	return &upstream.JobParams{
+		JobID:     id,
+		PrevHash:  "000000000000000000000000000000000000000000000000000000000000abcd",
+		Coinbase1: "01000000010000000000000000000000000000000000000000000000000000000000000000ffffffff1703a08601",
+		Coinbase2: "ffffffff0100f2052a01000000016a00000000",
+		Version:   "20000000",
+		NBits:     "1d00ffff",
+		NTime:     fmt.Sprintf("%08x", time.Now().Unix()),
+		CleanJobs: true,
+	}
+}
+
+// Shares submitted while the upstream reconnects must be answered, and
+// never sliced against another connection's extranonce1.
+func TestSubmitAcrossUpstreamReconnect(t *testing.T) {
+	s := newTestServer(t, nil)
+	states := []struct {
+		en1    string
+		prefix int
+	}{
+		{"aabbccdd", 2},
+		{"aabbccddeeff0011", 1}, // longer: old miner EN1s are shorter than it
+	}
+	s.SetProxyMode(states[0].en1, 4, states[0].prefix, 0)
+	startTestServer(t, s)
+	s.SetUpstreamDifficulty(1e-12)
+	var forwarded atomic.Int32
+	s.OnShareForward = func(worker, jobID, fullEN2, ntime, nonce, versionBits string) (bool, string) {
+		forwarded.Add(1)
+		return true, ""
+	}
+	s.BroadcastUpstreamJob(testUpstreamJob("j0"))
+
+	const miners, submits = 4, 200
+	var wg sync.WaitGroup
+	for i := 0; i < miners; i++ {
+		c := dialTest(t, s)
+		c.call("mining.extranonce.subscribe")
+		c.login(fmt.Sprintf("rig%d", i))
+
+		var job atomic.Value
+		job.Store(s.currentJob().ID)
+		var answered atomic.Int32
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for answered.Load() < submits {
+				m, err := c.read()
+				if err != nil {
+					t.Errorf("miner %d after %d answers: %v", i, answered.Load(), err)
+					return
+				}
+				switch {
+				case m.Method == "mining.notify":
+					var params []json.RawMessage
+					json.Unmarshal(m.Params, &params)
+					var id string
+					json.Unmarshal(params[0], &id)
+					job.Store(id)
+				case m.ID != nil && *m.ID > 3:
+					answered.Add(1)
+				}
+			}
+		}()
+
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for n := 0; n < submits; n++ {
+				jobID := job.Load().(string)
+				c.send("mining.submit", fmt.Sprintf("rig%d", i), jobID, fmt.Sprintf("%08x", n),
+					fmt.Sprintf("%08x", time.Now().Unix()), fmt.Sprintf("%08x", n*7919))
+			}
+			<-done
+		}(i)
+	}
+
+	for n := 1; n <= 10; n++ {
+		st := states[n%2]
+		s.UpdateProxyState(st.en1, 4, st.prefix, 0)
+		s.BroadcastUpstreamJob(testUpstreamJob(fmt.Sprintf("j%d", n)))
+		time.Sleep(5 * time.Millisecond)
+	}
+	wg.Wait()
+
+	if forwarded.Load() == 0 {
+		t.Error("no share reached the forwarding path")
+	}
+}
+
+func TestHeldSessions(t *testing.T) {
+	s := newTestServer(t, nil)
+	s.SetProxyMode("aabbccdd", 4, 2, 0)
+	s.HoldWork = func(m MinerInfo) bool { return m.WorkerName == "paused" }
+	startTestServer(t, s)
+	s.BroadcastUpstreamJob(testUpstreamJob("j1"))
+
+	paused, working := dialTest(t, s), dialTest(t, s)
+	paused.call("mining.subscribe", "test/1.0")
+	working.call("mining.subscribe", "test/1.0")
+	if jobs := paused.notifiesBefore("mining.authorize", "paused", "x"); len(jobs) != 0 {
+		t.Errorf("held miner sent %v on authorize", jobs)
+	}
+	working.call("mining.authorize", "working", "x")
+
+	s.BroadcastUpstreamJob(testUpstreamJob("j2"))
+	if jobs := paused.notifiesBefore("mining.extranonce.subscribe"); len(jobs) != 0 {
+		t.Errorf("held miner sent %v", jobs)
+	}
+	if jobs := working.notifiesBefore("mining.extranonce.subscribe"); len(jobs) == 0 || jobs[len(jobs)-1] != "j2" {
+		t.Errorf("working miner sent %v, want j2 last", jobs)
+	}
+
+	if n := s.SetHeld(nil); n != 1 {
+		t.Errorf("SetHeld released %d miners, want 1", n)
+	}
+	if jobs := paused.notifiesBefore("mining.extranonce.subscribe"); len(jobs) != 1 || jobs[0] != "j2" {
+		t.Errorf("released miner sent %v, want the current job", jobs)
+	}
+}
@@ -8,14 +8,22 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// extranonceGrace is how long after mining.set_extranonce a miner may
+// still submit work sized for its old extranonce without ban points.
+const extranonceGrace = 10 * time.Second
+
 // Session represents a single miner connection.
 type Session struct {
 	ID          string
 	conn        net.Conn
+	remote      string // miner address: the PROXY header source, else the peer
+	ip          string // remote IP, without port
 	server      *Server
 	extranonce1 string
 	subscribed  bool
@@ -26,7 +34,7 @@ type Session struct {
 	connectedAt  time.Time
 	lastActivity time.Time
 	reader      *bufio.Reader
-	writeMu     sync.Mutex
+	out         outbox
 
 	vardiffState *VardiffState
 
@@ -38,8 +46,25 @@ type Session struct {
 	sharesDuped    uint64
 	bestDifficulty float64
 
+	// Shares on a replaced tip, and how long after the tip changed they
+	// arrived (ms): a moving average and the worst.
+	sharesStaleBlock uint64
+	staleTipAgeAvg   float64
+	staleTipAgeMax   float64
+
+	// Shares failing consensus checks, by reason (also in sharesRejected).
+	rejectedNTime       uint64
+	rejectedVersionMask uint64
+	rejectedExtranonce2 uint64
+	rejectedNonce       uint64
+
 	suggestedDiff float64 // from mining.suggest_difficulty (miner's threshold)
 
+	held atomic.Bool // jobs are being withheld (HoldWork, SetHeld)
+
+	extranonceSubscribed atomic.Bool // sent mining.extranonce.subscribe
+	extranonceChanged    time.Time   // last mining.set_extranonce (diffMu)
+
 	// Difficulty transition grace period (matches ckpool diff_change_job_id).
 	// Shares for jobs issued before diffChangeJobID are validated against oldDiff.
 	oldDiff          float64
@@ -48,20 +73,29 @@ type Session struct {
 	// diffMu protects currentDiff, oldDiff, diffChangeJobID, and the share
 	// counters above. These are written by Handle() and read/written by
 	// setProxyDiff() (from the server goroutine) and GetProxyDiagnostics().
+	// It also guards extranonce1 once authorized, which UpdateProxyState
+	// may replace.
 	diffMu sync.Mutex
 }
 
-func newSession(id string, conn net.Conn, server *Server, extranonce1 string) *Session {
+func newSession(id string, conn net.Conn, reader *bufio.Reader, remote net.Addr, server *Server, extranonce1 string) *Session {
 	now := time.Now()
+	ip, _, err := net.SplitHostPort(remote.String())
+	if err != nil {
+		ip = remote.String()
+	}
 	return &Session{
 		ID:           id,
 		conn:         conn,
+		remote:       remote.String(),
+		ip:           ip,
 		server:       server,
 		extranonce1:  extranonce1,
 		currentDiff:  server.vardiffMgr.StartDiff(),
 		connectedAt:  now,
 		lastActivity: now,
-		reader:       bufio.NewReaderSize(conn, 4096),
+		reader:       reader,
+		out:          newOutbox(),
 	}
 }
 
@@ -72,8 +106,10 @@ func (s *Session) Handle() {
 			s.server.log.Errorf("stratum", "session %s panic: %v", s.ID, r)
 		}
 		s.conn.Close()
+		s.closeOutbox()
 		s.server.removeSession(s)
 	}()
+	go s.writeLoop()
 
 	// Initialize vardiff state
 	s.vardiffState = s.server.vardiffMgr.NewState()
@@ -82,12 +118,24 @@ func (s *Session) Handle() {
 		// Use retarget interval as read deadline so idle sessions get
 		// periodic vardiff checks (halving difficulty when no shares arrive).
 		retargetInterval := s.server.vardiffMgr.RetargetInterval()
-		s.conn.SetReadDeadline(time.Now().Add(retargetInterval))
+		deadline := time.Now().Add(retargetInterval)
+		// Unauthorized connections only get the handshake timeout.
+		handshake := s.server.handshakeTimeout()
+		if !s.authorized && handshake > 0 {
+			if d := s.connectedAt.Add(handshake); d.Before(deadline) {
+				deadline = d
+			}
+		}
+		s.conn.SetReadDeadline(deadline)
 
 		line, err := s.reader.ReadBytes('\n')
 		if err != nil {
 			// Timeout → idle vardiff check (don't disconnect yet)
 			if netErr, ok := err.(net.Error); ok && netErr.Timeout() {
+				if !s.authorized && handshake > 0 && time.Since(s.connectedAt) >= handshake {
+					s.server.log.Infof("stratum", "session %s from %s did not authorize within %v", s.ID, s.ip, handshake)
+					return
+				}
 				// True inactivity (no data at all for 5 min) → disconnect
 				if time.Since(s.lastActivity) > 5*time.Minute {
 					return
@@ -130,6 +178,7 @@ func (s *Session) Handle() {
 		req, err := ParseRequest(line)
 		if err != nil {
 			s.server.log.Debugf("stratum", "session %s bad request: %v", s.ID, err)
+			s.server.penalize(s.ip, s.server.config.Ban.MalformedPoints, "malformed request")
 			continue
 		}
 
@@ -150,6 +199,7 @@ func (s *Session) handleRequest(req *Request) {
 	case "mining.suggest_difficulty":
 		s.handleSuggestDifficulty(req)
 	case "mining.extranonce.subscribe":
+		s.extranonceSubscribed.Store(true)
 		s.sendResponse(req.ID, true, nil)
 	default:
 		s.server.log.Debugf("stratum", "session %s unknown method: %s", s.ID, req.Method)
@@ -178,8 +228,9 @@ func (s *Session) handleConfigure(req *Request) {
 			// forwarded shares don't trigger "mask violation" rejections.
 			// In solo mode, use the standard safe mask.
 			poolMask := uint32(0x1fffe000)
-			if s.server.proxyMode && s.server.proxyVersionMask != 0 {
-				poolMask = s.server.proxyVersionMask
+			upstreamMask := s.server.extranonce.Load().versionMask
+			if s.server.proxyMode && upstreamMask != 0 {
+				poolMask = upstreamMask
 			}
 
 			// Intersect with miner's requested mask
@@ -195,7 +246,7 @@ func (s *Session) handleConfigure(req *Request) {
 				}
 			}
 
-			if s.server.proxyMode && s.server.proxyVersionMask == 0 {
+			if s.server.proxyMode && upstreamMask == 0 {
 				// Upstream doesn't support version-rolling — reject
 				result["version-rolling"] = false
 				s.server.log.Infof("stratum", "session %s version-rolling denied (upstream doesn't support it)", s.ID)
@@ -258,9 +309,19 @@ func (s *Session) handleSubscribe(req *Request) {
 		}
 	}
 
+	// Optional second param: the subscription ID of a previous session
+	// this miner wants to resume.
+	resumed := false
+	if len(req.Params) > 1 {
+		var prevID string
+		if json.Unmarshal(req.Params[1], &prevID) == nil && prevID != "" {
+			resumed = s.resume(prevID)
+		}
+	}
+
 	// Auto-detect start difficulty from miner type (only if no explicit
 	// mining.suggest_difficulty was received, which takes priority)
-	if s.userAgent != "" && s.suggestedDiff == 0 {
+	if !resumed && s.userAgent != "" && s.suggestedDiff == 0 {
 		uaDiff := s.server.vardiffMgr.StartDiffForUA(s.userAgent)
 		if uaDiff != s.currentDiff {
 			s.currentDiff = uaDiff
@@ -277,7 +338,7 @@ func (s *Session) handleSubscribe(req *Request) {
 	result := []interface{}{
 		subscriptions,
 		s.extranonce1,
-		s.server.extranonce2Size,
+		s.server.extranonce.Load().en2Size,
 	}
 
 	s.sendResponse(req.ID, result, nil)
@@ -285,7 +346,7 @@ func (s *Session) handleSubscribe(req *Request) {
 	// Send initial difficulty after subscribe response
 	s.sendSetDifficulty(s.currentDiff)
 
-	s.server.log.Infof("stratum", "miner %s subscribed (extranonce1=%s ua=%s)", s.conn.RemoteAddr(), s.extranonce1, s.userAgent)
+	s.server.log.Infof("stratum", "miner %s subscribed (extranonce1=%s ua=%s)", s.remote, s.extranonce1, s.userAgent)
 }
 
 func (s *Session) handleAuthorize(req *Request) {
@@ -295,16 +356,25 @@ func (s *Session) handleAuthorize(req *Request) {
 	}
 
 	workerName, _ := ParamString(req.Params, 0)
-	if workerName == "" {
-		s.sendResponse(req.ID, false, NewError(ErrUnauthorized, "empty worker name"))
+	if !validWorkerName(workerName) {
+		s.sendResponse(req.ID, false, NewError(ErrUnauthorized, "invalid worker name"))
+		s.server.penalize(s.ip, s.server.config.Ban.AuthFailPoints, "invalid worker name")
+		return
+	}
+	password, _ := ParamString(req.Params, 1)
+	if err := authorizeWorker(&s.server.config.Auth, workerName, password, s.ip); err != nil {
+		s.sendResponse(req.ID, false, NewError(ErrUnauthorized, err.Error()))
+		s.server.log.Warnf("stratum", "refused worker %q from %s: %v", workerName, s.ip, err)
+		s.server.penalize(s.ip, s.server.config.Ban.AuthFailPoints, "authorize refused: "+err.Error())
 		return
 	}
 
 	s.workerName = workerName
-	s.authorized = true
+	s.setOutboxWorker(workerName)
+	s.server.sessionAuthorized(s)
 
 	s.sendResponse(req.ID, true, nil)
-	s.server.log.Infof("stratum", "miner %s authorized as %s", s.conn.RemoteAddr(), workerName)
+	s.server.log.Infof("stratum", "miner %s authorized as %s", s.remote, workerName)
 
 	// In proxy mode, set difficulty to upstream diff immediately.
 	// In solo mode, restore last known difficulty for this worker.
@@ -344,16 +414,26 @@ func (s *Session) handleAuthorize(req *Request) {
 		s.server.OnMinerConnected(s.toMinerInfo())
 	}
 
+	// A miner in a paused group starts out held; SetHeld updates it later.
+	s.held.Store(s.server.HoldWork != nil && s.server.HoldWork(s.toMinerInfo()))
+
 	// Send current job if available
 	s.server.sendCurrentJob(s)
 }
 
 func (s *Session) handleSubmit(req *Request) {
 	if !s.authorized {
-		s.sendResponse(req.ID, false, NewError(ErrUnauthorized, "not authorized"))
+		stratumErr := NewError(ErrUnauthorized, "not authorized")
+		s.sendResponse(req.ID, false, stratumErr)
+		if s.server.OnShareRejected != nil {
+			s.server.OnShareRejected(s.ID, ShareReject{Code: rejectCode(stratumErr), Detail: stratumErr.Message})
+		}
+		s.server.penalize(s.ip, s.server.config.Ban.MalformedPoints, "submit before authorize")
 		return
 	}
 
+	en1 := s.en1()
+	ens := s.server.extranonce.Load()
 	worker, _ := ParamString(req.Params, 0)
 	jobID, _ := ParamJobID(req.Params, 1)
 	en2, _ := ParamString(req.Params, 2)
@@ -363,43 +443,37 @@ func (s *Session) handleSubmit(req *Request) {
 	// Optional 6th param: version bits (from version-rolling miners)
 	versionBits, _ := ParamString(req.Params, 5)
 
-	// Fix extranonce2 length: silently pad or truncate broken clients (matches ckpool behavior).
-	expectedEN2Len := s.server.extranonce2Size * 2
-	if len(en2) != expectedEN2Len {
-		if len(en2) > expectedEN2Len {
-			// Truncate to expected length
-			s.server.log.Debugf("stratum", "truncated en2 from %d to %d chars for %s", len(en2), expectedEN2Len, s.workerName)
-			en2 = en2[:expectedEN2Len]
-		} else if len(en2) > 0 {
-			// Pad with leading zeros
-			for len(en2) < expectedEN2Len {
-				en2 = "0" + en2
-			}
-			s.server.log.Debugf("stratum", "padded en2 to %s for %s", en2, s.workerName)
-		}
-	}
-
 	sub := ShareSubmission{
-		WorkerName:  worker,
-		JobID:       jobID,
-		Extranonce2: en2,
-		NTime:       ntime,
-		Nonce:       nonce,
-		VersionBits: versionBits,
-		VersionMask: s.versionMask,
+		WorkerName:      worker,
+		JobID:           jobID,
+		Extranonce2:     en2,
+		Extranonce2Size: ens.en2Size,
+		NTime:           ntime,
+		Nonce:           nonce,
+		VersionBits:     versionBits,
+		VersionMask:     s.versionMask,
 	}
 
 	s.server.log.Debugf("stratum", "share submit from %s: job=%q en1=%s en2=%s ntime=%s nonce=%s vbits=%s en2size=%d",
-		s.workerName, jobID, s.extranonce1, en2, ntime, nonce, versionBits, s.server.extranonce2Size)
+		s.workerName, jobID, en1, en2, ntime, nonce, versionBits, ens.en2Size)
 
 	shareReceived := time.Now()
+	age := s.server.shareAge(jobID, shareReceived)
 
 	// Count ALL shares at entry point (before validation) for proxy accounting
 	if s.server.proxyMode {
 		s.server.proxySharesIn.Add(1)
 	}
 
-	result, stratumErr := s.server.shareValidator.ValidateShare(s.extranonce1, sub)
+	// A share for the previous upstream connection, submitted while the
+	// extranonce was being replaced, can no longer be forwarded.
+	var result *ShareResult
+	var stratumErr *StratumError
+	if s.server.proxyMode && !strings.HasPrefix(en1, ens.upstreamEN1) {
+		stratumErr = NewError(ErrStaleJob, "extranonce changed")
+	} else {
+		result, stratumErr = s.server.shareValidator.ValidateShare(en1, sub)
+	}
 	if stratumErr != nil {
 		s.sendResponse(req.ID, false, stratumErr)
 
@@ -427,12 +501,86 @@ func (s *Session) handleSubmit(req *Request) {
 
 		s.diffMu.Lock()
 		s.sharesRejected++
+		switch stratumErr.Code {
+		case ErrNTimeRange:
+			s.rejectedNTime++
+		case ErrVersionMask:
+			s.rejectedVersionMask++
+		case ErrExtranonce2:
+			s.rejectedExtranonce2++
+		case ErrNonceLength:
+			s.rejectedNonce++
+		}
 		s.diffMu.Unlock()
 		if s.server.OnShareRejected != nil {
-			s.server.OnShareRejected(s.ID, stratumErr.Message)
+			reject := ShareReject{Code: rejectCode(stratumErr), Detail: stratumErr.Message, Age: age}
+			if reject.Code == RejectStaleJob {
+				reject.Detail = jobID
+			}
+			s.server.OnShareRejected(s.ID, reject)
+		}
+		// Stale shares are normal around block changes, and so are
+		// wrong-sized shares from work in flight across an extranonce
+		// change; only other invalid ones count towards a ban.
+		penalize := stratumErr.Code != ErrStaleJob
+		if stratumErr.Code == ErrExtranonce2 || stratumErr.Code == ErrNonceLength {
+			penalize = !s.recentExtranonceChange(shareReceived)
+		}
+		if penalize {
+			s.server.penalize(s.ip, s.server.config.Ban.InvalidSharePoints, "invalid share: "+stratumErr.Message)
 		}
 		s.server.log.Infof("stratum", "share REJECTED from %s: %s (job=%q en1=%s en2=%s ntime=%s nonce=%s vbits=%s)",
-			s.workerName, stratumErr.Message, jobID, s.extranonce1, en2, ntime, nonce, versionBits)
+			s.workerName, stratumErr.Message, jobID, en1, en2, ntime, nonce, versionBits)
+		return
+	}
+
+	if result.StaleBlock {
+		s.sendResponse(req.ID, false, NewError(ErrStaleJob, "stale block"))
+		s.recordStaleBlock(result.TipAge)
+		if s.server.proxyMode {
+			s.server.proxySharesStaleBlock.Add(1)
+		}
+		if s.server.OnShareRejected != nil {
+			s.server.OnShareRejected(s.ID, ShareReject{
+				Code:   RejectStaleBlock,
+				Detail: fmt.Sprintf("height %d", result.Height),
+				Age:    age,
+				TipAge: result.TipAge,
+			})
+		}
+		s.server.log.Infof("stratum", "stale-block share from %s: job=%q height=%d, tip changed %v ago",
+			s.workerName, jobID, result.Height, result.TipAge.Round(time.Millisecond))
+		return
+	}
+
+	// Shares below the difficulty the miner was sent count for nothing.
+	// Either side of a difficulty change is allowed, and so is anything
+	// the upstream pool would take; a block candidate always goes through.
+	s.diffMu.Lock()
+	floor := s.currentDiff
+	if s.oldDiff > 0 && s.oldDiff < floor {
+		floor = s.oldDiff
+	}
+	s.diffMu.Unlock()
+	if s.server.proxyMode {
+		if upDiff := s.server.UpstreamDifficulty(); upDiff > 0 && upDiff < floor {
+			floor = upDiff
+		}
+	}
+	if result.Difficulty < floor && !result.BlockFound {
+		s.sendResponse(req.ID, false, NewError(ErrLowDifficulty, "low difficulty share"))
+		s.diffMu.Lock()
+		s.sharesRejected++
+		s.diffMu.Unlock()
+		if s.server.OnShareRejected != nil {
+			s.server.OnShareRejected(s.ID, ShareReject{
+				Code:   RejectLowDiff,
+				Detail: fmt.Sprintf("%.6g < %.6g", result.Difficulty, floor),
+				Age:    age,
+			})
+		}
+		s.server.log.Infof("stratum", "low-difficulty share from %s: %.6g below %.6g (job=%q)",
+			s.workerName, result.Difficulty, floor, jobID)
 		return
 	}
 
@@ -500,11 +648,9 @@ func (s *Session) handleSubmit(req *Request) {
 	}
 	s.diffMu.Unlock()
 
-	if s.server.OnShareAccepted != nil {
-		s.server.OnShareAccepted(s.ID, hashrateDiff, result.Difficulty)
-	}
-
-	// Proxy mode: instrument and forward qualifying shares upstream
+	// Proxy mode: instrument and forward qualifying shares upstream. A
+	// share the pool refuses is reported as rejected instead of accepted.
+	upstreamReject := ""
 	if s.server.proxyMode {
 		upDiff := s.server.UpstreamDifficulty()
 		s.server.proxySharesValid.Add(1)
@@ -515,7 +661,7 @@ func (s *Session) handleSubmit(req *Request) {
 
 		if s.server.OnShareForward != nil && upDiff > 0 && result.Difficulty >= upDiff {
 			s.server.proxySharesFwd.Add(1)
-			minerPrefix := s.extranonce1[len(s.server.upstreamEN1):]
+			minerPrefix := en1[len(ens.upstreamEN1):]
 			fullEN2 := minerPrefix + en2
 			accepted, reason := s.server.OnShareForward(s.workerName, jobID, fullEN2, ntime, nonce, versionBits)
 			latency := time.Since(shareReceived)
@@ -528,12 +674,21 @@ func (s *Session) handleSubmit(req *Request) {
 				s.server.proxySharesUpReject.Add(1)
 				s.server.log.Infof("proxy", "[SHARE-FWD] miner=%s REJECTED reason=%q latency=%v job=%s diff=%.2f upDiff=%.2f en2=%s",
 					s.workerName, reason, latency, jobID, result.Difficulty, upDiff, fullEN2)
+				upstreamReject = reason
 			}
 		} else if upDiff > 0 && result.Difficulty < upDiff {
 			s.server.proxySharesBelow.Add(1)
 		}
 	}
 
+	if upstreamReject != "" {
+		if s.server.OnShareRejected != nil {
+			s.server.OnShareRejected(s.ID, ShareReject{Code: RejectUpstream, Detail: upstreamReject, Age: age})
+		}
+	} else if s.server.OnShareAccepted != nil {
+		s.server.OnShareAccepted(s.ID, hashrateDiff, result.Difficulty, age)
+	}
+
 	// Block found
 	if result.BlockFound {
 		if s.server.proxyMode {
@@ -544,7 +699,7 @@ func (s *Session) handleSubmit(req *Request) {
 			}
 		} else {
 			// Solo mode: submit to node
-			height := s.server.currentJob().Template.Height
+			height := result.Height
 			s.server.log.Infof("stratum", "BLOCK CANDIDATE by %s! Hash: %s — submitting to node...", s.workerName, result.BlockHash)
 
 			accepted := false
@@ -599,6 +754,10 @@ func (s *Session) handleSuggestDifficulty(req *Request) {
 
 
 func (s *Session) sendNotify(job *Job, cleanJobs bool) {
+	s.enqueue(outMsg{job: job, clean: cleanJobs})
+}
+
+func encodeNotify(job *Job, cleanJobs bool) []byte {
 	params := []interface{}{
 		job.ID,
 		job.PrevHash,
@@ -610,7 +769,7 @@ func (s *Session) sendNotify(job *Job, cleanJobs bool) {
 		job.NTime,
 		cleanJobs,
 	}
-	s.send(EncodeNotification("mining.notify", params))
+	return EncodeNotification("mining.notify", params)
 }
 
 func (s *Session) sendSetDifficulty(diff float64) {
@@ -635,6 +794,31 @@ func (s *Session) setProxyDiff(diff float64) {
 	s.sendSetDifficulty(diff)
 }
 
+// en1 returns the session's current extranonce1.
+func (s *Session) en1() string {
+	s.diffMu.Lock()
+	defer s.diffMu.Unlock()
+	return s.extranonce1
+}
+
+// setExtranonce gives the session a new extranonce1 and extranonce2 size
+// with mining.set_extranonce. Miners apply it from the next job.
+func (s *Session) setExtranonce(en1 string, en2Size int) {
+	s.diffMu.Lock()
+	s.extranonce1 = en1
+	s.extranonceChanged = time.Now()
+	s.diffMu.Unlock()
+	s.send(EncodeNotification("mining.set_extranonce", []interface{}{en1, en2Size}))
+}
+
+// recentExtranonceChange reports whether the extranonce was replaced less
+// than extranonceGrace before t.
+func (s *Session) recentExtranonceChange(t time.Time) bool {
+	s.diffMu.Lock()
+	defer s.diffMu.Unlock()
+	return !s.extranonceChanged.IsZero() && t.Sub(s.extranonceChanged) < extranonceGrace
+}
+
 // sendReconnect tells the miner to disconnect and reconnect after waitSec.
 // Supports cgminer, BFGminer, and many firmware variants.
 func (s *Session) sendReconnect(waitSec int) {
@@ -647,10 +831,25 @@ func (s *Session) sendResponse(id interface{}, result interface{}, stratumErr *S
 }
 
 func (s *Session) send(data []byte) {
-	s.writeMu.Lock()
-	defer s.writeMu.Unlock()
-	s.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
-	s.conn.Write(data)
+	s.enqueue(outMsg{data: data})
+}
+
+// recordStaleBlock counts a stale-block share as a rejection and records
+// how long after the tip changed it arrived.
+func (s *Session) recordStaleBlock(tipAge time.Duration) {
+	ms := float64(tipAge) / float64(time.Millisecond)
+	s.diffMu.Lock()
+	s.sharesRejected++
+	s.sharesStaleBlock++
+	if s.staleTipAgeAvg == 0 {
+		s.staleTipAgeAvg = ms
+	} else {
+		s.staleTipAgeAvg = 0.8*s.staleTipAgeAvg + 0.2*ms
+	}
+	if ms > s.staleTipAgeMax {
+		s.staleTipAgeMax = ms
+	}
+	s.diffMu.Unlock()
 }
 
 func (s *Session) toMinerInfo() MinerInfo {
@@ -659,18 +858,35 @@ func (s *Session) toMinerInfo() MinerInfo {
 	accepted := s.sharesAccepted
 	rejected := s.sharesRejected
 	bestDiff := s.bestDifficulty
+	staleBlock, tipAge, tipAgeMax := s.sharesStaleBlock, s.staleTipAgeAvg, s.staleTipAgeMax
+	badNTime, badVersion, badEN2, badNonce := s.rejectedNTime, s.rejectedVersionMask, s.rejectedExtranonce2, s.rejectedNonce
 	s.diffMu.Unlock()
+	latency, latencyMax, depth, dropped := s.deliveryStats()
 
 	return MinerInfo{
 		ID:             s.ID,
 		WorkerName:     s.workerName,
 		UserAgent:      s.userAgent,
-		IPAddress:      s.conn.RemoteAddr().String(),
+		IPAddress:      s.remote,
 		ConnectedAt:    s.connectedAt,
 		CurrentDiff:    curDiff,
 		SharesAccepted: accepted,
 		SharesRejected: rejected,
 		BestDifficulty: bestDiff,
+
+		JobLatencyMs:    latency,
+		JobLatencyMaxMs: latencyMax,
+		QueueDepth:      depth,
+		Dropped:         dropped,
+
+		SharesStaleBlock: staleBlock,
+		StaleTipAgeMs:    tipAge,
+		StaleTipAgeMaxMs: tipAgeMax,
+
+		RejectedNTime:       badNTime,
+		RejectedVersionMask: badVersion,
+		RejectedExtranonce2: badEN2,
+		RejectedNonce:       badNonce,
 	}
 }
 
@@ -687,6 +903,24 @@ type MinerInfo struct {
 	SharesRejected uint64    `json:"sharesRejected"`
 	BestDifficulty float64   `json:"bestDifficulty"`
 	LastShareTime  time.Time `json:"lastShareTime"`
+
+	// Job delivery through the session's send queue
+	JobLatencyMs    float64 `json:"jobLatencyMs"`    // moving average, queue to socket
+	JobLatencyMaxMs float64 `json:"jobLatencyMaxMs"` // worst since connecting
+	QueueDepth      int     `json:"queueDepth"`
+	Dropped         uint64  `json:"dropped"` // notifies replaced before they were sent
+
+	// Shares on a replaced tip (also counted in SharesRejected), and how
+	// long after the tip changed they arrived
+	SharesStaleBlock uint64  `json:"sharesStaleBlock"`
+	StaleTipAgeMs    float64 `json:"staleTipAgeMs"`    // moving average
+	StaleTipAgeMaxMs float64 `json:"staleTipAgeMaxMs"` // worst since connecting
+
+	// Shares failing consensus checks (also counted in SharesRejected)
+	RejectedNTime       uint64 `json:"rejectedNTime"`
+	RejectedVersionMask uint64 `json:"rejectedVersionMask"`
+	RejectedExtranonce2 uint64 `json:"rejectedExtranonce2"`
+	RejectedNonce       uint64 `json:"rejectedNonce"`
 }
 
 // Ensure MinerInfo implements json.Marshaler if needed
@@ -7,6 +7,7 @@ import (
 	"govault/internal/node"
 	"math/big"
 	"sync"
+	"time"
 )
 
 // pdiff1Target is the target for difficulty 1 in pool difficulty.
@@ -18,15 +19,25 @@ func init() {
 	pdiff1Target.SetString("00000000FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF", 16)
 }
 
+// maxFutureBlockTime is how far ahead of the local clock a block's ntime
+// may be before nodes refuse it.
+const maxFutureBlockTime = 2 * time.Hour
+
+// proxyNTimeSlack is how far before an upstream job's ntime a share's
+// ntime may be. The upstream pool does not send its template's mintime,
+// so the job's ntime is only an estimate of the floor.
+const proxyNTimeSlack = 10 * time.Minute
+
 // ShareSubmission holds the data submitted by a miner.
 type ShareSubmission struct {
-	WorkerName  string
-	JobID       string
-	Extranonce2 string
-	NTime       string
-	Nonce       string
-	VersionBits string // optional: version rolling bits from mining.submit param 6
-	VersionMask uint32 // negotiated mask from mining.configure
+	WorkerName      string
+	JobID           string
+	Extranonce2     string
+	Extranonce2Size int // bytes the miner was told to use
+	NTime           string
+	Nonce           string
+	VersionBits     string // optional: version rolling bits from mining.submit param 6
+	VersionMask     uint32 // negotiated mask from mining.configure
 }
 
 // ShareResult is the outcome of validating a share.
@@ -36,6 +47,12 @@ type ShareResult struct {
 	Difficulty float64
 	BlockHash  string
 	BlockHex   string
+	Height     int64 // height of the job's block
+
+	// StaleBlock is set, with Valid false, for a share on a job whose
+	// prevhash is no longer the tip. TipAge is how long ago the tip changed.
+	StaleBlock bool
+	TipAge     time.Duration
 }
 
 // ShareValidator validates submitted shares against job data.
@@ -59,13 +76,17 @@ func NewShareValidator(jm *JobManager) *ShareValidator {
 func (sv *ShareValidator) ValidateShare(extranonce1 string, sub ShareSubmission) (*ShareResult, *StratumError) {
 	job := sv.jobManager.GetJob(sub.JobID)
 	if job == nil {
-		// Include available job IDs in error for diagnostics
-		ids := sv.jobManager.ActiveJobIDs()
-		available := make([]string, 0, len(ids))
-		for id := range ids {
-			available = append(available, id)
-		}
-		return nil, NewError(ErrStaleJob, fmt.Sprintf("job not found: %q (available: %v)", sub.JobID, available))
+		return nil, NewError(ErrStaleJob, fmt.Sprintf("job not found: %q", sub.JobID))
+	}
+
+	// A job on a replaced tip can never produce a useful block, so its
+	// shares are not hashed or credited.
+	if tip, changed := sv.jobManager.Tip(); job.PrevHash != tip {
+		return &ShareResult{Height: job.Height, StaleBlock: true, TipAge: time.Since(changed)}, nil
+	}
+
+	if stratumErr := checkShare(job, sub, time.Now()); stratumErr != nil {
+		return nil, stratumErr
 	}
 
 	// Check for duplicate (include version bits for version-rolling miners).
@@ -128,6 +149,7 @@ func (sv *ShareValidator) ValidateShare(extranonce1 string, sub ShareSubmission)
 	result := &ShareResult{
 		Valid:      true,
 		Difficulty: actualDiff,
+		Height:     job.Height,
 	}
 
 	// Check if this meets the network target (block found!)
@@ -148,6 +170,62 @@ func (sv *ShareValidator) ValidateShare(extranonce1 string, sub ShareSubmission)
 	return result, nil
 }
 
+// checkShare applies the checks a block built from the share would face
+// at the node: extranonce2 and nonce sizes, ntime range, and version bits
+// within the negotiated mask.
+func checkShare(job *Job, sub ShareSubmission, now time.Time) *StratumError {
+	if b, err := hex.DecodeString(sub.Extranonce2); err != nil || len(b) != sub.Extranonce2Size {
+		return NewError(ErrExtranonce2, fmt.Sprintf("malformed extranonce2: %q (want %d bytes of hex)", sub.Extranonce2, sub.Extranonce2Size))
+	}
+	if b, err := hex.DecodeString(sub.Nonce); err != nil || len(b) != 4 {
+		return NewError(ErrNonceLength, fmt.Sprintf("bad nonce length: %q", sub.Nonce))
+	}
+
+	ntime, err := hexUint32(sub.NTime)
+	if err != nil {
+		return NewError(ErrNTimeRange, fmt.Sprintf("ntime out of range: invalid ntime %q", sub.NTime))
+	}
+	// Without a template (proxy mode) the floor is the job's own ntime,
+	// less some slack; the upstream pool applies the real one.
+	minTime, _ := hexUint32(job.NTime)
+	switch slack := uint32(proxyNTimeSlack / time.Second); {
+	case job.Template != nil && job.Template.MinTime > 0:
+		minTime = uint32(job.Template.MinTime)
+	case job.Template == nil && minTime > slack:
+		minTime -= slack
+	}
+	maxTime := now.Add(maxFutureBlockTime).Unix()
+	if ntime < minTime || int64(ntime) > maxTime {
+		return NewError(ErrNTimeRange, fmt.Sprintf("ntime out of range: %d not in [%d, %d]", ntime, minTime, maxTime))
+	}
+
+	if sub.VersionBits != "" {
+		bits, err := hexUint32(sub.VersionBits)
+		if err != nil {
+			return NewError(ErrVersionMask, fmt.Sprintf("version mask violation: invalid version bits %q", sub.VersionBits))
+		}
+		// Some firmware submits the full version rather than just the
+		// rolled bits; bits outside the mask must then match the job's.
+		base, _ := hexUint32(job.Version)
+		if outside := bits &^ sub.VersionMask; outside != 0 && outside != base&^sub.VersionMask {
+			return NewError(ErrVersionMask, fmt.Sprintf("version mask violation: bits %08x outside mask %08x", outside, sub.VersionMask))
+		}
+	}
+	return nil
+}
+
+// hexUint32 parses a Stratum big-endian 4-byte hex field.
+func hexUint32(s string) (uint32, error) {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		return 0, err
+	}
+	if len(b) != 4 {
+		return 0, fmt.Errorf("got %d bytes, want 4", len(b))
+	}
+	return binary.BigEndian.Uint32(b), nil
+}
+
 // CleanDuplicates removes duplicate tracking for old jobs.
 func (sv *ShareValidator) CleanDuplicates(keepJobIDs map[string]bool) {
 	sv.mu.Lock()
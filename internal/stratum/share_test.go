@@ -0,0 +1,122 @@
+package stratum
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"govault/internal/config"
+	"govault/internal/node"
+)
+
+func TestCheckShare(t *testing.T) {
+	now := time.Unix(1_800_000_000, 0)
+	hexTime := func(d time.Duration) string { return fmt.Sprintf("%08x", now.Add(d).Unix()) }
+	solo := &Job{
+		NTime:    hexTime(0),
+		Version:  "20000000",
+		Template: &node.BlockTemplate{MinTime: now.Add(-time.Hour).Unix()},
+	}
+	proxy := &Job{NTime: hexTime(0), Version: "20000000"}
+
+	cases := []struct {
+		name string
+		job  *Job
+		edit func(*ShareSubmission)
+		want int // error code, 0 for none
+	}{
+		{"valid", solo, nil, 0},
+		{"en2 short", solo, func(s *ShareSubmission) { s.Extranonce2 = "000000" }, ErrExtranonce2},
+		{"en2 long", solo, func(s *ShareSubmission) { s.Extranonce2 = "0000000000" }, ErrExtranonce2},
+		{"en2 not hex", solo, func(s *ShareSubmission) { s.Extranonce2 = "0000000g" }, ErrExtranonce2},
+		{"nonce short", solo, func(s *ShareSubmission) { s.Nonce = "000000" }, ErrNonceLength},
+		{"nonce not hex", solo, func(s *ShareSubmission) { s.Nonce = "zzzzzzzz" }, ErrNonceLength},
+		{"ntime not hex", solo, func(s *ShareSubmission) { s.NTime = "nope" }, ErrNTimeRange},
+		{"ntime short", solo, func(s *ShareSubmission) { s.NTime = "0000" }, ErrNTimeRange},
+		{"ntime at mintime", solo, func(s *ShareSubmission) { s.NTime = hexTime(-time.Hour) }, 0},
+		{"ntime before mintime", solo, func(s *ShareSubmission) { s.NTime = hexTime(-time.Hour - time.Second) }, ErrNTimeRange},
+		{"ntime 2h ahead", solo, func(s *ShareSubmission) { s.NTime = hexTime(maxFutureBlockTime) }, 0},
+		{"ntime too far ahead", solo, func(s *ShareSubmission) { s.NTime = hexTime(maxFutureBlockTime + time.Second) }, ErrNTimeRange},
+		{"proxy ntime within slack", proxy, func(s *ShareSubmission) { s.NTime = hexTime(-proxyNTimeSlack) }, 0},
+		{"proxy ntime before slack", proxy, func(s *ShareSubmission) { s.NTime = hexTime(-proxyNTimeSlack - time.Second) }, ErrNTimeRange},
+		{"proxy ntime too far ahead", proxy, func(s *ShareSubmission) { s.NTime = hexTime(maxFutureBlockTime + time.Second) }, ErrNTimeRange},
+		{"version bits in mask", solo, func(s *ShareSubmission) { s.VersionBits = "1fffe000" }, 0},
+		{"full version in mask", solo, func(s *ShareSubmission) { s.VersionBits = "3fffe000" }, 0},
+		{"version bits outside mask", solo, func(s *ShareSubmission) { s.VersionBits = "00000001" }, ErrVersionMask},
+		{"version bits without mask", solo, func(s *ShareSubmission) { s.VersionMask = 0; s.VersionBits = "00002000" }, ErrVersionMask},
+		{"version bits not hex", solo, func(s *ShareSubmission) { s.VersionBits = "xyz" }, ErrVersionMask},
+	}
+	for _, c := range cases {
+		sub := ShareSubmission{
+			Extranonce2:     "00000000",
+			Extranonce2Size: 4,
+			NTime:           hexTime(0),
+			Nonce:           "12345678",
+			VersionMask:     0x1fffe000,
+		}
+		if c.edit != nil {
+			c.edit(&sub)
+		}
+		err := checkShare(c.job, sub, now)
+		switch {
+		case c.want == 0 && err != nil:
+			t.Errorf("%s: rejected: %v", c.name, err)
+		case c.want != 0 && (err == nil || err.Code != c.want):
+			t.Errorf("%s: got %v, want code %d", c.name, err, c.want)
+		}
+	}
+}
+
+// A share on a replaced tip is stale-block while the old job is kept, for
+// StaleGraceSec after the tip changed, and an unknown job after that.
+func TestStaleBlockGrace(t *testing.T) {
+	jm := NewJobManager("", "", 4, nil, &config.JobRetentionConfig{MaxAgeSec: 600, StaleGraceSec: 60, MaxJobs: 100})
+	sv := NewShareValidator(jm)
+	addTestJob(jm, "old", "tip1", time.Now())
+	addTestJob(jm, "new", "tip2", time.Now())
+
+	sub := ShareSubmission{JobID: "old", Extranonce2: "00000000", Extranonce2Size: 4, NTime: "00000000", Nonce: "00000000"}
+	result, err := sv.ValidateShare("aabbccdd", sub)
+	if err != nil || result == nil || !result.StaleBlock || result.Valid {
+		t.Fatalf("inside grace: result %+v, error %v; want a stale-block result", result, err)
+	}
+	if result.TipAge < 0 || result.TipAge > time.Second {
+		t.Errorf("inside grace: tip age %v, want just now", result.TipAge)
+	}
+
+	jm.mu.Lock()
+	jm.tipChanged = time.Now().Add(-61 * time.Second)
+	jm.mu.Unlock()
+	addTestJob(jm, "newer", "tip2", time.Now())
+	if result, err := sv.ValidateShare("aabbccdd", sub); err == nil || err.Code != ErrStaleJob || result != nil {
+		t.Errorf("outside grace: result %+v, error %v; want job not found", result, err)
+	}
+}
+
+func TestSubmitStaleBlock(t *testing.T) {
+	s := newTestServer(t, nil)
+	s.SetProxyMode("aabbccdd", 4, 2, 0)
+	rejects := make(chan ShareReject, 1)
+	s.OnShareRejected = func(minerID string, r ShareReject) { rejects <- r }
+	startTestServer(t, s)
+	s.BroadcastUpstreamJob(testUpstreamJob("j1"))
+
+	c := dialTest(t, s)
+	c.login("rig")
+	next := testUpstreamJob("j2")
+	next.PrevHash = "000000000000000000000000000000000000000000000000000000000000beef"
+	s.BroadcastUpstreamJob(next)
+
+	ntime := fmt.Sprintf("%08x", time.Now().Unix())
+	m := c.call("mining.submit", "rig", "j1", "00000000", ntime, "00000001")
+	if string(m.Result) != "false" || !strings.Contains(string(m.Error), "stale block") {
+		t.Errorf("share on the old tip: result %s, error %s", m.Result, m.Error)
+	}
+	if r := <-rejects; r.Code != RejectStaleBlock {
+		t.Errorf("share on the old tip recorded as %s", r.Code)
+	}
+	if info := s.GetSessions()[0]; info.SharesStaleBlock != 1 || info.SharesRejected != 1 {
+		t.Errorf("session counts %d stale-block of %d rejected, want 1 of 1", info.SharesStaleBlock, info.SharesRejected)
+	}
+}
@@ -16,9 +16,13 @@ import (
 
 // pendingJob stores an early job notification received before the OnJob
 // callback is wired. This avoids losing the first job from the upstream.
+// During a reconnect it also holds jobs back until OnReconnect has moved
+// miners to the new EN1. mu is held while delivering jobs, so they reach
+// OnJob in order.
 type pendingJob struct {
-	mu  sync.Mutex
-	job *JobParams
+	mu   sync.Mutex
+	job  *JobParams
+	held bool // reconnecting: buffer jobs even with OnJob wired
 }
 
 // JobParams holds the fields from a mining.notify message.
@@ -240,7 +244,28 @@ func (c *Client) SubmitShare(worker, jobID, fullEN2, ntime, nonce, versionBits s
 	if json.Unmarshal(resp, &result) == nil && result {
 		return true, ""
 	}
-	return false, string(resp)
+	return false, poolError(resp)
+}
+
+// poolError formats a refused submit as "<code> <message>". Pools send the
+// error as [code, message, traceback] or {"code": ..., "message": ...};
+// anything else is returned as is.
+func poolError(raw json.RawMessage) string {
+	var list []interface{}
+	if json.Unmarshal(raw, &list) == nil && len(list) >= 2 {
+		if code, ok := list[0].(float64); ok {
+			msg, _ := list[1].(string)
+			return fmt.Sprintf("%d %s", int(code), msg)
+		}
+	}
+	var obj struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(raw, &obj) == nil && obj.Code != 0 {
+		return fmt.Sprintf("%d %s", obj.Code, obj.Message)
+	}
+	return string(raw)
 }
 
 // --- internal ---
@@ -511,17 +536,41 @@ func (c *Client) handleJobNotify(params json.RawMessage) {
 		jobID, prevHash[:8], prevHash[len(prevHash)-8:], version, nbits, cleanJobs,
 		len(cb1), len(cb2), len(branches))
 
-	if c.OnJob != nil {
+	c.earlyJob.mu.Lock()
+	defer c.earlyJob.mu.Unlock()
+	switch {
+	case c.earlyJob.held:
+		c.earlyJob.job = job
+		c.log.Debugf("upstream", "holding job %s until reconnect completes", jobID)
+	case c.OnJob != nil:
 		c.OnJob(job)
-	} else {
+	default:
 		// Buffer early job before OnJob is wired (race with Connect)
-		c.earlyJob.mu.Lock()
 		c.earlyJob.job = job
-		c.earlyJob.mu.Unlock()
 		c.log.Debugf("upstream", "buffered early job %s (OnJob not wired yet)", jobID)
 	}
 }
 
+// holdJobs buffers job notifications from a new connection until
+// releaseJobs, dropping any held from an earlier attempt.
+func (c *Client) holdJobs() {
+	c.earlyJob.mu.Lock()
+	c.earlyJob.held = true
+	c.earlyJob.job = nil
+	c.earlyJob.mu.Unlock()
+}
+
+// releaseJobs stops holding jobs and delivers the latest held one.
+func (c *Client) releaseJobs() {
+	c.earlyJob.mu.Lock()
+	defer c.earlyJob.mu.Unlock()
+	c.earlyJob.held = false
+	if j := c.earlyJob.job; j != nil && c.OnJob != nil {
+		c.earlyJob.job = nil
+		c.OnJob(j)
+	}
+}
+
 func (c *Client) handleSetDifficulty(params json.RawMessage) {
 	var raw []json.RawMessage
 	if err := json.Unmarshal(params, &raw); err != nil || len(raw) < 1 {
@@ -676,6 +725,10 @@ func (c *Client) reconnectLoop() {
 		c.pending = make(map[int64]chan json.RawMessage)
 		c.pendMu.Unlock()
 
+		// The new upstream's jobs need the new EN1; hold them until
+		// OnReconnect has handed it to the stratum server.
+		c.holdJobs()
+
 		c.wg.Add(1)
 		go c.readLoop()
 
@@ -701,6 +754,7 @@ func (c *Client) reconnectLoop() {
 		if c.OnReconnect != nil {
 			c.OnReconnect()
 		}
+		c.releaseJobs()
 	}
 }
 
@@ -0,0 +1,513 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"govault/internal/config"
+	"govault/internal/database"
+	"govault/internal/miner"
+	"govault/internal/stratum"
+
+	"github.com/wailsapp/wails/v2/pkg/runtime"
+)
+
+// scheduleState tracks a rule while it is active.
+type scheduleState struct {
+	rule  config.ScheduleRule
+	since time.Time
+	// Targets acted on: worker IDs (withhold), session IDs (reconnect) or
+	// IPs (throttle, shutdown). Throttled IPs map to their device change
+	// ID; -1 marks a failed attempt, which is not retried or reverted.
+	applied map[string]int64
+}
+
+// batchID groups the device changes made while the rule was active.
+func (s *scheduleState) batchID() string {
+	return fmt.Sprintf("schedule-%s-%d", s.rule.ID, s.since.Unix())
+}
+
+// scheduleOverride forces a rule on or off.
+type scheduleOverride struct {
+	Mode  string    // "on" or "off"
+	Until time.Time // zero = until cleared
+}
+
+// ScheduleStatus is a schedule rule with its current state.
+type ScheduleStatus struct {
+	config.ScheduleRule
+	Active        bool   `json:"active"`
+	Since         int64  `json:"since,omitempty"`
+	Override      string `json:"override"` // "", "on" or "off"
+	OverrideUntil int64  `json:"overrideUntil,omitempty"`
+	Targets       int    `json:"targets"` // workers the rule applies to
+}
+
+// GetScheduleStatus returns every schedule rule with whether it is active.
+func (a *App) GetScheduleStatus() []ScheduleStatus {
+	rules := a.config.App.Scheduler.Rules
+	groups := a.loadGroups()
+	subjects := a.groupSubjects()
+
+	a.schedMu.Lock()
+	defer a.schedMu.Unlock()
+	result := make([]ScheduleStatus, 0, len(rules))
+	for _, r := range rules {
+		st := ScheduleStatus{ScheduleRule: r, Targets: len(scheduleTargets(r, groups, subjects))}
+		if s, ok := a.schedActive[r.ID]; ok {
+			st.Active = true
+			st.Since = s.since.Unix()
+		}
+		if o, ok := a.schedOverrides[r.ID]; ok {
+			st.Override = o.Mode
+			if !o.Until.IsZero() {
+				st.OverrideUntil = o.Until.Unix()
+			}
+		}
+		result = append(result, st)
+	}
+	return result
+}
+
+// SetScheduleOverride forces a rule on ("on") or off ("off") for the given
+// number of minutes (0 = until cleared), or hands it back to its schedule
+// ("auto"). Overrides apply even while the scheduler is disabled and are
+// not kept across restarts.
+func (a *App) SetScheduleOverride(ruleID, mode string, minutes int) error {
+	var rule *config.ScheduleRule
+	for i, r := range a.config.App.Scheduler.Rules {
+		if r.ID == ruleID {
+			rule = &a.config.App.Scheduler.Rules[i]
+			break
+		}
+	}
+	if rule == nil {
+		return fmt.Errorf("schedule rule %q not found", ruleID)
+	}
+	if minutes < 0 {
+		return fmt.Errorf("override duration must not be negative")
+	}
+
+	detail := mode
+	a.schedMu.Lock()
+	switch mode {
+	case "auto":
+		delete(a.schedOverrides, ruleID)
+	case "on", "off":
+		o := scheduleOverride{Mode: mode}
+		if minutes > 0 {
+			o.Until = time.Now().Add(time.Duration(minutes) * time.Minute)
+			detail = fmt.Sprintf("%s for %d min", mode, minutes)
+		}
+		a.schedOverrides[ruleID] = o
+	default:
+		a.schedMu.Unlock()
+		return fmt.Errorf("unknown override mode %q", mode)
+	}
+	a.schedMu.Unlock()
+
+	a.logScheduleAction(*rule, "override", "set", "", detail, nil)
+	go a.evaluateSchedule()
+	return nil
+}
+
+// GetScheduleActions returns recent scheduler actions, newest first. An
+// empty ruleID returns actions for all rules.
+func (a *App) GetScheduleActions(ruleID string, limit int) []database.ScheduleActionEntry {
+	if a.db == nil {
+		return nil
+	}
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	actions, err := a.db.RecentScheduleActions(ruleID, limit)
+	if err != nil {
+		a.log.Errorf("app", "schedule actions: %v", err)
+		return nil
+	}
+	return actions
+}
+
+// scheduleLoop evaluates the schedule every 30 seconds.
+func (a *App) scheduleLoop() {
+	a.restoreSchedule()
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-a.stopStats:
+			return
+		case <-ticker.C:
+			a.evaluateSchedule()
+		}
+	}
+}
+
+// evaluateSchedule starts and stops rules for the current time. Active
+// rules are applied again on every pass so workers and devices that come
+// online while a rule is active are caught.
+func (a *App) evaluateSchedule() {
+	a.schedRunMu.Lock()
+	defer a.schedRunMu.Unlock()
+
+	now := time.Now()
+	groups := a.loadGroups()
+	subjects := a.groupSubjects()
+	held := make(map[string]bool)         // worker IDs
+	heldSessions := make(map[string]bool) // their live sessions
+	configured := make(map[string]bool)
+
+	for _, r := range a.config.App.Scheduler.Rules {
+		configured[r.ID] = true
+		a.schedMu.Lock()
+		st := a.schedActive[r.ID]
+		a.schedMu.Unlock()
+
+		active := a.ruleActive(r, now)
+		if !active {
+			if st != nil {
+				a.stopSchedule(st)
+			}
+			continue
+		}
+		targets := scheduleTargets(r, groups, subjects)
+		if st == nil {
+			st = &scheduleState{rule: r, since: now, applied: make(map[string]int64)}
+			a.schedMu.Lock()
+			a.schedActive[r.ID] = st
+			a.schedMu.Unlock()
+			a.logScheduleAction(r, r.Action, "start", "", fmt.Sprintf("%d workers", len(targets)), nil)
+		}
+		st.rule = r
+		a.applySchedule(st, targets, now)
+		if r.Action == config.ScheduleWithhold {
+			for _, t := range targets {
+				held[t.view.Worker.ID] = true
+				for _, sess := range t.view.Sessions {
+					heldSessions[sess.ID] = true
+				}
+			}
+		}
+	}
+
+	// Stop rules removed from the config
+	a.schedMu.Lock()
+	var removed []*scheduleState
+	for id, st := range a.schedActive {
+		if !configured[id] {
+			removed = append(removed, st)
+		}
+	}
+	a.schedMu.Unlock()
+	for _, st := range removed {
+		a.stopSchedule(st)
+	}
+
+	a.schedMu.Lock()
+	a.heldWorkers = held
+	a.schedMu.Unlock()
+
+	a.svcMu.RLock()
+	srv := a.stratum
+	a.svcMu.RUnlock()
+	if srv != nil {
+		if n := srv.SetHeld(heldSessions); n > 0 {
+			a.log.Infof("scheduler", "resumed work for %d miners", n)
+		}
+	}
+}
+
+// ruleActive applies any manual override to a rule's schedule. Expired
+// overrides are dropped.
+func (a *App) ruleActive(r config.ScheduleRule, now time.Time) bool {
+	a.schedMu.Lock()
+	o, ok := a.schedOverrides[r.ID]
+	if ok && !o.Until.IsZero() && now.After(o.Until) {
+		delete(a.schedOverrides, r.ID)
+		ok = false
+	}
+	a.schedMu.Unlock()
+	if ok {
+		return o.Mode == "on"
+	}
+	return a.config.App.Scheduler.Enabled && a.config.App.ScheduleActiveAt(r, now)
+}
+
+// applySchedule acts on targets the rule has not reached yet.
+func (a *App) applySchedule(st *scheduleState, targets []groupSubject, now time.Time) {
+	r := st.rule
+	switch r.Action {
+	case config.ScheduleWithhold:
+		for _, t := range targets {
+			id := t.view.Worker.ID
+			if _, ok := st.applied[id]; !ok {
+				st.applied[id] = 0
+				a.logScheduleAction(r, r.Action, "apply", id, "", nil)
+			}
+		}
+
+	case config.ScheduleReconnect:
+		a.svcMu.RLock()
+		srv := a.stratum
+		a.svcMu.RUnlock()
+		if srv == nil {
+			return
+		}
+		wait := a.scheduleWait(r, now)
+		for _, t := range targets {
+			for _, s := range t.view.Sessions {
+				if _, ok := st.applied[s.ID]; ok {
+					continue
+				}
+				st.applied[s.ID] = 0
+				err := srv.ReconnectMiner(s.ID, wait)
+				a.logScheduleAction(r, r.Action, "apply", t.view.Worker.ID, fmt.Sprintf("reconnect in %ds", wait), err)
+			}
+		}
+
+	case config.ScheduleThrottle, config.ScheduleShutdown:
+		var ips []string
+		for _, t := range targets {
+			ip := t.subject.IP
+			if _, ok := st.applied[ip]; ok || ip == "" || slices.Contains(ips, ip) || a.scheduleClaimed(ip, st) {
+				continue
+			}
+			ips = append(ips, ip)
+		}
+		results := a.eachDevice(ips, func(ip string) DeviceChangeResult {
+			if r.Action == config.ScheduleThrottle {
+				freq := r.Frequency
+				return a.applyDeviceChange(ip, st.batchID(), "schedule", miner.DeviceSettings{Frequency: &freq}, true)
+			}
+			res := DeviceChangeResult{IP: ip, OK: true}
+			if err := a.discovery.SetMining(ip, false); err != nil {
+				res.OK, res.Error = false, err.Error()
+			}
+			return res
+		})
+		for _, res := range results {
+			var err error
+			if !res.OK {
+				err = fmt.Errorf("%s", res.Error)
+				st.applied[res.IP] = -1
+			} else {
+				st.applied[res.IP] = res.ChangeID
+			}
+			detail := ""
+			if r.Action == config.ScheduleThrottle {
+				detail = fmt.Sprintf("%.0f MHz", r.Frequency)
+			}
+			a.logScheduleAction(r, r.Action, "apply", res.IP, detail, err)
+		}
+	}
+}
+
+// stopSchedule undoes a rule's device actions and marks it inactive.
+// Withheld miners are released by the caller; reconnected miners come back
+// on their own.
+func (a *App) stopSchedule(st *scheduleState) {
+	r := st.rule
+	switch r.Action {
+	case config.ScheduleThrottle:
+		var ids []int64
+		for _, id := range st.applied {
+			if id > 0 {
+				ids = append(ids, id)
+			}
+		}
+		for _, res := range a.RollbackDeviceChanges(ids, true) {
+			var err error
+			if !res.OK {
+				err = fmt.Errorf("%s", res.Error)
+			}
+			a.logScheduleAction(r, r.Action, "revert", res.IP, "", err)
+		}
+	case config.ScheduleShutdown:
+		// Enable every device the rule tried to stop: one that failed
+		// part way may still have some hashboards off. A failed device
+		// another rule has stopped since is left to that rule.
+		var ips []string
+		for ip, id := range st.applied {
+			if id < 0 && a.scheduleClaimed(ip, st) {
+				continue
+			}
+			ips = append(ips, ip)
+		}
+		sort.Strings(ips)
+		a.eachDevice(ips, func(ip string) DeviceChangeResult {
+			err := a.discovery.SetMining(ip, true)
+			a.logScheduleAction(r, r.Action, "revert", ip, "", err)
+			return DeviceChangeResult{IP: ip, OK: err == nil}
+		})
+	}
+
+	a.schedMu.Lock()
+	delete(a.schedActive, r.ID)
+	a.schedMu.Unlock()
+	a.logScheduleAction(r, r.Action, "stop", "", "", nil)
+}
+
+// scheduleClaimed reports whether another active rule with the same action
+// already holds a device, so its recorded settings stay the ones restored.
+func (a *App) scheduleClaimed(ip string, st *scheduleState) bool {
+	a.schedMu.Lock()
+	defer a.schedMu.Unlock()
+	for _, other := range a.schedActive {
+		if other == st || other.rule.Action != st.rule.Action {
+			continue
+		}
+		if id, ok := other.applied[ip]; ok && id >= 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// scheduleWait is how long a reconnected miner should stay away: until the
+// rule is expected to end, between one minute and one hour.
+func (a *App) scheduleWait(r config.ScheduleRule, now time.Time) int {
+	end := now.Add(time.Hour)
+	a.schedMu.Lock()
+	o, overridden := a.schedOverrides[r.ID]
+	a.schedMu.Unlock()
+	if overridden {
+		if !o.Until.IsZero() && o.Until.Before(end) {
+			end = o.Until
+		}
+	} else {
+		for t := now.Add(time.Minute); t.Before(end); t = t.Add(time.Minute) {
+			if !a.config.App.ScheduleActiveAt(r, t) {
+				end = t
+				break
+			}
+		}
+	}
+	return max(60, int(end.Sub(now).Seconds()))
+}
+
+// scheduleTargets returns the workers a rule applies to.
+func scheduleTargets(r config.ScheduleRule, groups []miner.Group, subjects []groupSubject) []groupSubject {
+	if r.GroupID == 0 {
+		return subjects
+	}
+	for _, g := range groups {
+		if g.ID != r.GroupID {
+			continue
+		}
+		var targets []groupSubject
+		for _, s := range subjects {
+			if g.Matches(s.subject) {
+				targets = append(targets, s)
+			}
+		}
+		return targets
+	}
+	return nil
+}
+
+// holdWork tells the stratum server whether a newly authorized miner is
+// paused by a withhold rule.
+func (a *App) holdWork(info stratum.MinerInfo) bool {
+	id := a.workerID(info.ID)
+	a.schedMu.Lock()
+	defer a.schedMu.Unlock()
+	return a.heldWorkers[id]
+}
+
+// restoreSchedule rebuilds the rules that were active when the app last
+// stopped from the action log, so the next pass can undo their device
+// changes if they have since ended.
+func (a *App) restoreSchedule() {
+	if a.db == nil {
+		return
+	}
+	actions, err := a.db.RecentScheduleActions("", 2000)
+	if err != nil {
+		a.log.Errorf("scheduler", "read action log: %v", err)
+		return
+	}
+
+	rules := make(map[string]config.ScheduleRule)
+	for _, r := range a.config.App.Scheduler.Rules {
+		rules[r.ID] = r
+	}
+	done := make(map[string]bool)
+	pending := make(map[string][]database.ScheduleActionEntry) // applies newer than the rule's start
+
+	a.schedMu.Lock()
+	defer a.schedMu.Unlock()
+	for _, e := range actions { // newest first
+		if done[e.RuleID] || e.Action == "override" {
+			continue
+		}
+		switch e.Phase {
+		case "stop":
+			done[e.RuleID] = true
+		case "apply":
+			if e.Status == "ok" {
+				pending[e.RuleID] = append(pending[e.RuleID], e)
+			}
+		case "start":
+			done[e.RuleID] = true
+			r, ok := rules[e.RuleID]
+			if !ok {
+				r = config.ScheduleRule{ID: e.RuleID, Name: e.RuleName, Action: e.Action}
+			}
+			st := &scheduleState{rule: r, since: time.Unix(e.Timestamp, 0), applied: make(map[string]int64)}
+			for _, p := range pending[e.RuleID] {
+				st.applied[p.Target] = 0
+				if e.Action == config.ScheduleThrottle {
+					st.applied[p.Target] = a.scheduleChangeID(p.Target, st.batchID())
+				}
+			}
+			a.schedActive[e.RuleID] = st
+			a.log.Infof("scheduler", "rule %q was active at shutdown (%d targets)", r.Name, len(st.applied))
+		}
+	}
+}
+
+// scheduleChangeID finds the device change a rule's batch made on a
+// device, or returns 0.
+func (a *App) scheduleChangeID(ip, batchID string) int64 {
+	changes, err := a.db.RecentDeviceChanges(ip, 50)
+	if err != nil {
+		return 0
+	}
+	for _, c := range changes {
+		if c.BatchID == batchID && c.Status == "ok" && c.RolledBackBy == 0 {
+			return c.ID
+		}
+	}
+	return 0
+}
+
+// logScheduleAction writes a scheduler action to the log, the database
+// and the frontend.
+func (a *App) logScheduleAction(r config.ScheduleRule, action, phase, target, detail string, actErr error) {
+	entry := database.ScheduleActionEntry{
+		Timestamp: time.Now().Unix(),
+		RuleID:    r.ID,
+		RuleName:  r.Name,
+		Action:    action,
+		Phase:     phase,
+		Target:    target,
+		Status:    "ok",
+		Detail:    detail,
+	}
+	if actErr != nil {
+		entry.Status = "failed"
+		entry.Detail = strings.TrimSpace(detail + " " + actErr.Error())
+		a.log.Warnf("scheduler", "%s: %s %s %s failed: %v", r.Name, action, phase, target, actErr)
+	} else {
+		a.log.Infof("scheduler", "%s: %s %s %s %s", r.Name, action, phase, target, detail)
+	}
+	if a.db != nil {
+		if err := a.db.InsertScheduleAction(entry); err != nil {
+			a.log.Errorf("scheduler", "record action: %v", err)
+		}
+	}
+	runtime.EventsEmit(a.ctx, "scheduler:action", entry)
+}